@@ -2,19 +2,38 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/frankbraun/codechain/command"
+	"github.com/frankbraun/codechain/util/def"
 	"github.com/frankbraun/codechain/util/seckey"
 	scritEngine "github.com/scritcash/scrit/engine/command"
 	scritGov "github.com/scritcash/scrit/gov/command"
+	scritCurrency "github.com/scritcash/scrit/gov/currency/command"
 	scritDBCType "github.com/scritcash/scrit/gov/dbctype/command"
 	scritEpoch "github.com/scritcash/scrit/gov/epoch/command"
+	scritGovMint "github.com/scritcash/scrit/gov/mint/command"
 	scritMint "github.com/scritcash/scrit/mint/command"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/mint/issue"
 	scritKeyList "github.com/scritcash/scrit/mint/keylist/command"
+	"github.com/scritcash/scrit/mint/redeem"
+	"github.com/scritcash/scrit/mint/signer"
+	"github.com/scritcash/scrit/mint/spendbook"
+	scritThreshold "github.com/scritcash/scrit/mint/threshold/command"
+	"github.com/scritcash/scrit/netconf"
+	scritWallet "github.com/scritcash/scrit/wallet/command"
 )
 
 // Test setting up a federation of Scrit mints (see doc/federation-setup.md).
@@ -150,17 +169,17 @@ func TestFederationSetup(t *testing.T) {
 	}
 
 	// define first DBC types (in denominations of 1, 2, and 5 EUR)
-	err = scritDBCType.Add("scrit-gov dbctype add", "-currency", "EUR", "-amount", "100000000")
+	err = scritDBCType.Add("scrit-gov dbctype add", "-currency", "EUR", "-amount", "1.00")
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = scritDBCType.Add("scrit-gov dbctype add", "-currency", "EUR", "-amount", "200000000")
+	err = scritDBCType.Series("scrit-gov dbctype series", "-currency", "EUR", "-min", "2.00", "-max", "5.00")
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = scritDBCType.Add("scrit-gov dbctype add", "-currency", "EUR", "-amount", "500000000")
-	if err != nil {
-		t.Fatal(err)
+	err = scritDBCType.Series("scrit-gov dbctype series", "-currency", "EUR", "-min", "1.00", "-max", "10.00")
+	if err == nil {
+		t.Fatal("dbctype series should refuse duplicate DBC types")
 	}
 
 	// create key lists
@@ -189,6 +208,12 @@ func TestFederationSetup(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// changing the signing period requires -force
+	err = scritEpoch.Add("scrit-gov epoch add", "-signing-period", "24h")
+	if err == nil {
+		t.Fatal("changing the signing period should require -force")
+	}
+
 	// extend key lists
 	if err := os.Setenv("SCRIT-MINTHOMEDIR", mint1dir); err != nil {
 		t.Fatal(err)
@@ -209,12 +234,530 @@ func TestFederationSetup(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// private key lists are encrypted, migrate a plaintext one (as written by
+	// older versions) and check it again
+	privFilename := filepath.Join(mint3dir, netconf.DefPrivKeyListDir, key3+".json")
+	plaintext := filepath.Join(tmpdir, "mint3-plaintext.json")
+	if err := scritKeyList.Unlock("scrit-mint keylist unlock", "-o", plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(plaintext, privFilename); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, err := netconf.IsEncryptedKeyList(privFilename); err != nil || encrypted {
+		t.Fatalf("private key list should be plaintext (%v)", err)
+	}
+	if err := scritKeyList.Encrypt("scrit-mint keylist encrypt"); err != nil {
+		t.Fatal(err)
+	}
+	if encrypted, err := netconf.IsEncryptedKeyList(privFilename); err != nil || !encrypted {
+		t.Fatalf("private key list should be encrypted (%v)", err)
+	}
+	if err := scritKeyList.Unlock("scrit-mint keylist unlock"); err == nil {
+		t.Fatal("unlock should require an output file")
+	}
+	if err := scritKeyList.Status("scrit-mint keylist status"); err != nil {
+		t.Fatal(err)
+	}
+
 	// test configuration
 	if err := scritEngine.ValidateConf("scrit-engine validateconf"); err != nil {
 		t.Fatal(err)
 	}
 
+	// declare a custom asset
+	err = scritCurrency.Add("scrit-gov currency add", "-code", "SCRITG", "-decimals", "3", "-symbol", "g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scritCurrency.List("scrit-gov currency list"); err != nil {
+		t.Fatal(err)
+	}
+
 	if err := scritDBCType.List("scrit-gov dbctype list"); err != nil {
 		t.Fatal(err)
 	}
+
+	// define the third signing epoch with new denominations and rotate the
+	// identity key of mint 3
+	err = scritEpoch.Add("scrit-gov epoch add")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = scritDBCType.Series("scrit-gov dbctype series", "remove", "-currency", "EUR", "-min", "2.00", "-max", "5.00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = scritDBCType.Series("scrit-gov dbctype series", "add", "-currency", "EUR", "-scheme", "1-2.5-5", "-min", "10", "-max", "50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{mint1dir, mint2dir, mint3dir} {
+		if err := os.Setenv("SCRIT-MINTHOMEDIR", dir); err != nil {
+			t.Fatal(err)
+		}
+		if err := scritKeyList.Extend("scrit-mint keylist extend"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpfile4, err := ioutil.TempFile("", "scrit_integration_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile4.Name())
+	os.Stdout = tmpfile4
+	if err := scritMint.Rotate("scrit-mint rotate"); err != nil {
+		os.Stdout = stdout
+		t.Fatal(err)
+	}
+	os.Stdout = stdout
+	if err := tmpfile4.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf, err = ioutil.ReadFile(tmpfile4.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines = bytes.Split(buf, []byte("\n"))
+	newKey3 := string(lines[len(lines)-3])
+	sig3 := string(lines[len(lines)-2])
+	// the old identity key and its private key list are kept until the
+	// replacement takes effect
+	secrets, err := ioutil.ReadDir(filepath.Join(mint3dir, def.SecretsSubDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 {
+		t.Fatalf("mint 3 has %d secret key files instead of 2", len(secrets))
+	}
+	if _, err := os.Stat(filepath.Join(mint3dir, netconf.DefPrivKeyListDir, key3+".json")); err != nil {
+		t.Fatalf("private key list of old identity removed: %v", err)
+	}
+	newSecKey3 := filepath.Join(mint3dir, def.SecretsSubDir, strings.TrimPrefix(newKey3, "ed25519-"))
+	if err := scritMint.Rotate("scrit-mint rotate", "-finish", "-s", newSecKey3); err == nil {
+		t.Fatal("rotation should only be finished after the replacement took effect")
+	}
+	fi, err := os.Stat(filepath.Join(netconf.DefMintDir, newKey3+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0644 {
+		t.Fatalf("key list of new identity has wrong mode: %v", fi.Mode())
+	}
+	err = scritGovMint.Replace("scrit-gov mint replace", newKey3, key3, sig3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scritEngine.ValidateConf("scrit-engine validateconf"); err != nil {
+		t.Fatal(err)
+	}
+
+	// issue DBCs against a deposit during the first signing epoch, approved
+	// by mint 1 and mint 2 (mint 3 has a new identity key by now)
+	operator, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{mint1dir, mint2dir} {
+		err := ioutil.WriteFile(filepath.Join(dir, issue.DefOperatorsFile),
+			[]byte(operator.MarshalID()+"\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	deposits := filepath.Join(tmpdir, "deposits.json")
+	err = ioutil.WriteFile(deposits,
+		[]byte(`[{"ID": "deposit-1", "Currency": "EUR", "Amount": 300000000}]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opSigner := netconf.NewKeySigner()
+	opSigner.AddIdentityKey(operator)
+	order, err := issue.NewOrder(operator, opSigner, "deposit-1", "EUR", 300000000,
+		[]issue.Output{
+			{DBCType: netconf.DBCType{Currency: "EUR", Amount: 100000000}, Blinded: []byte("output 1")},
+			{DBCType: netconf.DBCType{Currency: "EUR", Amount: 200000000}, Blinded: []byte("output 2")},
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	orderFile := filepath.Join(tmpdir, "order.json")
+	if err := order.Save(orderFile); err != nil {
+		t.Fatal(err)
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first signing epoch has not started yet, the mints only approve at
+	// the current time
+	issueTime := net.NetworkEpochs[0].SignStart.Add(time.Hour)
+	at := issueTime.Format(time.RFC3339)
+	if err := os.Setenv("SCRIT-MINTHOMEDIR", mint1dir); err != nil {
+		t.Fatal(err)
+	}
+	err = scritMint.Issue("scrit-mint issue", "-deposits", deposits, "-o",
+		filepath.Join(tmpdir, "issuance0.json"), orderFile)
+	if !errors.Is(err, netconf.ErrNoSigningEpoch) {
+		t.Fatalf("issuance outside of signing epoch should fail, got: %v", err)
+	}
+	for i, dir := range []string{mint1dir, mint2dir} {
+		iss, err := approveAt(dir, deposits, order, issueTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := iss.Save(filepath.Join(tmpdir, fmt.Sprintf("issuance%d.json", i+1))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := approveAt(mint2dir, deposits, order, issueTime); err == nil {
+		t.Fatal("issuance order should only be approved once")
+	}
+
+	// redeem the 1.00 EUR DBC with mint 1 and mint 2
+	var issuances []*issue.Issuance
+	for i := 1; i <= 2; i++ {
+		iss, err := issue.LoadIssuance(filepath.Join(tmpdir, fmt.Sprintf("issuance%d.json", i)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		issuances = append(issuances, iss)
+	}
+	dbcs, err := order.DBCs(issuances...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbcFile := filepath.Join(tmpdir, "dbc1.json")
+	if err := dbcs[0].Save(dbcFile); err != nil {
+		t.Fatal(err)
+	}
+	requestFile := filepath.Join(tmpdir, "redemption.json")
+	err = scritWallet.Redeem("scrit-wallet redeem", "-id", "redemption-1",
+		"-payout", "DE00 1234", "-o", requestFile, "-at", at, dbcFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := redeem.LoadRequest(requestFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, dir := range []string{mint1dir, mint2dir} {
+		if err := os.Setenv("SCRIT-MINTHOMEDIR", dir); err != nil {
+			t.Fatal(err)
+		}
+		receipt, err := redeemAt(dir, request, issueTime)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := receipt.Save(filepath.Join(tmpdir, fmt.Sprintf("receipt%d.json", i+1))); err != nil {
+			t.Fatal(err)
+		}
+		if err := receipt.Verify(request); err != nil {
+			t.Fatal(err)
+		}
+		if err := scritMint.Redemptions("scrit-mint redemptions"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// redeeming the same request again is a retry, another request with the
+	// same DBC must fail
+	if _, err := redeemAt(mint2dir, request, issueTime); err != nil {
+		t.Fatal(err)
+	}
+	requestFile2 := filepath.Join(tmpdir, "redemption2.json")
+	err = scritWallet.Redeem("scrit-wallet redeem", "-id", "redemption-2",
+		"-payout", "DE00 5678", "-o", requestFile2, "-at", at, dbcFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request2, err := redeem.LoadRequest(requestFile2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := redeemAt(mint2dir, request2, issueTime); !errors.Is(err, spendbook.ErrSpent) {
+		t.Fatalf("DBCs should only be redeemed once, got: %v", err)
+	}
+	if err := scritGov.Supply("scrit-gov supply"); err != nil {
+		t.Fatal(err)
+	}
+
+	// audit the supply against the commitment logs of mint 1 and mint 2
+	var commitLogs []string
+	for i, dir := range []string{mint1dir, mint2dir} {
+		if err := os.Setenv("SCRIT-MINTHOMEDIR", dir); err != nil {
+			t.Fatal(err)
+		}
+		commitLog := filepath.Join(tmpdir, fmt.Sprintf("commitlog%d.json", i+1))
+		if err := scritMint.CommitLog("scrit-mint commitlog", "-o", commitLog); err != nil {
+			t.Fatal(err)
+		}
+		commitLogs = append(commitLogs, commitLog)
+	}
+	if err := scritEngine.Audit("scrit-engine audit", commitLogs...); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritEngine.ValidateConf("scrit-engine validateconf"); err != nil {
+		t.Fatal(err)
+	}
+
+	// revoke the signing keys of mint 1, the quorum is still reached
+	if err := os.Setenv("SCRIT-MINTHOMEDIR", mint1dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritMint.Revoke("scrit-mint revoke", "test compromise"); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritEngine.ValidateConf("scrit-engine validateconf"); err != nil {
+		t.Fatal(err)
+	}
+
+	// canonical federation files
+	if err := scritGov.Fmt("scrit-gov fmt", "-check"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(netconf.DefNetConfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(netconf.DefNetConfFile, append([]byte("  "), data...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritGov.Fmt("scrit-gov fmt", "-check"); err == nil {
+		t.Fatal("fmt -check should fail for non-canonical file")
+	}
+	if err := scritGov.Fmt("scrit-gov fmt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritGov.Fmt("scrit-gov fmt", "-check"); err != nil {
+		t.Fatal(err)
+	}
+
+	// migrate legacy network configuration without version
+	data, err = ioutil.ReadFile(netconf.DefNetConfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	delete(doc, "Version")
+	data, err = json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(netconf.DefNetConfFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritGov.Migrate("scrit-gov migrate"); err != nil {
+		t.Fatal(err)
+	}
+	data, err = ioutil.ReadFile(netconf.DefNetConfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err := netconf.FileVersion(data); err != nil || v != netconf.NetworkVersion {
+		t.Fatalf("network configuration not migrated: version %d, %v", v, err)
+	}
+
+	// backup and restore mint 2
+	if err := os.Setenv("SCRIT-MINTHOMEDIR", mint2dir); err != nil {
+		t.Fatal(err)
+	}
+	backupFile := filepath.Join(tmpdir, "mint2.backup")
+	if err := scritMint.Backup("scrit-mint backup", backupFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritMint.Restore("scrit-mint restore", backupFile); err == nil {
+		t.Fatal("restore should require -f for existing mint state")
+	}
+	if err := scritMint.Restore("scrit-mint restore", "-f", backupFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritKeyList.Status("scrit-mint keylist status"); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritKeyList.Unlock("scrit-mint keylist unlock", "-o", filepath.Join(tmpdir, "mint2-unlocked.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritKeyList.Prune("scrit-mint keylist prune"); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().AddDate(10, 0, 0).Format(time.RFC3339)
+	if err := scritKeyList.Prune("scrit-mint keylist prune", "-at", future); err != nil {
+		t.Fatal(err)
+	}
+	if err := scritKeyList.Status("scrit-mint keylist status", "-at", future); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// lastLine runs f with stdout redirected into a temporary file and returns
+// the last line of output.
+func lastLine(t *testing.T, f func() error) string {
+	stdout := os.Stdout
+	tmpfile, err := ioutil.TempFile("", "scrit_integration_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+	os.Stdout = tmpfile
+	err = f()
+	os.Stdout = stdout
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := ioutil.ReadFile(tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(buf), []byte("\n"))
+	return string(lines[len(lines)-1])
+}
+
+// Test generating a 2-of-3 threshold identity key and signing with it.
+func TestThresholdIdentity(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "scrit_integration_test")
+	if err != nil {
+		t.Fatalf("ioutil.TempDir() failed: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+	seckey.TestPass = "test"
+
+	// ceremony directory shared by all operators
+	dir := filepath.Join(tmpdir, "ceremony")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var homeDirs []string
+	for i := 1; i <= 3; i++ {
+		homeDir := filepath.Join(tmpdir, fmt.Sprintf("operator%d", i))
+		if err := os.Mkdir(homeDir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		homeDirs = append(homeDirs, homeDir)
+	}
+	operator := func(i int) {
+		if err := os.Setenv("SCRIT-MINTHOMEDIR", homeDirs[i-1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// distributed key generation
+	for i := 1; i <= 3; i++ {
+		operator(i)
+		err := scritThreshold.DKGStart("scrit-mint threshold dkg-start",
+			"-dir", dir, "-id", strconv.Itoa(i), "-t", "2", "-n", "3")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 1; i <= 3; i++ {
+		operator(i)
+		err := scritThreshold.DKGShare("scrit-mint threshold dkg-share", "-dir", dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var id string
+	for i := 1; i <= 3; i++ {
+		operator(i)
+		id = lastLine(t, func() error {
+			return scritThreshold.DKGFinish("scrit-mint threshold dkg-finish", "-dir", dir)
+		})
+	}
+	ik, err := netconf.ParseIdentityKey(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// signing ceremony with operators 1 and 3
+	msg := "ed25519-replacement"
+	for _, i := range []int{1, 3} {
+		operator(i)
+		if err := scritThreshold.Commit("scrit-mint threshold commit", "-dir", dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, i := range []int{1, 3} {
+		operator(i)
+		err := scritThreshold.Sign("scrit-mint threshold sign", "-dir", dir, "-m", msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	sig := lastLine(t, func() error {
+		return scritThreshold.Aggregate("scrit-mint threshold aggregate", "-dir", dir, "-m", msg)
+	})
+	s, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(ik.PubKey, []byte(msg), s) {
+		t.Error("threshold signature doesn't verify")
+	}
+}
+
+// loadFederationAt loads the federation in the current directory at time at.
+func loadFederationAt(at time.Time) (*netconf.Federation, error) {
+	fed, r, err := netconf.LoadFederationFS(os.DirFS("."), &netconf.LoadOptions{At: at})
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Err(); err != nil {
+		return nil, err
+	}
+	return fed, nil
+}
+
+// approveAt approves order with the mint in homeDir at time at, like
+// 'scrit-mint issue' does at the current time.
+func approveAt(homeDir, deposits string, order *issue.Order, at time.Time) (*issue.Issuance, error) {
+	s, err := signer.LoadFile(homeDir, "")
+	if err != nil {
+		return nil, err
+	}
+	operators, err := issue.LoadOperators(filepath.Join(homeDir, issue.DefOperatorsFile))
+	if err != nil {
+		return nil, err
+	}
+	fed, err := loadFederationAt(at)
+	if err != nil {
+		return nil, err
+	}
+	m := &issue.Mint{
+		Federation:  fed,
+		IdentityKey: s.IdentityKey(),
+		Signer:      s,
+		Operators:   operators,
+		Rail:        &issue.FileRail{Filename: deposits},
+	}
+	return m.Approve(".", order, at)
+}
+
+// redeemAt redeems req with the mint in homeDir at time at, like
+// 'scrit-mint redeem' does at the current time.
+func redeemAt(homeDir string, req *redeem.Request, at time.Time) (*redeem.Receipt, error) {
+	sec, _, _, err := identity.Load(homeDir, "")
+	if err != nil {
+		return nil, err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	s := netconf.NewKeySigner()
+	s.AddIdentityKey(ik)
+	fed, err := loadFederationAt(at)
+	if err != nil {
+		return nil, err
+	}
+	m := &redeem.Mint{
+		Federation:  fed,
+		IdentityKey: ik,
+		PrivateKey:  sec,
+		Signer:      s,
+		HomeDir:     homeDir,
+	}
+	return m.Redeem(".", req, at)
 }
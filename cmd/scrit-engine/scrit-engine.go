@@ -12,7 +12,8 @@ import (
 func usage() {
 	cmd := os.Args[0]
 	fmt.Fprintf(os.Stderr, "Usage: %s reissue [-d federation_dir] DBC\n", cmd)
-	fmt.Fprintf(os.Stderr, "       %s validateconf [-d federation_dir]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s validateconf [-d federation_dir] [-json] [-at time]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s audit [-d federation_dir] commitlog.json...\n", cmd)
 	os.Exit(2)
 }
 
@@ -28,6 +29,8 @@ func main() {
 		err = command.Reissue(argv0, args...)
 	case "validateconf":
 		err = command.ValidateConf(argv0, args...)
+	case "audit":
+		err = command.Audit(argv0, args...)
 	default:
 		usage()
 	}
@@ -12,10 +12,14 @@ import (
 func usage() {
 	cmd := os.Args[0]
 	fmt.Fprintf(os.Stderr, "Usage: %s start\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s currency\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s dbctype\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s epoch\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s mint\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s status\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s fmt\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s migrate\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s supply\n", cmd)
 	os.Exit(2)
 }
 
@@ -29,6 +33,8 @@ func main() {
 	switch os.Args[1] {
 	case "start":
 		err = command.Start(argv0, args...)
+	case "currency":
+		err = command.Currency(argv0, args...)
 	case "dbctype":
 		err = command.DBCType(argv0, args...)
 	case "epoch":
@@ -37,6 +43,12 @@ func main() {
 		err = command.Mint(argv0, args...)
 	case "status":
 		err = command.Status(argv0, args...)
+	case "fmt":
+		err = command.Fmt(argv0, args...)
+	case "migrate":
+		err = command.Migrate(argv0, args...)
+	case "supply":
+		err = command.Supply(argv0, args...)
 	default:
 		usage()
 	}
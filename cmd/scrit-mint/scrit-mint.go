@@ -15,6 +15,17 @@ func usage() {
 	fmt.Fprintf(os.Stderr, "       %s keyfile -s seckey.bin [-c]\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s identity [-s seckey.bin]\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s keylist\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s rotate [-s seckey.bin] [-finish]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s revoke [-s seckey.bin] notice\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s signer [-s seckey.bin]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s threshold\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s issue [-s seckey.bin] -deposits deposits.json order.json\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s redeem [-s seckey.bin] redemption.json\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s redemptions [-since time]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s reissue [-s seckey.bin] reissue.json\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s commitlog [-s seckey.bin] [-o commitlog.json]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s backup backup_file\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s restore [-f] [-s seckey.bin] backup_file\n", cmd)
 	os.Exit(2)
 }
 
@@ -34,6 +45,28 @@ func main() {
 		err = command.Identity(argv0, args...)
 	case "keylist":
 		err = command.KeyList(argv0, args...)
+	case "rotate":
+		err = command.Rotate(argv0, args...)
+	case "revoke":
+		err = command.Revoke(argv0, args...)
+	case "signer":
+		err = command.Signer(argv0, args...)
+	case "threshold":
+		err = command.Threshold(argv0, args...)
+	case "issue":
+		err = command.Issue(argv0, args...)
+	case "redeem":
+		err = command.Redeem(argv0, args...)
+	case "redemptions":
+		err = command.Redemptions(argv0, args...)
+	case "reissue":
+		err = command.Reissue(argv0, args...)
+	case "commitlog":
+		err = command.CommitLog(argv0, args...)
+	case "backup":
+		err = command.Backup(argv0, args...)
+	case "restore":
+		err = command.Restore(argv0, args...)
 	default:
 		usage()
 	}
@@ -2,15 +2,37 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 
-	"github.com/frankbraun/codechain/secpkg"
-	"github.com/frankbraun/codechain/util"
+	"github.com/scritcash/scrit/wallet/command"
 )
 
+func usage() {
+	cmd := os.Args[0]
+	fmt.Fprintf(os.Stderr, "Usage: %s redeem [-d federation_dir] -payout destination dbc.json...\n", cmd)
+	os.Exit(2)
+}
+
 func main() {
-	if err := secpkg.UpToDate("scrit"); err != nil {
-		util.Fatal(err)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	argv0 := os.Args[0] + " " + os.Args[1]
+	args := os.Args[2:]
+	var err error
+	switch os.Args[1] {
+	case "redeem":
+		err = command.Redeem(argv0, args...)
+	default:
+		usage()
+	}
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintf(os.Stderr, "%s: error: %s\n", os.Args[0], err)
+			os.Exit(1)
+		}
+		os.Exit(2)
 	}
-	fmt.Println("scrit-wallet")
 }
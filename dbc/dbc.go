@@ -0,0 +1,148 @@
+// Package dbc implements digital bearer certificates (DBCs).
+//
+// A DBC is a message signed by the signing keys of a quorum of the mints of a
+// federation. The signing keys are specific for the DBC type and signing
+// epoch of the DBC. Whoever holds a DBC owns it, it is spent by reissuing or
+// redeeming it, which the mints record in their spendbooks.
+package dbc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// ErrSignature is returned if a DBC signature does not verify.
+var ErrSignature = errors.New("dbc: signature does not verify")
+
+// ErrNotValidating is returned if the epoch of a DBC doesn't validate
+// anymore (or not yet).
+var ErrNotValidating = errors.New("dbc: epoch of DBC not validating")
+
+// ErrQuorum is returned if the signatures of a DBC do not reach the quorum.
+var ErrQuorum = errors.New("dbc: signatures do not reach quorum")
+
+// Signature is the signature of a DBC by a mint.
+type Signature struct {
+	MintID    string    // ID of mint identity key
+	PubKey    []byte    // public signing key
+	Signature []byte    // signature of DBC message
+	Time      time.Time // signing time
+}
+
+// DBC is a digital bearer certificate.
+type DBC struct {
+	DBCType    netconf.DBCType // type of DBC
+	Epoch      int             // signing epoch
+	Message    []byte          // signed message
+	Signatures []Signature     // mint signatures
+}
+
+// Input returns the encoding of the DBC which is spent (without signatures).
+// It identifies the DBC in spendbooks.
+func (d *DBC) Input() []byte {
+	encodingScheme := []interface{}{
+		[]byte(d.DBCType.Currency),
+		int64(d.DBCType.Amount),
+		int64(d.Epoch),
+		d.Message,
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	buf := make([]byte, size)
+	enc, err := binencode.Encode(buf, encodingScheme...)
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	return enc
+}
+
+// verifySignature verifies the signature s of DBC d with the key list of mint
+// m. The signing key must be valid at the signing time. It returns true, if
+// the signing key has been rolled at time t (see netconf.MintEpoch.Rolled).
+func (d *DBC) verifySignature(m *netconf.Mint, s *Signature, t time.Time) (bool, error) {
+	if d.Epoch >= len(m.MintEpochs) {
+		return false, fmt.Errorf("%w: mint %s has no epoch %d", ErrSignature, s.MintID, d.Epoch)
+	}
+	me := m.MintEpochs[d.Epoch]
+	k := me.SigningKeyAt(d.DBCType, s.Time)
+	if k == nil || !bytes.Equal(k.PubKey, s.PubKey) {
+		return false, fmt.Errorf("%w: mint %s: no signing key at %s", ErrSignature, s.MintID,
+			s.Time.Format(time.RFC3339))
+	}
+	if !ed25519.Verify(k.PubKey, d.Message, s.Signature) {
+		return false, fmt.Errorf("%w: mint %s", ErrSignature, s.MintID)
+	}
+	return me.Rolled(k, t), nil
+}
+
+// Verify the DBC against federation f at time t. The epoch of the DBC must
+// validate at time t and the valid signatures must be acceptable (see
+// netconf.Federation.AcceptSignatures), which is returned.
+func (d *DBC) Verify(f *netconf.Federation, t time.Time) (netconf.Acceptance, error) {
+	validating := false
+	for _, e := range f.Network.ValidatingEpochsAt(t) {
+		if e == d.Epoch {
+			validating = true
+			break
+		}
+	}
+	if !validating {
+		return netconf.Reject, fmt.Errorf("%w: %d", ErrNotValidating, d.Epoch)
+	}
+	var sigs []netconf.MintSignature
+	for i := range d.Signatures {
+		s := &d.Signatures[i]
+		m, ok := f.Mints[s.MintID]
+		if !ok {
+			continue // unknown mint
+		}
+		rolled, err := d.verifySignature(m, s, t)
+		if err != nil {
+			return netconf.Reject, err
+		}
+		sigs = append(sigs, netconf.MintSignature{
+			MintID: s.MintID,
+			Time:   s.Time,
+			Rolled: rolled,
+		})
+	}
+	a := f.AcceptSignatures(d.Epoch, sigs, t)
+	if a == netconf.Reject {
+		return a, ErrQuorum
+	}
+	return a, nil
+}
+
+// Load a DBC from filename.
+func Load(filename string) (*DBC, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var d DBC
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// Save the DBC to filename (with mode 0600, because it is a bearer
+// certificate).
+func (d *DBC) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(d)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0600)
+}
@@ -0,0 +1,152 @@
+package dbc
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/netconf/netconftest"
+)
+
+var oneEUR = netconf.DBCType{Currency: "EUR", Amount: 100000000}
+
+// testFederation returns a 2-of-3 federation with the DBC type oneEUR, the
+// signers of its mints (by mint ID), and a time in the first signing epoch.
+func testFederation(t *testing.T) (*netconf.Federation, map[string]netconf.Signer, time.Time) {
+	f := netconftest.NewFederation(t, oneEUR)
+	signers := make(map[string]netconf.Signer)
+	for i, s := range f.Signers {
+		signers[f.MintID(i)] = s
+	}
+	return f.Federation, signers, f.At
+}
+
+// sign d with the mints with the given IDs at time at.
+func sign(t *testing.T, fed *netconf.Federation, signers map[string]netconf.Signer, d *DBC, at time.Time, mintIDs ...string) {
+	for _, id := range mintIDs {
+		k := fed.Mints[id].MintEpochs[d.Epoch].SigningKeyAt(d.DBCType, at)
+		sig, err := signers[id].Sign(k.PubKey, d.Message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Signatures = append(d.Signatures, Signature{
+			MintID:    id,
+			PubKey:    k.PubKey,
+			Signature: sig,
+			Time:      at,
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	fed, signers, at := testFederation(t)
+	var ids []string
+	for id := range fed.Mints {
+		ids = append(ids, id)
+	}
+	d := &DBC{DBCType: oneEUR, Message: []byte("message")}
+	sign(t, fed, signers, d, at, ids[0])
+	if _, err := d.Verify(fed, at); !errors.Is(err, ErrQuorum) {
+		t.Errorf("Verify() should fail with ErrQuorum, got: %v", err)
+	}
+	sign(t, fed, signers, d, at, ids[1])
+	a, err := d.Verify(fed, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a != netconf.Accept {
+		t.Errorf("acceptance %d instead of Accept", a)
+	}
+
+	// save and load
+	filename := filepath.Join(t.TempDir(), "dbc.json")
+	if err := d.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Verify(fed, at); err != nil {
+		t.Error(err)
+	}
+
+	// epoch must be validating
+	if _, err := d.Verify(fed, at.Add(-2*time.Hour)); !errors.Is(err, ErrNotValidating) {
+		t.Errorf("Verify() should fail with ErrNotValidating, got: %v", err)
+	}
+
+	// signing time outside of signing epoch
+	signed := d.Signatures[1].Time
+	d.Signatures[1].Time = fed.Network.NetworkEpochs[0].SignEnd
+	if _, err := d.Verify(fed, at); !errors.Is(err, ErrSignature) {
+		t.Errorf("Verify() should fail with ErrSignature, got: %v", err)
+	}
+	d.Signatures[1].Time = signed
+
+	// manipulated message
+	d.Message = []byte("manipulated")
+	if _, err := d.Verify(fed, at); !errors.Is(err, ErrSignature) {
+		t.Errorf("Verify() should fail with ErrSignature, got: %v", err)
+	}
+}
+
+func TestVerifyRolledKey(t *testing.T) {
+	f := netconftest.NewFederation(t, oneEUR)
+	signers := make(map[string]netconf.Signer)
+	for i, s := range f.Signers {
+		signers[f.MintID(i)] = s
+	}
+	d := &DBC{DBCType: oneEUR, Message: []byte("message")}
+	sign(t, f.Federation, signers, d, f.At, f.MintID(0), f.MintID(1))
+	// the key of the first mint is rolled after the DBC has been signed
+	roll := f.At.Add(time.Hour)
+	if _, err := f.Mint(0).RollKey(f.IdentityKeys[0], 0, oneEUR, roll); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		t      time.Time
+		accept netconf.Acceptance
+	}{
+		{roll.Add(-time.Second), netconf.Accept},
+		{roll, netconf.AcceptRecovery},
+		{roll.Add(time.Hour), netconf.AcceptRecovery},
+	}
+	for i, testCase := range testCases {
+		a, err := d.Verify(f.Federation, testCase.t)
+		if err != nil {
+			t.Fatalf("test case %d: %v", i, err)
+		}
+		if a != testCase.accept {
+			t.Errorf("test case %d: acceptance %d instead of %d", i, a, testCase.accept)
+		}
+	}
+	// a signature of the rolled key backdated before the roll
+	backdated := &DBC{DBCType: oneEUR, Message: []byte("backdated")}
+	sign(t, f.Federation, signers, backdated, f.At, f.MintID(0), f.MintID(1))
+	if a, err := backdated.Verify(f.Federation, roll.Add(time.Hour)); err != nil ||
+		a != netconf.AcceptRecovery {
+		t.Errorf("Verify() == %d, %v", a, err)
+	}
+	// the other mints are not affected
+	other := &DBC{DBCType: oneEUR, Message: []byte("other")}
+	sign(t, f.Federation, signers, other, f.At, f.MintID(1), f.MintID(2))
+	if a, err := other.Verify(f.Federation, roll.Add(time.Hour)); err != nil || a != netconf.Accept {
+		t.Errorf("Verify() == %d, %v", a, err)
+	}
+}
+
+func TestInput(t *testing.T) {
+	d1 := &DBC{DBCType: oneEUR, Message: []byte("message")}
+	d2 := &DBC{DBCType: oneEUR, Epoch: 1, Message: []byte("message")}
+	if string(d1.Input()) == string(d2.Input()) {
+		t.Error("inputs of DBCs with different epochs are equal")
+	}
+	d2.Epoch = 0
+	d2.Signatures = []Signature{{MintID: "mint"}}
+	if string(d1.Input()) != string(d2.Input()) {
+		t.Error("input depends on signatures")
+	}
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func audit(dir string, logFiles []string) error {
+	opts := &netconf.LoadOptions{Logger: log.Std}
+	fed, r, err := netconf.LoadFederationFS(os.DirFS(dir), opts)
+	if err != nil {
+		return err
+	}
+	var logs []*netconf.CommitmentLog
+	for _, filename := range logFiles {
+		l, err := netconf.LoadCommitmentLog(filename)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, l)
+	}
+	views, ar := fed.Audit(logs)
+	if err := r.WriteText(os.Stderr); err != nil {
+		return err
+	}
+	if err := ar.WriteText(os.Stderr); err != nil {
+		return err
+	}
+	for _, v := range views {
+		who := "federation"
+		if v.MintID != "" {
+			who = "mint " + v.MintID
+		}
+		fmt.Printf("%s %s: %s\n", who, v.DBCType, &v)
+	}
+	if r.HasErrors() || ar.HasErrors() {
+		return errors.New("audit failed")
+	}
+	return nil
+}
+
+// Audit implements the scrit-engine 'audit' command.
+func Audit(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-d federation_dir] commitlog.json...\n", argv0)
+		fmt.Fprintf(os.Stderr, "Audit DBC supply against ledgers and commitment logs of all mints.\n")
+		fmt.Fprintf(os.Stderr, "Per currency the value of the outputs must not exceed inputs plus issuance minus redemption.\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("d", ".", "Set federation directory")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return audit(*dir, fs.Args())
+}
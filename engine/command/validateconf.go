@@ -1,9 +1,11 @@
 package command
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/frankbraun/codechain/secpkg"
 	"github.com/frankbraun/codechain/util/log"
@@ -14,11 +16,13 @@ import (
 func ValidateConf(argv0 string, args ...string) error {
 	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s [-d federation_dir]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Usage: %s [-d federation_dir] [-json] [-at time]\n", argv0)
 		fmt.Fprintf(os.Stderr, "Validate federation configuration.\n")
 		fs.PrintDefaults()
 	}
 	dir := fs.String("d", ".", "Set federation directory")
+	jsn := fs.Bool("json", false, "Print validation report as JSON")
+	at := fs.String("at", "", "Validate as of given time (RFC3339) instead of now")
 	verbose := fs.Bool("v", false, "Be verbose")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -33,8 +37,25 @@ func ValidateConf(argv0 string, args ...string) error {
 	if err := secpkg.UpToDate("scrit"); err != nil {
 		return err
 	}
-	if _, err := netconf.LoadFederation(*dir); err != nil {
+	var opts netconf.LoadOptions
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+		opts.At = t
+	}
+	_, r, err := netconf.LoadFederationFS(os.DirFS(*dir), &opts)
+	if err != nil {
+		return err
+	}
+	if *jsn {
+		fmt.Println(r.Marshal())
+	} else if err := r.WriteText(os.Stdout); err != nil {
 		return err
 	}
+	if r.HasErrors() {
+		return errors.New("federation configuration is invalid")
+	}
 	return nil
 }
@@ -0,0 +1,279 @@
+package frost
+
+import (
+	"bytes"
+	"fmt"
+
+	"filippo.io/edwards25519"
+)
+
+// Round1Secret is the secret state of a participant between the rounds of
+// the distributed key generation. It must not be shared.
+type Round1Secret struct {
+	ID           int      // identifier of participant (1 to N)
+	Threshold    int      // number of participants required to sign
+	N            int      // total number of participants
+	Coefficients [][]byte // secret polynomial (Threshold scalars)
+}
+
+// Round1Package is the first round message of a participant in the
+// distributed key generation. It is sent to all other participants.
+type Round1Package struct {
+	ID          int      // identifier of participant (1 to N)
+	Threshold   int      // number of participants required to sign
+	N           int      // total number of participants
+	Commitments [][]byte // commitments to polynomial coefficients (points)
+	ProofR      []byte   // proof of knowledge of first coefficient (point)
+	ProofZ      []byte   // proof of knowledge of first coefficient (scalar)
+}
+
+// Round2Package is the second round message of a participant in the
+// distributed key generation. It contains the secret share of the sender for
+// the receiver and must be transmitted confidentially.
+type Round2Package struct {
+	From  int    // identifier of sender
+	To    int    // identifier of receiver
+	Share []byte // secret share (scalar)
+}
+
+// KeyShare is the result of the distributed key generation for a single
+// participant. The secret share must not be shared.
+type KeyShare struct {
+	ID     int        // identifier of participant (1 to N)
+	Secret []byte     // secret share (scalar)
+	Public *PublicKey // public group information
+}
+
+// PublicKey is the public information about a threshold key.
+type PublicKey struct {
+	Threshold          int      // number of participants required to sign
+	N                  int      // total number of participants
+	GroupKey           []byte   // Ed25519 group public key
+	VerificationShares [][]byte // public keys of all participants (by ID-1)
+}
+
+// proofContext returns the proof of knowledge challenge for participant id.
+func proofContext(id int, c0, r *edwards25519.Point) *edwards25519.Scalar {
+	return hdkg(scalarFromInt(id).Bytes(), c0.Bytes(), r.Bytes())
+}
+
+// DKGRound1 starts the distributed key generation for participant id of n
+// participants with the given threshold t.
+func DKGRound1(id, t, n int) (*Round1Secret, *Round1Package, error) {
+	if err := checkParams(t, n); err != nil {
+		return nil, nil, err
+	}
+	if err := checkID(id, n); err != nil {
+		return nil, nil, err
+	}
+	sec := &Round1Secret{ID: id, Threshold: t, N: n}
+	pkg := &Round1Package{ID: id, Threshold: t, N: n}
+	var coeffs []*edwards25519.Scalar
+	for i := 0; i < t; i++ {
+		a, err := randomScalar()
+		if err != nil {
+			return nil, nil, err
+		}
+		coeffs = append(coeffs, a)
+		sec.Coefficients = append(sec.Coefficients, a.Bytes())
+		c := new(edwards25519.Point).ScalarBaseMult(a)
+		pkg.Commitments = append(pkg.Commitments, c.Bytes())
+	}
+	// proof of knowledge of coeffs[0]
+	k, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	r := new(edwards25519.Point).ScalarBaseMult(k)
+	c0 := new(edwards25519.Point).ScalarBaseMult(coeffs[0])
+	c := proofContext(id, c0, r)
+	z := edwards25519.NewScalar().MultiplyAdd(coeffs[0], c, k)
+	pkg.ProofR = r.Bytes()
+	pkg.ProofZ = z.Bytes()
+	return sec, pkg, nil
+}
+
+// verify the round 1 package for the given parameters and return the parsed
+// commitments.
+func (pkg *Round1Package) verify(t, n int) ([]*edwards25519.Point, error) {
+	if pkg.Threshold != t || pkg.N != n {
+		return nil, fmt.Errorf("%w: participant %d uses %d-of-%d",
+			ErrInvalidThreshold, pkg.ID, pkg.Threshold, pkg.N)
+	}
+	if err := checkID(pkg.ID, n); err != nil {
+		return nil, err
+	}
+	if len(pkg.Commitments) != t {
+		return nil, fmt.Errorf("%w: participant %d has %d commitments",
+			ErrInvalidThreshold, pkg.ID, len(pkg.Commitments))
+	}
+	var commitments []*edwards25519.Point
+	for _, b := range pkg.Commitments {
+		c, err := parsePoint(b)
+		if err != nil {
+			return nil, err
+		}
+		commitments = append(commitments, c)
+	}
+	r, err := parsePoint(pkg.ProofR)
+	if err != nil {
+		return nil, err
+	}
+	z, err := parseScalar(pkg.ProofZ)
+	if err != nil {
+		return nil, err
+	}
+	// check R == z*B - c*C0
+	c := proofContext(pkg.ID, commitments[0], r)
+	negC := edwards25519.NewScalar().Negate(c)
+	rr := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(negC, commitments[0], z)
+	if rr.Equal(r) != 1 {
+		return nil, fmt.Errorf("%w: participant %d", ErrProofOfKnowledge, pkg.ID)
+	}
+	return commitments, nil
+}
+
+// verifyRound1 verifies all round 1 packages, which must contain exactly one
+// package per participant, and returns the commitments indexed by ID-1.
+func verifyRound1(t, n int, pkgs []*Round1Package) ([][]*edwards25519.Point, error) {
+	if len(pkgs) != n {
+		return nil, fmt.Errorf("frost: need %d round 1 packages, have %d",
+			n, len(pkgs))
+	}
+	commitments := make([][]*edwards25519.Point, n)
+	for _, pkg := range pkgs {
+		c, err := pkg.verify(t, n)
+		if err != nil {
+			return nil, err
+		}
+		if commitments[pkg.ID-1] != nil {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateID, pkg.ID)
+		}
+		commitments[pkg.ID-1] = c
+	}
+	return commitments, nil
+}
+
+// polynomial parses the secret polynomial.
+func (sec *Round1Secret) polynomial() ([]*edwards25519.Scalar, error) {
+	var coeffs []*edwards25519.Scalar
+	for _, b := range sec.Coefficients {
+		a, err := parseScalar(b)
+		if err != nil {
+			return nil, err
+		}
+		coeffs = append(coeffs, a)
+	}
+	return coeffs, nil
+}
+
+// evaluate the polynomial coeffs at x.
+func evaluate(coeffs []*edwards25519.Scalar, x int) *edwards25519.Scalar {
+	xs := scalarFromInt(x)
+	r := edwards25519.NewScalar()
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		r.MultiplyAdd(r, xs, coeffs[i])
+	}
+	return r
+}
+
+// evaluateCommitments evaluates the committed polynomial at x "in the
+// exponent".
+func evaluateCommitments(commitments []*edwards25519.Point, x int) *edwards25519.Point {
+	xs := scalarFromInt(x)
+	r := edwards25519.NewIdentityPoint()
+	for i := len(commitments) - 1; i >= 0; i-- {
+		r.ScalarMult(xs, r)
+		r.Add(r, commitments[i])
+	}
+	return r
+}
+
+// DKGRound2 verifies the round 1 packages of all participants (including the
+// own one) and returns the secret shares for all other participants.
+func DKGRound2(sec *Round1Secret, pkgs []*Round1Package) ([]*Round2Package, error) {
+	if _, err := verifyRound1(sec.Threshold, sec.N, pkgs); err != nil {
+		return nil, err
+	}
+	coeffs, err := sec.polynomial()
+	if err != nil {
+		return nil, err
+	}
+	var shares []*Round2Package
+	for j := 1; j <= sec.N; j++ {
+		if j == sec.ID {
+			continue
+		}
+		shares = append(shares, &Round2Package{
+			From:  sec.ID,
+			To:    j,
+			Share: evaluate(coeffs, j).Bytes(),
+		})
+	}
+	return shares, nil
+}
+
+// DKGFinalize verifies the secret shares received from all other
+// participants and computes the key share of the participant.
+func DKGFinalize(
+	sec *Round1Secret,
+	round1 []*Round1Package,
+	round2 []*Round2Package,
+) (*KeyShare, error) {
+	commitments, err := verifyRound1(sec.Threshold, sec.N, round1)
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := sec.polynomial()
+	if err != nil {
+		return nil, err
+	}
+	if len(round2) != sec.N-1 {
+		return nil, fmt.Errorf("frost: need %d round 2 packages, have %d",
+			sec.N-1, len(round2))
+	}
+	// own share
+	s := evaluate(coeffs, sec.ID)
+	seen := make(map[int]bool)
+	for _, pkg := range round2 {
+		if pkg.To != sec.ID {
+			return nil, fmt.Errorf("frost: round 2 package for %d, not %d",
+				pkg.To, sec.ID)
+		}
+		if err := checkID(pkg.From, sec.N); err != nil {
+			return nil, err
+		}
+		if pkg.From == sec.ID || seen[pkg.From] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateID, pkg.From)
+		}
+		seen[pkg.From] = true
+		share, err := parseScalar(pkg.Share)
+		if err != nil {
+			return nil, err
+		}
+		expected := evaluateCommitments(commitments[pkg.From-1], sec.ID)
+		if new(edwards25519.Point).ScalarBaseMult(share).Equal(expected) != 1 {
+			return nil, fmt.Errorf("%w: from participant %d", ErrShare, pkg.From)
+		}
+		s.Add(s, share)
+	}
+	// group key and verification shares
+	pub := &PublicKey{Threshold: sec.Threshold, N: sec.N}
+	groupKey := edwards25519.NewIdentityPoint()
+	for _, c := range commitments {
+		groupKey.Add(groupKey, c[0])
+	}
+	pub.GroupKey = groupKey.Bytes()
+	for l := 1; l <= sec.N; l++ {
+		y := edwards25519.NewIdentityPoint()
+		for _, c := range commitments {
+			y.Add(y, evaluateCommitments(c, l))
+		}
+		pub.VerificationShares = append(pub.VerificationShares, y.Bytes())
+	}
+	own := new(edwards25519.Point).ScalarBaseMult(s)
+	if !bytes.Equal(own.Bytes(), pub.VerificationShares[sec.ID-1]) {
+		return nil, ErrShare
+	}
+	return &KeyShare{ID: sec.ID, Secret: s.Bytes(), Public: pub}, nil
+}
@@ -0,0 +1,183 @@
+// Package frost implements FROST threshold signatures for Ed25519, following
+// RFC 9591 (ciphersuite FROST(Ed25519, SHA-512)), together with a distributed
+// key generation based on Pedersen's DKG with proofs of knowledge.
+//
+// A group of n participants jointly generates an Ed25519 key of which every
+// participant only holds a share. Any threshold t of them can then produce a
+// signature which is a standard Ed25519 signature under the group public key,
+// that is, it verifies with ed25519.Verify.
+//
+// All messages between participants are plain structs which can be marshaled
+// as JSON and passed around as files.
+package frost
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"filippo.io/edwards25519"
+)
+
+// contextString of the FROST(Ed25519, SHA-512) ciphersuite.
+const contextString = "FROST-ED25519-SHA512-v1"
+
+// ErrInvalidID is returned if a participant identifier is out of range.
+var ErrInvalidID = errors.New("frost: invalid participant identifier")
+
+// ErrInvalidThreshold is returned if the threshold is out of range.
+var ErrInvalidThreshold = errors.New("frost: invalid threshold")
+
+// ErrDuplicateID is returned if a participant identifier appears twice.
+var ErrDuplicateID = errors.New("frost: duplicate participant identifier")
+
+// ErrProofOfKnowledge is returned if a DKG proof of knowledge doesn't verify.
+var ErrProofOfKnowledge = errors.New("frost: proof of knowledge does not verify")
+
+// ErrShare is returned if a secret share doesn't match the commitments of the
+// dealer.
+var ErrShare = errors.New("frost: secret share does not match commitments")
+
+// ErrSignatureShare is returned if a signature share doesn't verify.
+var ErrSignatureShare = errors.New("frost: signature share does not verify")
+
+// ErrSignature is returned if an aggregated signature doesn't verify.
+var ErrSignature = errors.New("frost: signature does not verify")
+
+// ErrTooFewSigners is returned if less than threshold signers take part.
+var ErrTooFewSigners = errors.New("frost: too few signers")
+
+// ErrNotSigner is returned if a participant is not part of a signing set.
+var ErrNotSigner = errors.New("frost: participant not in signing set")
+
+// ErrIdentityCommitment is returned if a signing commitment is the identity
+// element, which would cancel the nonce of the participant.
+var ErrIdentityCommitment = errors.New("frost: commitment is identity element")
+
+// scalarFromInt returns i as scalar.
+func scalarFromInt(i int) *edwards25519.Scalar {
+	var buf [32]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(i))
+	s, err := edwards25519.NewScalar().SetCanonicalBytes(buf[:])
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	return s
+}
+
+// randRead fills b with random bytes.
+func randRead(b []byte) (int, error) {
+	return io.ReadFull(rand.Reader, b)
+}
+
+// randomScalar returns a uniformly random scalar.
+func randomScalar() (*edwards25519.Scalar, error) {
+	var buf [64]byte
+	if _, err := io.ReadFull(rand.Reader, buf[:]); err != nil {
+		return nil, err
+	}
+	return edwards25519.NewScalar().SetUniformBytes(buf[:])
+}
+
+// hashToScalar hashes the concatenation of msgs with SHA-512 and reduces the
+// result to a scalar.
+func hashToScalar(msgs ...[]byte) *edwards25519.Scalar {
+	h := sha512.New()
+	for _, m := range msgs {
+		h.Write(m)
+	}
+	s, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	return s
+}
+
+// hash returns the SHA-512 hash of the concatenation of msgs.
+func hash(msgs ...[]byte) []byte {
+	h := sha512.New()
+	for _, m := range msgs {
+		h.Write(m)
+	}
+	return h.Sum(nil)
+}
+
+// h1 is the hash function H1 of RFC 9591 (binding factors).
+func h1(m []byte) *edwards25519.Scalar {
+	return hashToScalar([]byte(contextString+"rho"), m)
+}
+
+// h2 is the hash function H2 of RFC 9591 (challenge, equal to Ed25519).
+func h2(m ...[]byte) *edwards25519.Scalar {
+	return hashToScalar(m...)
+}
+
+// h3 is the hash function H3 of RFC 9591 (nonce generation).
+func h3(m ...[]byte) *edwards25519.Scalar {
+	return hashToScalar(append([][]byte{[]byte(contextString + "nonce")}, m...)...)
+}
+
+// h4 is the hash function H4 of RFC 9591 (message).
+func h4(m []byte) []byte {
+	return hash([]byte(contextString+"msg"), m)
+}
+
+// h5 is the hash function H5 of RFC 9591 (commitment list).
+func h5(m []byte) []byte {
+	return hash([]byte(contextString+"com"), m)
+}
+
+// hdkg is the hash function for DKG proofs of knowledge.
+func hdkg(m ...[]byte) *edwards25519.Scalar {
+	return hashToScalar(append([][]byte{[]byte(contextString + "dkg")}, m...)...)
+}
+
+// parseScalar parses a canonical scalar encoding.
+func parseScalar(b []byte) (*edwards25519.Scalar, error) {
+	return edwards25519.NewScalar().SetCanonicalBytes(b)
+}
+
+// parsePoint parses a point encoding.
+func parsePoint(b []byte) (*edwards25519.Point, error) {
+	return new(edwards25519.Point).SetBytes(b)
+}
+
+// checkParams checks the threshold parameters t and n.
+func checkParams(t, n int) error {
+	if t < 1 || t > n {
+		return ErrInvalidThreshold
+	}
+	return nil
+}
+
+// checkID checks the participant identifier id for n participants.
+func checkID(id, n int) error {
+	if id < 1 || id > n {
+		return ErrInvalidID
+	}
+	return nil
+}
+
+// lagrange returns the Lagrange coefficient for participant id in the
+// signing set ids.
+func lagrange(id int, ids []int) (*edwards25519.Scalar, error) {
+	num := scalarFromInt(1)
+	den := scalarFromInt(1)
+	found := false
+	x := scalarFromInt(id)
+	for _, j := range ids {
+		if j == id {
+			found = true
+			continue
+		}
+		xj := scalarFromInt(j)
+		num.Multiply(num, xj)
+		den.Multiply(den, edwards25519.NewScalar().Subtract(xj, x))
+	}
+	if !found {
+		return nil, ErrNotSigner
+	}
+	return num.Multiply(num, den.Invert(den)), nil
+}
@@ -0,0 +1,176 @@
+package frost
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"filippo.io/edwards25519"
+)
+
+// dkg runs a distributed key generation for t-of-n participants.
+func dkg(t *testing.T, threshold, n int) []*KeyShare {
+	var (
+		secrets []*Round1Secret
+		round1  []*Round1Package
+	)
+	for id := 1; id <= n; id++ {
+		sec, pkg, err := DKGRound1(id, threshold, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secrets = append(secrets, sec)
+		round1 = append(round1, pkg)
+	}
+	round2 := make(map[int][]*Round2Package)
+	for _, sec := range secrets {
+		pkgs, err := DKGRound2(sec, round1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pkg := range pkgs {
+			round2[pkg.To] = append(round2[pkg.To], pkg)
+		}
+	}
+	var shares []*KeyShare
+	for _, sec := range secrets {
+		ks, err := DKGFinalize(sec, round1, round2[sec.ID])
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, ks)
+	}
+	for _, ks := range shares[1:] {
+		if !bytes.Equal(ks.Public.GroupKey, shares[0].Public.GroupKey) {
+			t.Fatal("participants disagree on group key")
+		}
+	}
+	return shares
+}
+
+// sign msg with the given key shares.
+func sign(t *testing.T, signers []*KeyShare, msg []byte) ([]*Commitment, []*SignatureShare) {
+	var (
+		nonces      []*Nonce
+		commitments []*Commitment
+		shares      []*SignatureShare
+	)
+	for _, ks := range signers {
+		nonce, commitment, err := Commit(ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces = append(nonces, nonce)
+		commitments = append(commitments, commitment)
+	}
+	for i, ks := range signers {
+		share, err := Sign(ks, nonces[i], msg, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, share)
+	}
+	return commitments, shares
+}
+
+func TestFROST(t *testing.T) {
+	testCases := []struct {
+		threshold int
+		n         int
+		signers   []int
+	}{
+		{1, 1, []int{1}},
+		{2, 3, []int{1, 2}},
+		{2, 3, []int{3, 1}},
+		{2, 3, []int{1, 2, 3}},
+		{3, 5, []int{2, 4, 5}},
+	}
+	msg := []byte("key list encoding")
+	for _, tc := range testCases {
+		keyShares := dkg(t, tc.threshold, tc.n)
+		pub := keyShares[0].Public
+		var signers []*KeyShare
+		for _, id := range tc.signers {
+			signers = append(signers, keyShares[id-1])
+		}
+		commitments, shares := sign(t, signers, msg)
+		sig, err := Aggregate(pub, msg, commitments, shares)
+		if err != nil {
+			t.Fatalf("%d-of-%d %v: %v", tc.threshold, tc.n, tc.signers, err)
+		}
+		if !ed25519.Verify(pub.GroupKey, msg, sig) {
+			t.Errorf("%d-of-%d %v: signature doesn't verify", tc.threshold,
+				tc.n, tc.signers)
+		}
+	}
+}
+
+func TestFROSTFailures(t *testing.T) {
+	keyShares := dkg(t, 2, 3)
+	pub := keyShares[0].Public
+	msg := []byte("message")
+
+	// too few signers
+	_, commitment, err := Commit(keyShares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Aggregate(pub, msg, []*Commitment{commitment}, nil)
+	if !errors.Is(err, ErrTooFewSigners) {
+		t.Errorf("Aggregate() should fail with ErrTooFewSigners, got: %v", err)
+	}
+
+	// corrupted signature share
+	commitments, shares := sign(t, keyShares[:2], msg)
+	shares[1].Share = shares[0].Share
+	_, err = Aggregate(pub, msg, commitments, shares)
+	if !errors.Is(err, ErrSignatureShare) {
+		t.Errorf("Aggregate() should fail with ErrSignatureShare, got: %v", err)
+	}
+
+	// identity commitment
+	nonce, commitment, err := Commit(keyShares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, other, err := Commit(keyShares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Hiding = edwards25519.NewIdentityPoint().Bytes()
+	commitments = []*Commitment{commitment, other}
+	_, err = Sign(keyShares[0], nonce, msg, commitments)
+	if !errors.Is(err, ErrIdentityCommitment) {
+		t.Errorf("Sign() should fail with ErrIdentityCommitment, got: %v", err)
+	}
+	_, err = Aggregate(pub, msg, commitments, nil)
+	if !errors.Is(err, ErrIdentityCommitment) {
+		t.Errorf("Aggregate() should fail with ErrIdentityCommitment, got: %v", err)
+	}
+
+	// corrupted DKG share
+	sec, pkg1, err := DKGRound1(1, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec2, pkg2, err := DKGRound1(2, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	round1 := []*Round1Package{pkg1, pkg2}
+	round2, err := DKGRound2(sec2, round1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	round2[0].Share = scalarFromInt(42).Bytes()
+	if _, err := DKGFinalize(sec, round1, round2); !errors.Is(err, ErrShare) {
+		t.Errorf("DKGFinalize() should fail with ErrShare, got: %v", err)
+	}
+
+	// invalid proof of knowledge
+	pkg2.ProofZ = scalarFromInt(42).Bytes()
+	if _, err := DKGRound2(sec, round1); !errors.Is(err, ErrProofOfKnowledge) {
+		t.Errorf("DKGRound2() should fail with ErrProofOfKnowledge, got: %v", err)
+	}
+}
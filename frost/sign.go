@@ -0,0 +1,245 @@
+package frost
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sort"
+
+	"filippo.io/edwards25519"
+)
+
+// Nonce is the secret nonce pair of a participant for a single signing
+// operation. It must not be shared and must never be used twice.
+type Nonce struct {
+	ID      int    // identifier of participant
+	Hiding  []byte // hiding nonce (scalar)
+	Binding []byte // binding nonce (scalar)
+}
+
+// Commitment is the public commitment to a Nonce. It is sent to all other
+// signers.
+type Commitment struct {
+	ID      int    // identifier of participant
+	Hiding  []byte // hiding nonce commitment (point)
+	Binding []byte // binding nonce commitment (point)
+}
+
+// SignatureShare is the signature share of a participant.
+type SignatureShare struct {
+	ID    int    // identifier of participant
+	Share []byte // signature share (scalar)
+}
+
+// generateNonce implements nonce_generate of RFC 9591.
+func generateNonce(secret *edwards25519.Scalar) (*edwards25519.Scalar, error) {
+	var random [32]byte
+	if _, err := randRead(random[:]); err != nil {
+		return nil, err
+	}
+	return h3(random[:], secret.Bytes()), nil
+}
+
+// Commit generates a new nonce pair for key share ks and the corresponding
+// commitment (first round of signing).
+func Commit(ks *KeyShare) (*Nonce, *Commitment, error) {
+	s, err := parseScalar(ks.Secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	d, err := generateNonce(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	e, err := generateNonce(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := &Nonce{ID: ks.ID, Hiding: d.Bytes(), Binding: e.Bytes()}
+	commitment := &Commitment{
+		ID:      ks.ID,
+		Hiding:  new(edwards25519.Point).ScalarBaseMult(d).Bytes(),
+		Binding: new(edwards25519.Point).ScalarBaseMult(e).Bytes(),
+	}
+	return nonce, commitment, nil
+}
+
+// signingPackage contains the parsed and sorted commitments of a signing
+// operation together with the derived values.
+type signingPackage struct {
+	ids       []int
+	hiding    map[int]*edwards25519.Point
+	binding   map[int]*edwards25519.Point
+	rho       map[int]*edwards25519.Scalar
+	groupComm *edwards25519.Point
+	challenge *edwards25519.Scalar
+}
+
+// newSigningPackage computes binding factors, group commitment, and
+// challenge for signing msg under pub with the given commitments.
+func newSigningPackage(
+	pub *PublicKey,
+	msg []byte,
+	commitments []*Commitment,
+) (*signingPackage, error) {
+	if len(pub.VerificationShares) != pub.N {
+		return nil, fmt.Errorf("frost: public key has %d verification shares, not %d",
+			len(pub.VerificationShares), pub.N)
+	}
+	if len(commitments) < pub.Threshold {
+		return nil, fmt.Errorf("%w: need %d, have %d", ErrTooFewSigners,
+			pub.Threshold, len(commitments))
+	}
+	cs := make([]*Commitment, len(commitments))
+	copy(cs, commitments)
+	sort.Slice(cs, func(i, j int) bool { return cs[i].ID < cs[j].ID })
+	sp := &signingPackage{
+		hiding:  make(map[int]*edwards25519.Point),
+		binding: make(map[int]*edwards25519.Point),
+		rho:     make(map[int]*edwards25519.Scalar),
+	}
+	identity := edwards25519.NewIdentityPoint()
+	var encCommitments []byte
+	for _, c := range cs {
+		if err := checkID(c.ID, pub.N); err != nil {
+			return nil, err
+		}
+		if _, ok := sp.hiding[c.ID]; ok {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateID, c.ID)
+		}
+		d, err := parsePoint(c.Hiding)
+		if err != nil {
+			return nil, err
+		}
+		e, err := parsePoint(c.Binding)
+		if err != nil {
+			return nil, err
+		}
+		if d.Equal(identity) == 1 || e.Equal(identity) == 1 {
+			return nil, fmt.Errorf("%w: participant %d", ErrIdentityCommitment, c.ID)
+		}
+		sp.ids = append(sp.ids, c.ID)
+		sp.hiding[c.ID] = d
+		sp.binding[c.ID] = e
+		encCommitments = append(encCommitments, scalarFromInt(c.ID).Bytes()...)
+		encCommitments = append(encCommitments, d.Bytes()...)
+		encCommitments = append(encCommitments, e.Bytes()...)
+	}
+	// binding factors
+	prefix := append([]byte{}, pub.GroupKey...)
+	prefix = append(prefix, h4(msg)...)
+	prefix = append(prefix, h5(encCommitments)...)
+	for _, id := range sp.ids {
+		sp.rho[id] = h1(append(append([]byte{}, prefix...), scalarFromInt(id).Bytes()...))
+	}
+	// group commitment
+	sp.groupComm = edwards25519.NewIdentityPoint()
+	for _, id := range sp.ids {
+		sp.groupComm.Add(sp.groupComm, sp.hiding[id])
+		sp.groupComm.Add(sp.groupComm,
+			new(edwards25519.Point).ScalarMult(sp.rho[id], sp.binding[id]))
+	}
+	// challenge
+	sp.challenge = h2(sp.groupComm.Bytes(), pub.GroupKey, msg)
+	return sp, nil
+}
+
+// Sign msg with key share ks and the nonce generated by Commit, given the
+// commitments of all signers (second round of signing).
+// The caller must delete the nonce afterwards.
+func Sign(
+	ks *KeyShare,
+	nonce *Nonce,
+	msg []byte,
+	commitments []*Commitment,
+) (*SignatureShare, error) {
+	if nonce.ID != ks.ID {
+		return nil, fmt.Errorf("frost: nonce of participant %d, not %d",
+			nonce.ID, ks.ID)
+	}
+	sp, err := newSigningPackage(ks.Public, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+	d, err := parseScalar(nonce.Hiding)
+	if err != nil {
+		return nil, err
+	}
+	e, err := parseScalar(nonce.Binding)
+	if err != nil {
+		return nil, err
+	}
+	// make sure our commitment is part of the signing set
+	if _, ok := sp.hiding[ks.ID]; !ok {
+		return nil, ErrNotSigner
+	}
+	if new(edwards25519.Point).ScalarBaseMult(d).Equal(sp.hiding[ks.ID]) != 1 ||
+		new(edwards25519.Point).ScalarBaseMult(e).Equal(sp.binding[ks.ID]) != 1 {
+		return nil, fmt.Errorf("frost: commitment of participant %d doesn't match nonce",
+			ks.ID)
+	}
+	s, err := parseScalar(ks.Secret)
+	if err != nil {
+		return nil, err
+	}
+	lambda, err := lagrange(ks.ID, sp.ids)
+	if err != nil {
+		return nil, err
+	}
+	// z = d + e*rho + lambda*s*c
+	z := edwards25519.NewScalar().MultiplyAdd(e, sp.rho[ks.ID], d)
+	lsc := edwards25519.NewScalar().Multiply(lambda, s)
+	z.MultiplyAdd(lsc, sp.challenge, z)
+	return &SignatureShare{ID: ks.ID, Share: z.Bytes()}, nil
+}
+
+// Aggregate the signature shares of all signers into an Ed25519 signature on
+// msg under the group key of pub. Every signature share is verified.
+func Aggregate(
+	pub *PublicKey,
+	msg []byte,
+	commitments []*Commitment,
+	shares []*SignatureShare,
+) ([]byte, error) {
+	sp, err := newSigningPackage(pub, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+	if len(shares) != len(sp.ids) {
+		return nil, fmt.Errorf("frost: have %d commitments, but %d signature shares",
+			len(sp.ids), len(shares))
+	}
+	z := edwards25519.NewScalar()
+	seen := make(map[int]bool)
+	for _, share := range shares {
+		if _, ok := sp.hiding[share.ID]; !ok || seen[share.ID] {
+			return nil, fmt.Errorf("%w: %d", ErrNotSigner, share.ID)
+		}
+		seen[share.ID] = true
+		zi, err := parseScalar(share.Share)
+		if err != nil {
+			return nil, err
+		}
+		// verify share: zi*B == D + rho*E + (c*lambda)*Y
+		y, err := parsePoint(pub.VerificationShares[share.ID-1])
+		if err != nil {
+			return nil, err
+		}
+		lambda, err := lagrange(share.ID, sp.ids)
+		if err != nil {
+			return nil, err
+		}
+		cl := edwards25519.NewScalar().Multiply(sp.challenge, lambda)
+		expected := new(edwards25519.Point).ScalarMult(sp.rho[share.ID], sp.binding[share.ID])
+		expected.Add(expected, sp.hiding[share.ID])
+		expected.Add(expected, new(edwards25519.Point).ScalarMult(cl, y))
+		if new(edwards25519.Point).ScalarBaseMult(zi).Equal(expected) != 1 {
+			return nil, fmt.Errorf("%w: participant %d", ErrSignatureShare, share.ID)
+		}
+		z.Add(z, zi)
+	}
+	sig := append(sp.groupComm.Bytes(), z.Bytes()...)
+	if !ed25519.Verify(pub.GroupKey, msg, sig) {
+		return nil, ErrSignature
+	}
+	return sig, nil
+}
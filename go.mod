@@ -1,11 +1,12 @@
 module github.com/scritcash/scrit
 
-go 1.13
+go 1.16
 
 require (
+	filippo.io/edwards25519 v1.0.0
 	github.com/davecgh/go-spew v1.1.1
 	github.com/fatih/color v1.9.0 // indirect
 	github.com/frankbraun/codechain v1.0.1
-	golang.org/x/crypto v0.0.0-20200115085410-6d4e4cb37c7d // indirect
+	golang.org/x/crypto v0.0.0-20200115085410-6d4e4cb37c7d
 	golang.org/x/sys v0.0.0-20200116001909-b77594299b42 // indirect
 )
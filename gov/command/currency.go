@@ -0,0 +1,32 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/scritcash/scrit/gov/currency/command"
+)
+
+func usageCurrency(cmd string) error {
+	fmt.Fprintf(os.Stderr, "Usage: %s add\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s list\n", cmd)
+	return flag.ErrHelp
+}
+
+// Currency implements the scrit-gov 'currency' command.
+func Currency(argv0 string, args ...string) error {
+	if len(args) < 1 {
+		return usageCurrency(argv0)
+	}
+	newArgv0 := argv0 + " " + args[0]
+	newArgs := args[1:]
+	switch args[0] {
+	case "add":
+		return command.Add(newArgv0, newArgs...)
+	case "list":
+		return command.List(newArgv0, newArgs...)
+	default:
+		return usageCurrency(argv0)
+	}
+}
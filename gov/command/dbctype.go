@@ -12,6 +12,7 @@ func usageDBCType(cmd string) error {
 	fmt.Fprintf(os.Stderr, "Usage: %s add\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s remove\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s list\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s series [add|remove]\n", cmd)
 	return flag.ErrHelp
 }
 
@@ -29,6 +30,8 @@ func DBCType(argv0 string, args ...string) error {
 		return command.Remove(newArgv0, newArgs...)
 	case "list":
 		return command.List(newArgv0, newArgs...)
+	case "series":
+		return command.Series(newArgv0, newArgs...)
 	default:
 		return usageDBCType(argv0)
 	}
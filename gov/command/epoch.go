@@ -11,6 +11,7 @@ import (
 func usageEpoch(cmd string) error {
 	fmt.Fprintf(os.Stderr, "Usage: %s add\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s setquorum\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s schedule\n", cmd)
 	return flag.ErrHelp
 }
 
@@ -26,6 +27,8 @@ func Epoch(argv0 string, args ...string) error {
 		return command.Add(newArgv0, newArgs...)
 	case "setquorum":
 		return command.SetQuorum(newArgv0, newArgs...)
+	case "schedule":
+		return command.Schedule(newArgv0, newArgs...)
 	default:
 		return usageEpoch(argv0)
 	}
@@ -0,0 +1,124 @@
+package command
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// federationFiles returns all federation files in the current directory.
+func federationFiles() ([]string, error) {
+	files := []string{netconf.DefNetConfFile}
+	for _, dir := range []string{netconf.DefMintDir, netconf.DefRevocationDir} {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	matches, err := filepath.Glob(filepath.Join(netconf.DefDBCDir, "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, matches...)
+	return files, nil
+}
+
+// formatFile rewrites filename in canonical form, if necessary. In check mode
+// the file is not rewritten. It returns true, if the file was not canonical.
+func formatFile(filename string, check bool) (bool, error) {
+	lock, err := netconf.LockFile(filename)
+	if err != nil {
+		return false, err
+	}
+	defer lock.Unlock()
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+	canonical, err := netconf.CanonicalFile(filename, data)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(data, canonical) {
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return false, err
+	}
+	if err := lock.Check(); err != nil {
+		return false, err
+	}
+	if err := atomicfile.WriteFile(filename, canonical, fi.Mode().Perm()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func formatFiles(files []string, check bool) error {
+	var n int
+	for _, filename := range files {
+		changed, err := formatFile(filename, check)
+		if err != nil {
+			return err
+		}
+		if changed {
+			n++
+			if check {
+				fmt.Printf("'%s' not in canonical form\n", filename)
+			} else {
+				fmt.Printf("'%s' formatted\n", filename)
+			}
+		}
+	}
+	if check && n > 0 {
+		return fmt.Errorf("%d file(s) not in canonical form, run 'scrit-gov fmt'", n)
+	}
+	return nil
+}
+
+// Fmt implements the scrit-gov 'fmt' command.
+func Fmt(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-check] [file ...]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Rewrite federation files in canonical JSON encoding.\n")
+		fmt.Fprintf(os.Stderr, "Default: %s and all files in '%s', '%s', and '%s'.\n",
+			netconf.DefNetConfFile, netconf.DefMintDir, netconf.DefRevocationDir,
+			netconf.DefDBCDir)
+		fmt.Fprintf(os.Stderr, "Files must be given relative to the federation directory.\n")
+		fs.PrintDefaults()
+	}
+	check := fs.Bool("check", false, "Only check files, fail if not in canonical form")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		var err error
+		files, err = federationFiles()
+		if err != nil {
+			return err
+		}
+	}
+	return formatFiles(files, *check)
+}
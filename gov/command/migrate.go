@@ -0,0 +1,134 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+// fileVersion returns the format version of filename.
+func fileVersion(filename string) (int, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0, err
+	}
+	return netconf.FileVersion(data)
+}
+
+// migrateNetwork migrates the network configuration, if necessary, and
+// returns it.
+func migrateNetwork() (*netconf.Network, error) {
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+	version, err := fileVersion(netconf.DefNetConfFile)
+	if err != nil {
+		return nil, err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := net.Validate(); err != nil {
+		return nil, err
+	}
+	if version == netconf.NetworkVersion {
+		return net, nil
+	}
+	if err := lock.Check(); err != nil {
+		return nil, err
+	}
+	if err := net.Save(netconf.DefNetConfFile); err != nil {
+		return nil, err
+	}
+	fmt.Printf("'%s' migrated from version %d to %d\n", netconf.DefNetConfFile,
+		version, netconf.NetworkVersion)
+	return net, nil
+}
+
+// migrateMint migrates the mint key list filename, if necessary.
+func migrateMint(net *netconf.Network, filename string) error {
+	lock, err := netconf.LockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	version, err := fileVersion(filename)
+	if err != nil {
+		return err
+	}
+	if version == netconf.MintVersion {
+		return nil
+	}
+	mint, err := netconf.LoadMint(filename)
+	if err != nil {
+		return err
+	}
+	if err := mint.Validate(net); err != nil {
+		return fmt.Errorf("'%s': %w", filename, err)
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	if err := mint.Save(filename, fi.Mode().Perm()); err != nil {
+		return err
+	}
+	fmt.Printf("'%s' migrated from version %d to %d\n", filename, version,
+		netconf.MintVersion)
+	return nil
+}
+
+func migrate() error {
+	net, err := migrateNetwork()
+	if err != nil {
+		return err
+	}
+	mints, err := filepath.Glob(filepath.Join(netconf.DefMintDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, filename := range mints {
+		if err := migrateMint(net, filename); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migrate implements the scrit-gov 'migrate' command.
+func Migrate(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Migrate %s and key lists in '%s' to current file format.\n",
+			netconf.DefNetConfFile, netconf.DefMintDir)
+		fs.PrintDefaults()
+	}
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return migrate()
+}
@@ -19,6 +19,11 @@ func start(
 	signStart, signEnd, validationEnd time.Time,
 	mintIdentities []netconf.IdentityKey,
 ) error {
+	lock, err := netconf.LockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 	exists, err := file.Exists(filename)
 	if err != nil {
 		return err
@@ -31,6 +36,9 @@ func start(
 	if err := net.Validate(); err != nil {
 		return err
 	}
+	if err := lock.Check(); err != nil {
+		return err
+	}
 	return net.Save(filename)
 }
 
@@ -4,20 +4,49 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"sort"
+	"time"
 
 	"github.com/frankbraun/codechain/secpkg"
 	"github.com/frankbraun/codechain/util/log"
 	"github.com/scritcash/scrit/netconf"
 )
 
+func statusAt(net *netconf.Network, t time.Time) error {
+	i, err := net.SigningEpochAt(t)
+	if err != nil {
+		return err
+	}
+	mints, err := net.MintsAt(t)
+	if err != nil {
+		return err
+	}
+	e := net.NetworkEpochs[i]
+	fmt.Printf("epoch %d: %s - %s (validation until %s)\n", i,
+		e.SignStart.Format(time.RFC3339), e.SignEnd.Format(time.RFC3339),
+		e.ValidateEnd.Format(time.RFC3339))
+	fmt.Printf("quorum: %d-of-%d\n", e.QuorumM, e.NumberOfMintsN)
+	fmt.Printf("validating epochs: %v\n", net.ValidatingEpochsAt(t))
+	var ids []string
+	for id := range mints {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+	return nil
+}
+
 // Status implements the scrit-gov 'status' command.
 func Status(argv0 string, args ...string) error {
 	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Usage: %s [-at time]\n", argv0)
 		fmt.Fprintf(os.Stderr, "Print status of %s.\n", netconf.DefNetConfFile)
 		fs.PrintDefaults()
 	}
+	at := fs.String("at", "", "Show signing epoch and mints as of given time (RFC3339)")
 	verbose := fs.Bool("v", false, "Be verbose")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -39,6 +68,13 @@ func Status(argv0 string, args ...string) error {
 	if err := net.Validate(); err != nil {
 		return err
 	}
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+		return statusAt(net, t)
+	}
 	fmt.Println(net.Marshal())
 	return nil
 }
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func printSupply(fed *netconf.Federation, supply []netconf.Supply) error {
+	last := len(fed.Network.NetworkEpochs) - 1
+	totals := make(map[string]uint64)
+	for _, s := range supply {
+		value, err := s.Value()
+		if err != nil {
+			return err
+		}
+		v := netconf.DBCType{Currency: s.DBCType.Currency, Amount: value}
+		fmt.Printf("epoch %d %s: created %d, destroyed %d, outstanding %d (%s)\n",
+			s.Epoch, s.DBCType, s.Created, s.Destroyed, s.Outstanding, v)
+		if s.Epoch == last {
+			totals[s.DBCType.Currency] += value
+		}
+	}
+	var currencies []string
+	for c := range totals {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	for _, c := range currencies {
+		fmt.Printf("outstanding: %s\n", netconf.DBCType{Currency: c, Amount: totals[c]})
+	}
+	return nil
+}
+
+// Supply implements the scrit-gov 'supply' command.
+func Supply(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Report outstanding DBC supply per DBC type and epoch.\n")
+		fmt.Fprintf(os.Stderr, "The supply is computed from the ledgers in '%s'.\n", netconf.DefDBCDir)
+		fs.PrintDefaults()
+	}
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	opts := &netconf.LoadOptions{Logger: log.Std}
+	fed, r, err := netconf.LoadFederationFS(os.DirFS("."), opts)
+	if err != nil {
+		return err
+	}
+	supply, sr := fed.Supply()
+	if err := r.WriteText(os.Stderr); err != nil {
+		return err
+	}
+	if err := sr.WriteText(os.Stderr); err != nil {
+		return err
+	}
+	if err := printSupply(fed, supply); err != nil {
+		return err
+	}
+	if r.HasErrors() || sr.HasErrors() {
+		return errors.New("supply is inconsistent")
+	}
+	return nil
+}
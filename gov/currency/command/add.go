@@ -0,0 +1,89 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func add(net *netconf.Network, c netconf.Currency) error {
+	// make sure currency has not been defined yet
+	if _, err := netconf.LookupCurrency(c.Code); err == nil {
+		return fmt.Errorf("currency already defined: %s", c.Code)
+	}
+	// declare custom asset
+	return net.CurrencyAdd(c)
+}
+
+// Add implements the scrit-gov 'currency add' command.
+func Add(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Declare custom asset in %s, DBC types can be defined in it afterwards.\n",
+			netconf.DefNetConfFile)
+		fs.PrintDefaults()
+	}
+	code := fs.String("code", "", "Asset code (2 to 12 uppercase letters and digits)")
+	decimals := fs.Int("decimals", -1, fmt.Sprintf("Decimal places of smallest unit (at most %d)",
+		netconf.AmountDecimals))
+	symbol := fs.String("symbol", "", "Display symbol of asset (optional)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *code == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -code is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if *decimals == -1 {
+		fmt.Fprintf(os.Stderr, "%s: option -decimals is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	c := netconf.Currency{Code: *code, Decimals: *decimals, Symbol: *symbol}
+	// lock
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	// load
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	// validate
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	// edit
+	if err := add(net, c); err != nil {
+		return err
+	}
+	// validate again
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	if err := net.Save(netconf.DefNetConfFile); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,2 @@
+// Package command implements the scrit-gov currency commands.
+package command
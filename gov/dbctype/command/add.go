@@ -10,17 +10,17 @@ import (
 	"github.com/scritcash/scrit/netconf"
 )
 
-func add(net *netconf.Network, currency string, amount uint64) error {
+func add(net *netconf.Network, dbcType netconf.DBCType) error {
 	// make sure network has a future epoch
 	if err := net.HasFuture(); err != nil {
 		return err
 	}
+	// make sure currency is registered and amount has the right precision
+	if err := dbcType.Validate(); err != nil {
+		return err
+	}
 	// make sure DBCType has not been defined yet
 	dbcTypes := net.DBCTypes()
-	dbcType := netconf.DBCType{
-		Currency: currency,
-		Amount:   amount,
-	}
 	if dbcTypes[dbcType] {
 		return fmt.Errorf("DBC type already defined: %v", dbcType)
 	}
@@ -38,7 +38,7 @@ func Add(argv0 string, args ...string) error {
 		fs.PrintDefaults()
 	}
 	currency := fs.String("currency", "", "Currency of DBC type to add")
-	amount := fs.Uint64("amount", 0, "Amount of DBC type to add")
+	amount := fs.String("amount", "", "Amount of DBC type to add (decimal, e.g. 1.00)")
 	verbose := fs.Bool("v", false, "Be verbose")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -50,7 +50,7 @@ func Add(argv0 string, args ...string) error {
 		fmt.Fprintf(os.Stderr, "%s: option -currency is mandatory\n", argv0)
 		return flag.ErrHelp
 	}
-	if *amount == 0 {
+	if *amount == "" {
 		fmt.Fprintf(os.Stderr, "%s: option -amount is mandatory\n", argv0)
 		return flag.ErrHelp
 	}
@@ -61,6 +61,12 @@ func Add(argv0 string, args ...string) error {
 	if err := secpkg.UpToDate("scrit"); err != nil {
 		return err
 	}
+	// lock
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 	// load
 	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
 	if err != nil {
@@ -70,15 +76,23 @@ func Add(argv0 string, args ...string) error {
 	if err := net.Validate(); err != nil {
 		return err
 	}
+	// parse DBC type (after loading, which registers the custom assets)
+	dbcType, err := netconf.ParseDBCType(*currency, *amount)
+	if err != nil {
+		return err
+	}
 	// edit
-	if err := add(net, *currency, *amount); err != nil {
+	if err := add(net, dbcType); err != nil {
 		return err
 	}
 	// validate again
 	if err := net.Validate(); err != nil {
 		return err
 	}
-	// save
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
 	if err := net.Save(netconf.DefNetConfFile); err != nil {
 		return err
 	}
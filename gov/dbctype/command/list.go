@@ -13,7 +13,7 @@ import (
 func list(net *netconf.Network) error {
 	dbcTypes := netconf.DBCTypeMapToSortedArray(net.DBCTypes())
 	for _, t := range dbcTypes {
-		fmt.Printf("%s\t%d\n", t.Currency, t.Amount)
+		fmt.Println(t.String())
 	}
 	return nil
 }
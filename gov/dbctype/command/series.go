@@ -0,0 +1,119 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func editSeries(currency, scheme, min, max string, remove bool) error {
+	// lock
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	// load
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	// validate
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	// parse series (after loading, which registers the custom assets)
+	series, err := netconf.DBCSeries(currency, scheme, min, max)
+	if err != nil {
+		return err
+	}
+	// edit
+	if remove {
+		err = net.DBCSeriesRemove(series)
+	} else {
+		err = net.DBCSeriesAdd(series)
+	}
+	if err != nil {
+		return err
+	}
+	// validate again
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	if err := net.Save(netconf.DefNetConfFile); err != nil {
+		return err
+	}
+	for _, dbcType := range series {
+		if remove {
+			fmt.Printf("removed DBC type %s\n", dbcType)
+		} else {
+			fmt.Printf("added DBC type %s\n", dbcType)
+		}
+	}
+	return nil
+}
+
+func seriesCmd(argv0 string, remove bool, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		if remove {
+			fmt.Fprintf(os.Stderr, "Remove denomination series in future epoch of %s.\n", netconf.DefNetConfFile)
+		} else {
+			fmt.Fprintf(os.Stderr, "Add denomination series to future epoch of %s.\n", netconf.DefNetConfFile)
+		}
+		fs.PrintDefaults()
+	}
+	currency := fs.String("currency", "", "Currency of DBC types")
+	scheme := fs.String("scheme", "1-2-5", "Denomination steps per decade")
+	min := fs.String("min", "", "Smallest amount of series (decimal, e.g. 0.01)")
+	max := fs.String("max", "", "Largest amount of series (decimal, e.g. 500)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *currency == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -currency is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if *min == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -min is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if *max == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -max is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return editSeries(*currency, *scheme, *min, *max, remove)
+}
+
+// Series implements the scrit-gov 'dbctype series' command.
+func Series(argv0 string, args ...string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "add":
+			return seriesCmd(argv0+" add", false, args[1:]...)
+		case "remove":
+			return seriesCmd(argv0+" remove", true, args[1:]...)
+		}
+	}
+	return seriesCmd(argv0, false, args...)
+}
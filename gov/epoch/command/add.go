@@ -9,13 +9,47 @@ import (
 	"github.com/frankbraun/codechain/secpkg"
 	"github.com/frankbraun/codechain/util/log"
 	"github.com/scritcash/scrit/netconf"
-	"github.com/scritcash/scrit/util/def"
 )
 
-func add(net *netconf.Network, siginingPeriod, validationPeriod time.Duration) error {
-	// TODO: check if signingPeriod and/or validationPeriod changes from the
-	// period used in the last epoch.
-	net.EpochAdd(siginingPeriod, validationPeriod)
+func add(
+	net *netconf.Network,
+	signingPeriod, validationPeriod time.Duration,
+	count int,
+	force bool,
+) error {
+	lastEpoch := net.NetworkEpochs[len(net.NetworkEpochs)-1]
+	// use periods of last epoch, if not defined otherwise
+	if signingPeriod == 0 {
+		signingPeriod = lastEpoch.SigningPeriod()
+	}
+	if validationPeriod == 0 {
+		validationPeriod = lastEpoch.ValidationPeriod()
+	}
+	// check if signingPeriod and/or validationPeriod changes from the period
+	// used in the last epoch
+	if !force {
+		if signingPeriod != lastEpoch.SigningPeriod() {
+			return fmt.Errorf("signing period changes from %s to %s (use -force)",
+				lastEpoch.SigningPeriod(), signingPeriod)
+		}
+		if validationPeriod != lastEpoch.ValidationPeriod() {
+			return fmt.Errorf("validation period changes from %s to %s (use -force)",
+				lastEpoch.ValidationPeriod(), validationPeriod)
+		}
+	}
+	for i := 0; i < count; i++ {
+		net.EpochAdd(signingPeriod, validationPeriod)
+		// make sure the validation end of the previous epoch still fits
+		// before the signing end of the new epoch
+		prev := net.NetworkEpochs[len(net.NetworkEpochs)-2]
+		next := net.NetworkEpochs[len(net.NetworkEpochs)-1]
+		if prev.ValidateEnd.After(next.SignEnd) {
+			return fmt.Errorf("%w: validation end %s of epoch %d is after signing end %s of new epoch",
+				netconf.ErrValidationLongerThanNextSigning,
+				prev.ValidateEnd.Format(time.RFC3339), len(net.NetworkEpochs)-2,
+				next.SignEnd.Format(time.RFC3339))
+		}
+	}
 	return nil
 }
 
@@ -27,8 +61,10 @@ func Add(argv0 string, args ...string) error {
 		fmt.Fprintf(os.Stderr, "Add new epoch to %s.\n", netconf.DefNetConfFile)
 		fs.PrintDefaults()
 	}
-	signingPeriod := fs.Duration("signing-period", def.SigningPeriod, "Length of signing period")
-	validationPeriod := fs.Duration("validation-period", def.ValidationPeriod, "Length of validation period")
+	signingPeriod := fs.Duration("signing-period", 0, "Length of signing period (default: as in last epoch)")
+	validationPeriod := fs.Duration("validation-period", 0, "Length of validation period (default: as in last epoch)")
+	count := fs.Int("count", 1, "Number of epochs to add")
+	force := fs.Bool("force", false, "Allow periods to change from last epoch")
 	verbose := fs.Bool("v", false, "Be verbose")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -36,6 +72,10 @@ func Add(argv0 string, args ...string) error {
 	if *verbose {
 		log.Std = log.NewStd(os.Stdout)
 	}
+	if *count < 1 {
+		fmt.Fprintf(os.Stderr, "%s: option -count must be positive\n", argv0)
+		return flag.ErrHelp
+	}
 	if fs.NArg() != 0 {
 		fs.Usage()
 		return flag.ErrHelp
@@ -43,6 +83,12 @@ func Add(argv0 string, args ...string) error {
 	if err := secpkg.UpToDate("scrit"); err != nil {
 		return err
 	}
+	// lock
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 	// load
 	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
 	if err != nil {
@@ -53,14 +99,17 @@ func Add(argv0 string, args ...string) error {
 		return err
 	}
 	// edit
-	if err := add(net, *signingPeriod, *validationPeriod); err != nil {
+	if err := add(net, *signingPeriod, *validationPeriod, *count, *force); err != nil {
 		return err
 	}
 	// validate again
 	if err := net.Validate(); err != nil {
 		return err
 	}
-	// save
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
 	if err := net.Save(netconf.DefNetConfFile); err != nil {
 		return err
 	}
@@ -0,0 +1,103 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+// epochState returns the state of epoch i in net at time t.
+func epochState(net *netconf.Network, i int, t time.Time) string {
+	e := net.NetworkEpochs[i]
+	switch {
+	case t.Before(e.SignStart):
+		return "future"
+	case t.Before(e.SignEnd):
+		return "signing"
+	case t.Before(e.ValidateEnd):
+		return "validating"
+	default:
+		return "expired"
+	}
+}
+
+func schedule(net *netconf.Network, count int, t time.Time) error {
+	// project future epochs on a copy of the network with the periods of the
+	// last epoch
+	defined := len(net.NetworkEpochs)
+	proj := &netconf.Network{
+		NetworkEpochs: append([]netconf.NetworkEpoch(nil), net.NetworkEpochs...),
+	}
+	lastEpoch := net.NetworkEpochs[defined-1]
+	for i := 0; i < count; i++ {
+		proj.EpochAdd(lastEpoch.SigningPeriod(), lastEpoch.ValidationPeriod())
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "EPOCH\tSIGN START\tSIGN END\tVALIDATE END\tQUORUM\tSTATE")
+	for i, e := range proj.NetworkEpochs {
+		state := epochState(proj, i, t)
+		if i >= defined {
+			state = "projected"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d-of-%d\t%s\n", i,
+			e.SignStart.Format(time.RFC3339), e.SignEnd.Format(time.RFC3339),
+			e.ValidateEnd.Format(time.RFC3339), e.QuorumM, e.NumberOfMintsN,
+			state)
+	}
+	return w.Flush()
+}
+
+// Schedule implements the scrit-gov 'epoch schedule' command.
+func Schedule(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Show epochs of %s and project future epochs.\n", netconf.DefNetConfFile)
+		fs.PrintDefaults()
+	}
+	count := fs.Int("count", 3, "Number of future epochs to project")
+	at := fs.String("at", "", "Show epoch states as of given time (RFC3339)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *count < 0 {
+		fmt.Fprintf(os.Stderr, "%s: option -count must not be negative\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	t := time.Now()
+	if *at != "" {
+		var err error
+		t, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+	}
+	// load
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	// validate
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	// show
+	return schedule(net, *count, t)
+}
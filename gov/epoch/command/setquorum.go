@@ -46,6 +46,12 @@ func SetQuorum(argv0 string, args ...string) error {
 	if err := secpkg.UpToDate("scrit"); err != nil {
 		return err
 	}
+	// lock
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 	// load
 	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
 	if err != nil {
@@ -63,7 +69,10 @@ func SetQuorum(argv0 string, args ...string) error {
 	if err := net.Validate(); err != nil {
 		return err
 	}
-	// save
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
 	if err := net.Save(netconf.DefNetConfFile); err != nil {
 		return err
 	}
@@ -52,6 +52,12 @@ func Add(argv0 string, args ...string) error {
 	if err != nil {
 		return err
 	}
+	// lock
+	lock, err := netconf.LockFile(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 	// load
 	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
 	if err != nil {
@@ -69,7 +75,10 @@ func Add(argv0 string, args ...string) error {
 	if err := net.Validate(); err != nil {
 		return err
 	}
-	// save
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
 	if err := net.Save(netconf.DefNetConfFile); err != nil {
 		return err
 	}
@@ -0,0 +1,190 @@
+// Package backup implements encrypted backups of the complete state of a
+// mint, that is, of the mint home directory.
+//
+// A backup is a gzipped tar archive of the home directory which is encrypted
+// and authenticated with a key derived from a passphrase (Argon2id and
+// secretbox, like codechain keyfiles).
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/scritcash/scrit/util/atomicfile"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// magic is the header of backup files.
+const magic = "scrit-mint-backup-v1\n"
+
+// ErrDecrypt is returned if a backup cannot be decrypted (wrong passphrase
+// or corrupted backup).
+var ErrDecrypt = errors.New("backup: cannot decrypt")
+
+// ErrFormat is returned if a file is not a backup.
+var ErrFormat = errors.New("backup: unknown file format")
+
+// skip returns true, if the file with the given name (relative to the home
+// directory) should not be part of a backup.
+func skip(name string, fi os.FileInfo) bool {
+	if !fi.Mode().IsRegular() && !fi.IsDir() {
+		return true // sockets, symlinks, etc.
+	}
+	// leftovers of interrupted writes
+	return atomicfile.IsTemp(name)
+}
+
+// deriveKey derives the backup encryption key from pass and salt.
+func deriveKey(pass, salt []byte) *[32]byte {
+	var key [32]byte
+	copy(key[:], argon2.IDKey(pass, salt, 1, 64*1024, 4, 32))
+	return &key
+}
+
+// archive writes a gzipped tar archive of all files in dir to w.
+func archive(w io.Writer, dir string) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if name == "." || skip(name, fi) {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// Create an encrypted backup of homeDir and write it to w.
+func Create(w io.Writer, homeDir string, pass []byte) error {
+	var buf bytes.Buffer
+	if err := archive(&buf, homeDir); err != nil {
+		return err
+	}
+	defer bzero.Bytes(buf.Bytes())
+	var (
+		salt  [32]byte
+		nonce [24]byte
+	)
+	if _, err := io.ReadFull(rand.Reader, salt[:]); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return err
+	}
+	key := deriveKey(pass, salt[:])
+	defer bzero.Bytes(key[:])
+	out := append([]byte(magic), salt[:]...)
+	out = append(out, nonce[:]...)
+	out = secretbox.Seal(out, buf.Bytes(), &nonce, key)
+	_, err := w.Write(out)
+	return err
+}
+
+// Extract the encrypted backup read from r into directory dir, which must
+// exist.
+func Extract(r io.Reader, dir string, pass []byte) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if len(data) < len(magic)+32+24+secretbox.Overhead ||
+		string(data[:len(magic)]) != magic {
+		return ErrFormat
+	}
+	data = data[len(magic):]
+	var nonce [24]byte
+	salt := data[:32]
+	copy(nonce[:], data[32:56])
+	key := deriveKey(pass, salt)
+	defer bzero.Bytes(key[:])
+	plain, ok := secretbox.Open(nil, data[56:], &nonce, key)
+	if !ok {
+		return ErrDecrypt
+	}
+	defer bzero.Bytes(plain)
+	gr, err := gzip.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." ||
+			strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("backup: invalid file name: %s", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+		mode := os.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("backup: unsupported file type: %s", hdr.Name)
+		}
+	}
+}
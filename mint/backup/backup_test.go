@@ -0,0 +1,74 @@
+package backup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	homeDir := t.TempDir()
+	files := map[string]string{
+		"secrets/key":                "secret",
+		"privkeylists/mint.json":     "{}",
+		"privkeylists/mint.json.bac": "old",
+	}
+	for name, content := range files {
+		path := filepath.Join(homeDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pass := []byte("passphrase")
+	var buf bytes.Buffer
+	if err := Create(&buf, homeDir, pass); err != nil {
+		t.Fatal(err)
+	}
+
+	// wrong passphrase
+	if err := Extract(bytes.NewReader(buf.Bytes()), t.TempDir(), []byte("wrong")); err != ErrDecrypt {
+		t.Errorf("Extract() should fail with ErrDecrypt, got: %v", err)
+	}
+	// manipulated backup
+	data := append([]byte{}, buf.Bytes()...)
+	data[len(data)-1] ^= 1
+	if err := Extract(bytes.NewReader(data), t.TempDir(), pass); err != ErrDecrypt {
+		t.Errorf("Extract() should fail with ErrDecrypt, got: %v", err)
+	}
+	// no backup
+	if err := Extract(bytes.NewReader([]byte("foo")), t.TempDir(), pass); err != ErrFormat {
+		t.Errorf("Extract() should fail with ErrFormat, got: %v", err)
+	}
+
+	restoreDir := t.TempDir()
+	if err := Extract(&buf, restoreDir, pass); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := ioutil.ReadFile(filepath.Join(restoreDir, name))
+		if filepath.Ext(name) == ".bac" {
+			if !os.IsNotExist(err) {
+				t.Errorf("file '%s' should not be restored", name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("file '%s' restored with wrong content", name)
+		}
+	}
+	fi, err := os.Stat(filepath.Join(restoreDir, "secrets/key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("wrong file mode: %s", fi.Mode())
+	}
+}
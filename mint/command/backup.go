@@ -0,0 +1,85 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/frankbraun/codechain/util/terminal"
+	"github.com/scritcash/scrit/mint/backup"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// readBackupPassphrase reads the backup passphrase from the terminal. If
+// confirm is true the passphrase has to be entered twice.
+func readBackupPassphrase(confirm bool) ([]byte, error) {
+	if seckey.TestPass != "" {
+		return []byte(seckey.TestPass), nil
+	}
+	fmt.Println("passphrase for backup:")
+	return terminal.ReadPassphrase(syscall.Stdin, confirm)
+}
+
+func createBackup(homeDir, filename string) error {
+	exists, err := file.Exists(filename)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("file '%s' exists already", filename)
+	}
+	pass, err := readBackupPassphrase(true)
+	if err != nil {
+		return err
+	}
+	defer bzero.Bytes(pass)
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if err := backup.Create(f, homeDir, pass); err != nil {
+		f.Close()
+		os.Remove(filename)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("backup of '%s' written to '%s'\n", homeDir, filename)
+	return nil
+}
+
+// Backup implements the scrit-mint 'backup' command.
+func Backup(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s backup_file\n", argv0)
+		fmt.Fprintf(os.Stderr, "Write encrypted backup of complete mint state.\n")
+		fs.PrintDefaults()
+	}
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	homeDir := homedir.ScritMint()
+	exists, err := file.Exists(homeDir)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("mint home directory '%s' doesn't exist", homeDir)
+	}
+	return createBackup(homeDir, fs.Arg(0))
+}
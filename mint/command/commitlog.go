@@ -0,0 +1,70 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/mint/spendbook"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func exportCommitLog(homeDir, secKey, outFile string) error {
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	s := netconf.NewKeySigner()
+	s.AddIdentityKey(ik)
+	sb, err := spendbook.Open(homeDir)
+	if err != nil {
+		return err
+	}
+	defer sb.Close()
+	l, err := sb.Export(ik, s)
+	if err != nil {
+		return err
+	}
+	if err := l.Save(outFile); err != nil {
+		return err
+	}
+	fmt.Printf("commitment log with %d entries written to '%s'\n", len(l.Entries), outFile)
+	return nil
+}
+
+// CommitLog implements the scrit-mint 'commitlog' command.
+func CommitLog(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o %s]\n", argv0, netconf.DefCommitmentLogFile)
+		fmt.Fprintf(os.Stderr, "Export spendbook as signed commitment log for audits.\n")
+		fs.PrintDefaults()
+	}
+	outFile := fs.String("o", netconf.DefCommitmentLogFile, "Write commitment log to file")
+	secKey := fs.String("s", "", "Secret key file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	return exportCommitLog(homeDir, *secKey, *outFile)
+}
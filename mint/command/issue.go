@@ -0,0 +1,122 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/mint/issue"
+	"github.com/scritcash/scrit/mint/signer"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func approveOrder(
+	homeDir, secKey, socket, deposits, orderFile, outFile string,
+	t time.Time,
+) error {
+	// use the signer server for the DBC signing keys, if it is running
+	c, err := dialSigner(socket)
+	if err != nil {
+		return err
+	}
+	var (
+		ik *netconf.IdentityKey
+		s  netconf.Signer
+	)
+	if c != nil {
+		defer c.Close()
+		sec, _, _, err := identity.Load(homeDir, secKey)
+		if err != nil {
+			return err
+		}
+		ik, s = identitySigner(sec, c)
+	} else {
+		f, err := signer.LoadFile(homeDir, secKey)
+		if err != nil {
+			return err
+		}
+		ik, s = f.IdentityKey(), f
+	}
+	operators, err := issue.LoadOperators(filepath.Join(homeDir, issue.DefOperatorsFile))
+	if err != nil {
+		return err
+	}
+	fed, r, err := netconf.LoadFederationFS(os.DirFS("."), &netconf.LoadOptions{Logger: log.Std, At: t})
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	order, err := issue.LoadOrder(orderFile)
+	if err != nil {
+		return err
+	}
+	m := &issue.Mint{
+		Federation:  fed,
+		IdentityKey: ik,
+		Signer:      s,
+		Operators:   operators,
+		Rail:        &issue.FileRail{Filename: deposits},
+	}
+	iss, err := m.Approve(".", order, t)
+	if err != nil {
+		return err
+	}
+	if err := iss.Save(outFile); err != nil {
+		return err
+	}
+	fmt.Printf("issuance order %s approved in epoch %d (%d outputs), written to '%s'\n",
+		order.ID, iss.Epoch, len(iss.Signatures), outFile)
+	return nil
+}
+
+// Issue implements the scrit-mint 'issue' command.
+func Issue(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -deposits deposits.json [-o %s] order.json\n",
+			argv0, issue.DefIssuanceFile)
+		fmt.Fprintf(os.Stderr, "Approve issuance order and create DBCs against deposit.\n")
+		fmt.Fprintf(os.Stderr, "Must be called in the federation directory, the operator must be listed in '%s'.\n",
+			issue.DefOperatorsFile)
+		fs.PrintDefaults()
+	}
+	homeDir := homedir.ScritMint()
+	deposits := fs.String("deposits", "", "Confirmed deposits (local stand-in for payment rail)")
+	outFile := fs.String("o", issue.DefIssuanceFile, "Write approved issuance to file")
+	secKey := fs.String("s", "", "Secret key file")
+	socket := fs.String("socket", filepath.Join(homeDir, signer.DefSocketFile),
+		"Unix socket of signer (used if it is running)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *deposits == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -deposits is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	// always approve at the current time, approving in a past epoch would
+	// append to its published ledger
+	return approveOrder(homeDir, *secKey, *socket, *deposits, fs.Arg(0), *outFile, time.Now())
+}
@@ -11,6 +11,14 @@ import (
 func usageKeyList(cmd string) error {
 	fmt.Fprintf(os.Stderr, "Usage: %s create\n", cmd)
 	fmt.Fprintf(os.Stderr, "       %s extend\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s roll\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s encrypt\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s unlock\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s prepare\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s sign\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s finalize\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s status\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s prune\n", cmd)
 	return flag.ErrHelp
 }
 
@@ -26,6 +34,22 @@ func KeyList(argv0 string, args ...string) error {
 		return command.Create(newArgv0, newArgs...)
 	case "extend":
 		return command.Extend(newArgv0, newArgs...)
+	case "roll":
+		return command.Roll(newArgv0, newArgs...)
+	case "encrypt":
+		return command.Encrypt(newArgv0, newArgs...)
+	case "unlock":
+		return command.Unlock(newArgv0, newArgs...)
+	case "prepare":
+		return command.Prepare(newArgv0, newArgs...)
+	case "sign":
+		return command.Sign(newArgv0, newArgs...)
+	case "finalize":
+		return command.Finalize(newArgv0, newArgs...)
+	case "status":
+		return command.Status(newArgv0, newArgs...)
+	case "prune":
+		return command.Prune(newArgv0, newArgs...)
 	default:
 		return usageKeyList(argv0)
 	}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/mint/redeem"
+	"github.com/scritcash/scrit/mint/signer"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func redeemRequest(
+	homeDir, secKey, socket, requestFile, outFile string,
+	t time.Time,
+) error {
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	// the ledger is signed with the identity key, all other signing requests
+	// go to the signer server, if it is running
+	c, err := dialSigner(socket)
+	if err != nil {
+		return err
+	}
+	if c != nil {
+		defer c.Close()
+	}
+	ik, s := identitySigner(sec, c)
+	fed, r, err := netconf.LoadFederationFS(os.DirFS("."), &netconf.LoadOptions{Logger: log.Std, At: t})
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	req, err := redeem.LoadRequest(requestFile)
+	if err != nil {
+		return err
+	}
+	m := &redeem.Mint{
+		Federation:  fed,
+		IdentityKey: ik,
+		PrivateKey:  sec,
+		Signer:      s,
+		HomeDir:     homeDir,
+	}
+	rc, err := m.Redeem(".", req, t)
+	if err != nil {
+		return err
+	}
+	if err := rc.Save(outFile); err != nil {
+		return err
+	}
+	value, err := req.Value()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("redemption request %s redeemed in epoch %d (%s to '%s'), receipt written to '%s'\n",
+		req.ID, rc.Epoch, netconf.DBCType{Currency: req.Currency(), Amount: value},
+		req.Payout, outFile)
+	return nil
+}
+
+// Redeem implements the scrit-mint 'redeem' command.
+func Redeem(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o %s] %s\n",
+			argv0, redeem.DefReceiptFile, redeem.DefRequestFile)
+		fmt.Fprintf(os.Stderr, "Redeem DBCs: spend them without outputs and log the payout.\n")
+		fmt.Fprintf(os.Stderr, "Must be called in the federation directory.\n")
+		fs.PrintDefaults()
+	}
+	homeDir := homedir.ScritMint()
+	outFile := fs.String("o", redeem.DefReceiptFile, "Write redemption receipt to file")
+	secKey := fs.String("s", "", "Secret key file")
+	socket := fs.String("socket", filepath.Join(homeDir, signer.DefSocketFile),
+		"Unix socket of signer (used if it is running)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	// always redeem at the current time, redeeming in a past epoch would
+	// append to its published ledger
+	return redeemRequest(homeDir, *secKey, *socket, fs.Arg(0), *outFile, time.Now())
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/mint/redeem"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func exportRedemptions(homeDir string, since time.Time) error {
+	fed, r, err := netconf.LoadFederationFS(os.DirFS("."), &netconf.LoadOptions{Logger: log.Std})
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	confirmed, r := fed.ConfirmedEntries(netconf.LedgerDestroyed)
+	if err := r.Err(); err != nil {
+		return err
+	}
+	redemptions, err := redeem.LoadRedemptions(redeem.RedemptionsFilename(homeDir))
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(os.Stdout)
+	err = w.Write([]string{"request", "payout", "currency", "amount", "epoch", "time"})
+	if err != nil {
+		return err
+	}
+	for _, rd := range redemptions {
+		if rd.Time.Before(since) {
+			continue
+		}
+		// only pay out redemptions confirmed by a quorum of mints
+		if !rd.Confirmed(confirmed) {
+			fmt.Fprintf(os.Stderr, "redemption %s not confirmed (yet)\n", rd.RequestID)
+			continue
+		}
+		c, err := netconf.LookupCurrency(rd.Currency)
+		if err != nil {
+			return err
+		}
+		err = w.Write([]string{
+			rd.RequestID,
+			rd.Payout,
+			rd.Currency,
+			c.FormatAmount(rd.Amount),
+			strconv.Itoa(rd.Epoch),
+			rd.Time.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// Redemptions implements the scrit-mint 'redemptions' command.
+func Redemptions(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-since time]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Export redemptions confirmed by a quorum of mints as CSV for payout processing.\n")
+		fs.PrintDefaults()
+	}
+	sinceStr := fs.String("since", "", "Only export redemptions since time (RFC3339)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	var since time.Time
+	if *sinceStr != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, *sinceStr)
+		if err != nil {
+			return err
+		}
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return exportRedemptions(homedir.ScritMint(), since)
+}
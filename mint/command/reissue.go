@@ -0,0 +1,113 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/mint/reissue"
+	"github.com/scritcash/scrit/mint/signer"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func reissueRequest(
+	homeDir, secKey, socket, requestFile, outFile string,
+	t time.Time,
+) error {
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	// use the signer server for the DBC signing keys, if it is running
+	c, err := dialSigner(socket)
+	if err != nil {
+		return err
+	}
+	var (
+		ik *netconf.IdentityKey
+		s  netconf.Signer
+	)
+	if c != nil {
+		defer c.Close()
+		ik, s = identitySigner(sec, c)
+	} else {
+		f, err := signer.LoadFile(homeDir, secKey)
+		if err != nil {
+			return err
+		}
+		ik, s = f.IdentityKey(), f
+	}
+	fed, r, err := netconf.LoadFederationFS(os.DirFS("."), &netconf.LoadOptions{Logger: log.Std, At: t})
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	req, err := reissue.LoadRequest(requestFile)
+	if err != nil {
+		return err
+	}
+	m := &reissue.Mint{
+		Federation:  fed,
+		IdentityKey: ik,
+		PrivateKey:  sec,
+		Signer:      s,
+		HomeDir:     homeDir,
+	}
+	rs, err := m.Reissue(".", req, t)
+	if err != nil {
+		return err
+	}
+	if err := rs.Save(outFile); err != nil {
+		return err
+	}
+	fmt.Printf("reissue request %s reissued in epoch %d (%d inputs, %d outputs), written to '%s'\n",
+		req.ID, rs.Issuance.Epoch, len(req.Inputs), len(req.Outputs), outFile)
+	return nil
+}
+
+// Reissue implements the scrit-mint 'reissue' command.
+func Reissue(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-o %s] %s\n",
+			argv0, reissue.DefReissuanceFile, reissue.DefRequestFile)
+		fmt.Fprintf(os.Stderr, "Reissue DBCs: spend them and create DBCs of the same value.\n")
+		fmt.Fprintf(os.Stderr, "DBCs signed with revoked keys can be reissued until the end of the recovery period.\n")
+		fmt.Fprintf(os.Stderr, "Must be called in the federation directory.\n")
+		fs.PrintDefaults()
+	}
+	homeDir := homedir.ScritMint()
+	outFile := fs.String("o", reissue.DefReissuanceFile, "Write reissuance to file")
+	secKey := fs.String("s", "", "Secret key file")
+	socket := fs.String("socket", filepath.Join(homeDir, signer.DefSocketFile),
+		"Unix socket of signer (used if it is running)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	// always reissue at the current time, reissuing in a past epoch would
+	// append to its published ledgers
+	return reissueRequest(homeDir, *secKey, *socket, fs.Arg(0), *outFile, time.Now())
+}
@@ -0,0 +1,193 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/frankbraun/codechain/util/def"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/mint/backup"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// verifyRestore makes sure that the identity key restored to dir belongs to a
+// current mint of the network and that its restored private key list
+// validates against the network and matches the public key list of the mint.
+// Private key lists of other identities (e.g., from before a rotation) are
+// skipped with a warning.
+func verifyRestore(net *netconf.Network, dir, secKey string) error {
+	exists, err := file.Exists(filepath.Join(dir, def.SecretsSubDir))
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("backup contains no identity key")
+	}
+	if secKey != "" {
+		secKey = filepath.Join(dir, def.SecretsSubDir, filepath.Base(secKey))
+	}
+	sec, _, _, err := identity.Load(dir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	id := ik.MarshalID()
+	now := time.Now()
+	mints, err := net.MintsAt(now)
+	if errors.Is(err, netconf.ErrNoSigningEpoch) {
+		mints = net.Mints() // no current epoch, accept all mints
+	} else if err != nil {
+		return err
+	}
+	if !mints[id] {
+		return fmt.Errorf("restored identity is not a current mint of %s: %s",
+			netconf.DefNetConfFile, id)
+	}
+
+	// verify private key list of restored identity
+	privFilename := filepath.Join(dir, netconf.DefPrivKeyListDir, id+".json")
+	priv, err := netconf.LoadPrivKeyList(privFilename, ik)
+	if err != nil {
+		return fmt.Errorf("restored key list '%s': %w", filepath.Base(privFilename), err)
+	}
+	if err := priv.Validate(net); err != nil {
+		return fmt.Errorf("restored key list '%s' does not validate: %w",
+			filepath.Base(privFilename), err)
+	}
+	pub, err := netconf.LoadMint(filepath.Join(netconf.DefMintDir, id+".json"))
+	if err != nil {
+		return err
+	}
+	r := netconf.KeyListStatus(priv, pub, net, now)
+	if r.HasErrors() {
+		if err := r.WriteText(os.Stderr); err != nil {
+			return err
+		}
+		return fmt.Errorf("restored key list '%s' does not match public key list",
+			filepath.Base(privFilename))
+	}
+
+	// skip private key lists of other identities
+	files, err := filepath.Glob(filepath.Join(dir, netconf.DefPrivKeyListDir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, filename := range files {
+		if filename != privFilename {
+			fmt.Fprintf(os.Stderr, "WARNING: key list '%s' of other identity not verified\n",
+				filepath.Base(filename))
+		}
+	}
+	return nil
+}
+
+func restore(net *netconf.Network, homeDir, secKey, filename string, force bool) error {
+	// make sure we do not overwrite existing state by accident
+	exists, err := file.Exists(homeDir)
+	if err != nil {
+		return err
+	}
+	if exists {
+		files, err := ioutil.ReadDir(homeDir)
+		if err != nil {
+			return err
+		}
+		if len(files) > 0 && !force {
+			return fmt.Errorf("mint home directory '%s' is not empty, use -f", homeDir)
+		}
+	}
+
+	// extract backup into temporary directory next to home directory
+	if err := os.MkdirAll(filepath.Dir(homeDir), 0755); err != nil {
+		return err
+	}
+	tmpDir, err := ioutil.TempDir(filepath.Dir(homeDir), filepath.Base(homeDir)+".restore")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	pass, err := readBackupPassphrase(false)
+	if err != nil {
+		return err
+	}
+	defer bzero.Bytes(pass)
+	if err := backup.Extract(f, tmpDir, pass); err != nil {
+		return err
+	}
+	if err := verifyRestore(net, tmpDir, secKey); err != nil {
+		return err
+	}
+
+	// replace home directory
+	if exists {
+		old := homeDir + ".old"
+		oldExists, err := file.Exists(old)
+		if err != nil {
+			return err
+		}
+		if oldExists {
+			return fmt.Errorf("directory '%s' exists already", old)
+		}
+		if err := os.Rename(homeDir, old); err != nil {
+			return err
+		}
+		fmt.Printf("previous mint state moved to '%s'\n", old)
+	}
+	if err := os.Rename(tmpDir, homeDir); err != nil {
+		return err
+	}
+	fmt.Printf("backup '%s' restored to '%s'\n", filename, homeDir)
+	return nil
+}
+
+// Restore implements the scrit-mint 'restore' command.
+func Restore(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-f] [-s seckey.bin] backup_file\n", argv0)
+		fmt.Fprintf(os.Stderr, "Restore mint state from encrypted backup.\n")
+		fmt.Fprintf(os.Stderr, "The restored identity must be a current mint in %s and its\n",
+			netconf.DefNetConfFile)
+		fmt.Fprintf(os.Stderr, "private key list must match the public key list.\n")
+		fs.PrintDefaults()
+	}
+	force := fs.Bool("f", false, "Replace existing mint state (moved to .old)")
+	secKey := fs.String("s", "", "Secret key file name in restored secrets")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	return restore(net, homedir.ScritMint(), *secKey, fs.Arg(0), *force)
+}
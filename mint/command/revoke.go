@@ -0,0 +1,142 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/def"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// parseEpochs parses a comma separated list of epoch numbers.
+func parseEpochs(s string) ([]int, error) {
+	var epochs []int
+	if s == "" {
+		return nil, nil
+	}
+	for _, e := range strings.Split(s, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(e))
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse epoch '%s': %s", e, err)
+		}
+		epochs = append(epochs, i)
+	}
+	return epochs, nil
+}
+
+func revoke(
+	homeDir, secKey string,
+	epochs []int,
+	compromiseTime time.Time,
+	recoveryPeriod time.Duration,
+	notice string,
+) error {
+	// load identity key
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	id := ik.MarshalID()
+
+	// make sure revocation is valid for our mint configuration
+	mint, err := netconf.LoadMint(filepath.Join(netconf.DefMintDir, id+".json"))
+	if err != nil {
+		return err
+	}
+	r, err := netconf.NewRevocation(ik, epochs, compromiseTime,
+		compromiseTime.Add(recoveryPeriod), notice)
+	if err != nil {
+		return err
+	}
+	if err := r.Validate(mint); err != nil {
+		return err
+	}
+
+	// append revocation to existing ones
+	if err := os.MkdirAll(netconf.DefRevocationDir, 0755); err != nil {
+		return err
+	}
+	filename := filepath.Join(netconf.DefRevocationDir, id+".json")
+	lock, err := netconf.LockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	var revs []*netconf.Revocation
+	exists, err := file.Exists(filename)
+	if err != nil {
+		return err
+	}
+	if exists {
+		revs, err = netconf.LoadRevocations(filename)
+		if err != nil {
+			return err
+		}
+	}
+	revs = append(revs, r)
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	if err := netconf.SaveRevocations(filename, revs); err != nil {
+		return err
+	}
+	fmt.Printf("revocation written to '%s'\n", filename)
+	return nil
+}
+
+// Revoke implements the scrit-mint 'revoke' command.
+func Revoke(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-s seckey.bin] notice\n", argv0)
+		fmt.Fprintf(os.Stderr, "Revoke compromised signing keys of mint immediately.\n")
+		fmt.Fprintf(os.Stderr, "The notice describes the compromise.\n")
+		fs.PrintDefaults()
+	}
+	secKey := fs.String("s", "", "Secret key file")
+	epochList := fs.String("epochs", "", "Comma separated list of epochs to revoke (default: all)")
+	compromised := fs.String("compromise-time", "", "Time of compromise (RFC3339, default: now)")
+	recoveryPeriod := fs.Duration("recovery-period", def.ValidationPeriod, "Length of recovery period")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	epochs, err := parseEpochs(*epochList)
+	if err != nil {
+		return err
+	}
+	t := time.Now()
+	if *compromised != "" {
+		t, err = time.Parse(time.RFC3339, *compromised)
+		if err != nil {
+			return err
+		}
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	return revoke(homeDir, *secKey, epochs, t, *recoveryPeriod, fs.Arg(0))
+}
@@ -0,0 +1,253 @@
+package command
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/frankbraun/codechain/keyfile"
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/base64"
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/frankbraun/codechain/util/def"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/frankbraun/codechain/util/terminal"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// saveIdentity stores the identity key sk with the given comment encrypted
+// in homeDir/def.SecretsSubDir and returns the filename.
+func saveIdentity(homeDir string, sk *[64]byte, comment []byte) (string, error) {
+	var pass []byte
+	secretsDir := filepath.Join(homeDir, def.SecretsSubDir)
+	if err := os.MkdirAll(secretsDir, 0700); err != nil {
+		return "", err
+	}
+	if seckey.TestPass == "" {
+		var err error
+		fmt.Println("passphrase for new identity key:")
+		pass, err = terminal.ReadPassphrase(syscall.Stdin, true)
+		if err != nil {
+			return "", err
+		}
+		defer bzero.Bytes(pass)
+	} else {
+		pass = []byte(seckey.TestPass)
+	}
+	pub := sk[32:]
+	var signature [64]byte
+	copy(signature[:], ed25519.Sign(sk[:], append(pub, comment...)))
+	filename := filepath.Join(secretsDir, base64.Encode(pub))
+	if err := keyfile.Create(filename, pass, *sk, signature, comment); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+func rotate(net *netconf.Network, homeDir, secKey string) error {
+	// load old identity key
+	sec, _, comment, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	oldKey := netconf.NewIdentityKeyEd25519Priv(sec)
+	oldID := oldKey.MarshalID()
+	if !net.Mints()[oldID] {
+		return fmt.Errorf("mint not part of %s: %s", netconf.DefNetConfFile, oldID)
+	}
+
+	// load private key list of old identity
+	oldPrivFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, oldID+".json")
+	mint, err := netconf.LoadPrivKeyList(oldPrivFilename, oldKey)
+	if err != nil {
+		return err
+	}
+	if err := mint.Validate(net); err != nil {
+		return err
+	}
+
+	// generate new identity key, it is only stored after all checks passed
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	var sk [64]byte
+	copy(sk[:], priv)
+	bzero.Bytes(priv)
+	defer bzero.Bytes(sk[:])
+	newKey := netconf.NewIdentityKeyEd25519Priv(&sk)
+	newID := newKey.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, newID+".json")
+	confFilename := filepath.Join(netconf.DefMintDir, newID+".json")
+	var locks []*netconf.FileLock
+	defer func() {
+		for _, lock := range locks {
+			lock.Unlock()
+		}
+	}()
+	for _, filename := range []string{privFilename, confFilename} {
+		lock, err := netconf.LockFile(filename)
+		if err != nil {
+			return err
+		}
+		locks = append(locks, lock)
+		exists, err := file.Exists(filename)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("file '%s' exists already", filename)
+		}
+	}
+
+	// sign key replacement with old identity key
+	r, err := netconf.SignKeyReplacement(newKey, oldKey)
+	if err != nil {
+		return err
+	}
+
+	// migrate private key list to new identity key (epochs which have
+	// already started keep the signatures of the old identity key)
+	if err := mint.MigrateIdentity(newKey, time.Now()); err != nil {
+		return err
+	}
+	// validate as if the key replacement was already part of the network
+	net.MintReplace(r)
+	if err := mint.Validate(net); err != nil {
+		return err
+	}
+	// make sure new key lists have not been created concurrently
+	for _, lock := range locks {
+		if err := lock.Check(); err != nil {
+			return err
+		}
+	}
+
+	// store new identity key, remove it again if the key lists cannot be saved
+	secFilename, err := saveIdentity(homeDir, &sk, comment)
+	if err != nil {
+		return err
+	}
+	if err := mint.SavePrivKeyList(privFilename, newKey); err != nil {
+		os.Remove(secFilename)
+		return err
+	}
+	// prune private keys
+	mint.PrunePrivKeys()
+	// save public configuration file
+	if err := mint.Save(confFilename, 0644); err != nil {
+		os.Remove(privFilename)
+		os.Remove(secFilename)
+		return err
+	}
+
+	// the old identity key and its private key list are kept, they are
+	// needed until the replacement takes effect (see finishRotation)
+	fmt.Println("secret key file created:")
+	fmt.Println(secFilename)
+	fmt.Println("use -s to select the old identity key until the replacement takes effect,")
+	fmt.Println("then remove it with 'scrit-mint rotate -finish'")
+	fmt.Println("replace mint identity with:")
+	fmt.Printf("scrit-gov mint replace %s %s %s\n", newID, oldID, r.Signature)
+	fmt.Println("new mint identity and key replacement signature:")
+	fmt.Println(newID)
+	fmt.Println(r.Signature) // this must be the last output line!
+	return nil
+}
+
+// finishRotation removes the secret key files and private key lists of all
+// former identities of the mint identity key in secKey. The replacement must
+// have taken effect at time t.
+func finishRotation(net *netconf.Network, homeDir, secKey string, t time.Time) error {
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	id := ik.MarshalID()
+	mints, err := net.MintsAt(t)
+	if err != nil {
+		return err
+	}
+	if !mints[id] {
+		return fmt.Errorf("identity replacement has not taken effect yet: %s", id)
+	}
+	for oldID := range net.FormerIdentities(id) {
+		if mints[oldID] {
+			continue // should not happen
+		}
+		oldKey, err := netconf.ParseIdentityKey(oldID)
+		if err != nil {
+			return err
+		}
+		for _, filename := range []string{
+			filepath.Join(homeDir, def.SecretsSubDir, base64.Encode(oldKey.PubKey)),
+			filepath.Join(homeDir, netconf.DefPrivKeyListDir, oldID+".json"),
+		} {
+			err := os.Remove(filename)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("file of former identity removed: %s\n", filename)
+		}
+	}
+	return nil
+}
+
+// Rotate implements the scrit-mint 'rotate' command.
+func Rotate(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-s seckey.bin] [-finish]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Replace mint identity key with a newly generated one.\n")
+		fmt.Fprintf(os.Stderr, "Migrates the private key list to the new identity alongside the old one,\n")
+		fmt.Fprintf(os.Stderr, "and prints the signature required for 'scrit-gov mint replace'.\n")
+		fmt.Fprintf(os.Stderr, "Epochs which have already started keep the signatures of the old identity.\n")
+		fmt.Fprintf(os.Stderr, "With -finish the old identity key and its private key list are removed,\n")
+		fmt.Fprintf(os.Stderr, "after the replacement has taken effect.\n")
+		fs.PrintDefaults()
+	}
+	finish := fs.Bool("finish", false, "Remove former identities of the new identity key (-s)")
+	secKey := fs.String("s", "", "Secret key file of old identity (new identity with -finish)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	if *finish {
+		return finishRotation(net, homeDir, *secKey, time.Now())
+	}
+	return rotate(net, homeDir, *secKey)
+}
@@ -0,0 +1,141 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/signer"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// pruneSigner erases expired private keys from signer s.
+func pruneSigner(s *signer.File) {
+	n, err := s.Prune(time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot prune expired private keys: %s\n", err)
+		return
+	}
+	if n > 0 {
+		fmt.Printf("pruned %d expired private keys\n", n)
+	}
+}
+
+// dialSigner connects to the signer server listening on socket. If no signer
+// server has been started (the socket doesn't exist), nil is returned.
+func dialSigner(socket string) (*signer.Client, error) {
+	if _, err := os.Lstat(socket); os.IsNotExist(err) {
+		return nil, nil
+	}
+	c, err := signer.Dial(socket)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to signer (remove '%s', if it is not running anymore): %w",
+			socket, err)
+	}
+	log.Printf("sending signing requests to signer on '%s'", socket)
+	return c, nil
+}
+
+// identitySigner returns a signer for the private identity key sec which
+// sends all other signing requests to the signer client c, if c is not nil.
+func identitySigner(sec *[64]byte, c *signer.Client) (*netconf.IdentityKey, netconf.Signer) {
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	s := netconf.NewKeySigner()
+	s.AddIdentityKey(ik)
+	if c == nil {
+		return ik, s
+	}
+	return ik, signer.Chain{s, c}
+}
+
+func serveSigner(
+	homeDir, secKey, socket, audit string,
+	pruneInterval time.Duration,
+) error {
+	s, err := signer.LoadFile(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	// prune expired private keys automatically
+	if pruneInterval > 0 {
+		pruneSigner(s)
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		go func() {
+			for range ticker.C {
+				pruneSigner(s)
+			}
+		}()
+	}
+	a, err := os.OpenFile(audit, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	l, err := signer.Listen(socket)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	// remove socket on interrupt
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		l.Close()
+	}()
+	fmt.Printf("serving DBC signing keys on '%s'\n", socket)
+	if err := signer.NewServer(s, s, a).Serve(l); err != nil {
+		select {
+		case <-c:
+			return nil // interrupted
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// Signer implements the scrit-mint 'signer' command.
+func Signer(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-s seckey.bin]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Serve signing requests for DBC signing keys on Unix socket.\n")
+		fs.PrintDefaults()
+	}
+	homeDir := homedir.ScritMint()
+	secKey := fs.String("s", "", "Secret key file")
+	socket := fs.String("socket", filepath.Join(homeDir, signer.DefSocketFile),
+		"Unix socket to listen on")
+	audit := fs.String("audit", filepath.Join(homeDir, signer.DefAuditFile),
+		"Audit log file")
+	pruneInterval := fs.Duration("prune-interval", time.Hour,
+		"Interval to prune expired private keys (0 disables pruning)")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	return serveSigner(homeDir, *secKey, *socket, *audit, *pruneInterval)
+}
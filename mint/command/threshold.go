@@ -0,0 +1,53 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/scritcash/scrit/mint/threshold/command"
+)
+
+func usageThreshold(cmd string) error {
+	fmt.Fprintf(os.Stderr, "Usage: %s dkg-start -id id -t threshold -n participants\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s dkg-share\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s dkg-finish\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s boxkey\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s payloads [request]\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s commit\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s sign -m message | -f file\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s aggregate -m message | -f file\n", cmd)
+	fmt.Fprintf(os.Stderr, "       %s inject [-r request] signature [...]\n", cmd)
+	return flag.ErrHelp
+}
+
+// Threshold implements the scrit-mint 'threshold' command.
+func Threshold(argv0 string, args ...string) error {
+	if len(args) < 1 {
+		return usageThreshold(argv0)
+	}
+	newArgv0 := argv0 + " " + args[0]
+	newArgs := args[1:]
+	switch args[0] {
+	case "dkg-start":
+		return command.DKGStart(newArgv0, newArgs...)
+	case "dkg-share":
+		return command.DKGShare(newArgv0, newArgs...)
+	case "dkg-finish":
+		return command.DKGFinish(newArgv0, newArgs...)
+	case "boxkey":
+		return command.BoxKey(newArgv0, newArgs...)
+	case "payloads":
+		return command.Payloads(newArgv0, newArgs...)
+	case "commit":
+		return command.Commit(newArgv0, newArgs...)
+	case "sign":
+		return command.Sign(newArgv0, newArgs...)
+	case "aggregate":
+		return command.Aggregate(newArgv0, newArgs...)
+	case "inject":
+		return command.Inject(newArgv0, newArgs...)
+	default:
+		return usageThreshold(argv0)
+	}
+}
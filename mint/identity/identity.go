@@ -14,18 +14,26 @@ import (
 // If secKey is empty a secret key from homeDir/def.SecretsSubdir is loaded
 // only if it contains exactly one secret.
 func Load(homeDir, secKey string) (*[64]byte, *[64]byte, []byte, error) {
-	if secKey == "" {
-		secretDir := filepath.Join(homeDir, def.SecretsSubDir)
-		files, err := ioutil.ReadDir(secretDir)
-		if err != nil {
-			return nil, nil, nil, err
-		}
-		if len(files) > 1 {
-			return nil, nil, nil,
-				fmt.Errorf("directory '%s' contains more than one secret file, use option -s",
-					secretDir)
-		}
-		secKey = filepath.Join(secretDir, files[0].Name())
+	filename, err := Filename(homeDir, secKey)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	return seckey.Read(secKey)
+	return seckey.Read(filename)
+}
+
+// Filename returns the filename of the secret key loaded by Load.
+func Filename(homeDir, secKey string) (string, error) {
+	if secKey != "" {
+		return secKey, nil
+	}
+	secretDir := filepath.Join(homeDir, def.SecretsSubDir)
+	files, err := ioutil.ReadDir(secretDir)
+	if err != nil {
+		return "", err
+	}
+	if len(files) > 1 {
+		return "", fmt.Errorf("directory '%s' contains more than one secret file, use option -s",
+			secretDir)
+	}
+	return filepath.Join(secretDir, files[0].Name()), nil
 }
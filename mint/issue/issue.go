@@ -0,0 +1,268 @@
+// Package issue implements the issuance of DBCs against deposits of external
+// funds.
+//
+// An operator authorises an issuance order by signing it. Every mint approves
+// the order on its own: it verifies the operator signature, has the deposit
+// confirmed by a payment rail, signs the blinded outputs with its signing
+// keys of the current epoch, and records the created DBCs in its issuance
+// ledger (see netconf.Ledger). The signatures are returned to the operator as
+// an Issuance.
+package issue
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// DefOperatorsFile defines the default name of the file which lists the
+// identity keys of the authorised operators (one per line) in the mint home
+// directory.
+const DefOperatorsFile = "operators"
+
+// DefIssuanceFile defines the default name of the file approved issuances are
+// written to.
+const DefIssuanceFile = "issuance.json"
+
+// ErrOrder is returned if an issuance order is invalid.
+var ErrOrder = errors.New("issue: invalid order")
+
+// ErrOrderSignature is returned if the operator signature of an issuance
+// order does not verify.
+var ErrOrderSignature = errors.New("issue: order signature does not verify")
+
+// ErrOperator is returned if an issuance order is signed by an operator who
+// is not authorised.
+var ErrOperator = errors.New("issue: operator not authorised")
+
+// ErrDeposit is returned if the deposit of an issuance order has not been
+// confirmed by the payment rail (or doesn't match the order).
+var ErrDeposit = errors.New("issue: deposit not confirmed")
+
+// ErrIssued is returned if an issuance order has been approved already.
+var ErrIssued = errors.New("issue: order issued already")
+
+// LoadOperators loads the identity keys of the authorised operators from
+// filename, one per line. Empty lines and lines starting with '#' are
+// ignored. It returns the set of their IDs.
+func LoadOperators(filename string) (map[string]bool, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	operators := make(map[string]bool)
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ik, err := netconf.ParseIdentityKey(line)
+		if err != nil {
+			return nil, fmt.Errorf("issue: '%s': %w", filename, err)
+		}
+		operators[ik.MarshalID()] = true
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return operators, nil
+}
+
+// OutputSignature is the signature of a blinded output.
+type OutputSignature struct {
+	PubKey    []byte // public signing key
+	Signature []byte // signature of blinded output
+}
+
+// Issuance is the approval of an issuance order by a mint.
+type Issuance struct {
+	OrderID         string              // ID of issuance order
+	MintIdentityKey netconf.IdentityKey // identity key of approving mint
+	Epoch           int                 // signing epoch
+	Time            time.Time           // signing time
+	Signatures      []OutputSignature   // one per order output (same order)
+}
+
+// LoadIssuance loads an issuance from filename.
+func LoadIssuance(filename string) (*Issuance, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var iss Issuance
+	if err := json.Unmarshal(data, &iss); err != nil {
+		return nil, err
+	}
+	return &iss, nil
+}
+
+// Save the issuance to filename.
+func (iss *Issuance) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(iss)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
+
+// Mint approves issuance orders for a mint of a federation.
+type Mint struct {
+	Federation  *netconf.Federation  // the federation of the mint
+	IdentityKey *netconf.IdentityKey // identity key of the mint
+	Signer      netconf.Signer       // knows private identity and signing keys
+	Operators   map[string]bool      // IDs of authorised operators
+	Rail        PaymentRail          // confirms deposits
+}
+
+// recorded returns true, if ledger l contains an entry for the deposit of
+// order o, regardless of the DBC types the deposit was split into.
+func recorded(l *netconf.Ledger, o *Order) bool {
+	prefix := o.ID + "/"
+	for _, e := range l.Entries {
+		if strings.HasPrefix(e.ID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// issued returns true, if the mint recorded order o in one of its issuance
+// ledgers loaded with the federation.
+func (m *Mint) issued(o *Order) bool {
+	id := m.IdentityKey.MarshalID()
+	for _, ledgers := range m.Federation.Created {
+		for _, l := range ledgers {
+			if l.MintIdentityKey.MarshalID() != id {
+				continue
+			}
+			if recorded(l, o) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Approve approves issuance order o at time t. The outputs are signed and the
+// created DBCs are recorded in the issuance ledger of the mint in the
+// federation directory dir.
+func (m *Mint) Approve(dir string, o *Order, t time.Time) (*Issuance, error) {
+	// check authorisation
+	if !m.Operators[o.OperatorKey.MarshalID()] {
+		return nil, fmt.Errorf("%w: %s", ErrOperator, o.OperatorKey.MarshalID())
+	}
+	if err := o.Verify(); err != nil {
+		return nil, err
+	}
+	return m.create(dir, o, t, m.Rail)
+}
+
+// Create creates the DBCs of order o at time t like Approve, but without
+// checking the operator signature and the deposit. It is used for reissues,
+// where the order is backed by destroyed DBCs instead of a deposit (see
+// package reissue).
+func (m *Mint) Create(dir string, o *Order, t time.Time) (*Issuance, error) {
+	return m.create(dir, o, t, nil)
+}
+
+// create creates the DBCs of order o at time t, the deposit is confirmed by
+// rail (if not nil).
+func (m *Mint) create(dir string, o *Order, t time.Time, rail PaymentRail) (*Issuance, error) {
+	// check mint
+	net := m.Federation.Network
+	epoch, err := net.SigningEpochAt(t)
+	if err != nil {
+		return nil, err
+	}
+	id := m.IdentityKey.MarshalID()
+	mints, err := net.MintsAt(t)
+	if err != nil {
+		return nil, err
+	}
+	mint, ok := m.Federation.Mints[id]
+	if !mints[id] || !ok {
+		return nil, fmt.Errorf("%w: %s", netconf.ErrLedgerMint, id)
+	}
+	if m.Federation.Revoked(id, epoch, t) {
+		return nil, fmt.Errorf("%w: %s", netconf.ErrRevoked, id)
+	}
+	if epoch >= len(mint.MintEpochs) {
+		return nil, fmt.Errorf("%w: %s", netconf.ErrMintEpochsMissing, id)
+	}
+	// check order
+	if err := o.Validate(net.DBCTypesAt(epoch)); err != nil {
+		return nil, err
+	}
+	if m.issued(o) {
+		return nil, fmt.Errorf("%w: %s", ErrIssued, o.ID)
+	}
+	if rail != nil {
+		deposit, err := rail.Deposit(o.ID)
+		if err != nil {
+			return nil, err
+		}
+		if deposit.Currency != o.Currency || deposit.Amount != o.Amount {
+			return nil, fmt.Errorf("%w: deposit %s is %s, order is %s", ErrDeposit, o.ID,
+				netconf.DBCType{Currency: deposit.Currency, Amount: deposit.Amount},
+				netconf.DBCType{Currency: o.Currency, Amount: o.Amount})
+		}
+	}
+	// sign outputs and record them in ledger
+	iss := &Issuance{
+		OrderID:         o.ID,
+		MintIdentityKey: mint.MintIdentityKey,
+		Epoch:           epoch,
+		Time:            t,
+	}
+	me := mint.MintEpochs[epoch]
+	counts := o.Counts()
+	err = netconf.RecordLedger(dir, m.IdentityKey, m.Signer, netconf.LedgerCreate, epoch,
+		func(l *netconf.Ledger, _ []*netconf.Ledger) error {
+			if recorded(l, o) {
+				return fmt.Errorf("%w: %s", ErrIssued, o.ID)
+			}
+			for _, out := range o.Outputs {
+				k := me.SigningKeyAt(out.DBCType, t)
+				if k == nil {
+					return fmt.Errorf("%w: %v", netconf.ErrPrivKeyMissing, out.DBCType)
+				}
+				sig, err := m.Signer.Sign(k.PubKey, out.Blinded)
+				if err != nil {
+					return err
+				}
+				if !ed25519.Verify(k.PubKey, out.Blinded, sig) {
+					return netconf.ErrKeySignature
+				}
+				iss.Signatures = append(iss.Signatures, OutputSignature{
+					PubKey:    k.PubKey,
+					Signature: sig,
+				})
+			}
+			for _, dbcType := range netconf.DBCTypeCountsToSortedArray(counts) {
+				err := l.Add(netconf.LedgerEntry{
+					ID:      o.EntryID(dbcType),
+					DBCType: dbcType,
+					Count:   counts[dbcType],
+					Time:    t,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return iss, nil
+}
@@ -0,0 +1,249 @@
+package issue
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/netconf/netconftest"
+)
+
+var (
+	oneEUR = netconf.DBCType{Currency: "EUR", Amount: 100000000}
+	twoEUR = netconf.DBCType{Currency: "EUR", Amount: 200000000}
+)
+
+// testMint returns a mint of a 2-of-3 federation with the DBC types oneEUR
+// and twoEUR which accepts orders from operator op and deposits from the
+// returned file. It also returns a time in the first signing epoch.
+func testMint(t *testing.T, op *netconf.IdentityKey) (*Mint, string, time.Time) {
+	f := netconftest.NewFederation(t, oneEUR, twoEUR)
+	deposits := filepath.Join(t.TempDir(), "deposits.json")
+	err := ioutil.WriteFile(deposits, []byte(`[
+  {"ID": "dep1", "Currency": "EUR", "Amount": 400000000},
+  {"ID": "dep2", "Currency": "EUR", "Amount": 100000000}
+]`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &Mint{
+		Federation:  f.Federation,
+		IdentityKey: f.IdentityKeys[0],
+		Signer:      f.Signers[0],
+		Operators:   map[string]bool{op.MarshalID(): true},
+		Rail:        &FileRail{Filename: deposits},
+	}
+	return m, deposits, f.At
+}
+
+func testOrder(t *testing.T, op *netconf.IdentityKey, id string, amount uint64) *Order {
+	s := netconf.NewKeySigner()
+	s.AddIdentityKey(op)
+	outputs := []Output{
+		{DBCType: twoEUR, Blinded: []byte("blinded 1")},
+		{DBCType: oneEUR, Blinded: []byte("blinded 2")},
+		{DBCType: oneEUR, Blinded: []byte("blinded 3")},
+	}
+	o, err := NewOrder(op, s, id, "EUR", amount, outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+func TestApprove(t *testing.T) {
+	op, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _, at := testMint(t, op)
+	dir := t.TempDir()
+	o := testOrder(t, op, "dep1", 400000000)
+	filename := filepath.Join(dir, "order.json")
+	if err := o.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	o, err = LoadOrder(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iss, err := m.Approve(dir, o, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(iss.Signatures) != len(o.Outputs) {
+		t.Fatalf("%d signatures for %d outputs", len(iss.Signatures), len(o.Outputs))
+	}
+	for i, sig := range iss.Signatures {
+		if !ed25519.Verify(sig.PubKey, o.Outputs[i].Blinded, sig.Signature) {
+			t.Errorf("signature of output %d does not verify", i)
+		}
+	}
+
+	// check ledger
+	ledgers, err := netconf.LoadLedgers(filepath.Join(dir,
+		netconf.LedgerFilename(0, netconf.LedgerCreate)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgers) != 1 {
+		t.Fatalf("%d ledgers instead of 1", len(ledgers))
+	}
+	if err := ledgers[0].Validate(m.Federation.Network); err != nil {
+		t.Error(err)
+	}
+	e := ledgers[0].Entry(o.EntryID(oneEUR))
+	if e == nil || e.Count != 2 {
+		t.Errorf("wrong ledger entry: %+v", e)
+	}
+
+	// save and load issuance
+	filename = filepath.Join(dir, DefIssuanceFile)
+	if err := iss.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	iss, err = LoadIssuance(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assemble DBCs
+	dbcs, err := o.DBCs(iss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dbcs) != len(o.Outputs) {
+		t.Fatalf("%d DBCs for %d outputs", len(dbcs), len(o.Outputs))
+	}
+	// a single mint doesn't reach the quorum
+	if _, err := dbcs[0].Verify(m.Federation, at); !errors.Is(err, dbc.ErrQuorum) {
+		t.Errorf("Verify() should fail with dbc.ErrQuorum, got: %v", err)
+	}
+
+	// orders are only approved once
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrIssued) {
+		t.Errorf("Approve() should fail with ErrIssued, got: %v", err)
+	}
+}
+
+func TestApproveResplit(t *testing.T) {
+	op, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _, at := testMint(t, op)
+	dir := t.TempDir()
+	if _, err := m.Approve(dir, testOrder(t, op, "dep1", 400000000), at); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same deposit split into other DBC types is not issued again
+	s := netconf.NewKeySigner()
+	s.AddIdentityKey(op)
+	var outputs []Output
+	for i := 0; i < 4; i++ {
+		outputs = append(outputs, Output{
+			DBCType: oneEUR,
+			Blinded: []byte(fmt.Sprintf("resplit %d", i)),
+		})
+	}
+	o, err := NewOrder(op, s, "dep1", "EUR", 400000000, outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrIssued) {
+		t.Errorf("Approve() should fail with ErrIssued, got: %v", err)
+	}
+
+	// also not if the issuance ledger has been loaded with the federation
+	ledgers, err := netconf.LoadLedgers(filepath.Join(dir,
+		netconf.LedgerFilename(0, netconf.LedgerCreate)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Federation.Created = map[int][]*netconf.Ledger{0: ledgers}
+	if _, err := m.Approve(t.TempDir(), o, at); !errors.Is(err, ErrIssued) {
+		t.Errorf("Approve() should fail with ErrIssued, got: %v", err)
+	}
+}
+
+func TestApproveInvalid(t *testing.T) {
+	op, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _, at := testMint(t, op)
+	dir := t.TempDir()
+
+	// unauthorised operator
+	other, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := testOrder(t, other, "dep1", 400000000)
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrOperator) {
+		t.Errorf("Approve() should fail with ErrOperator, got: %v", err)
+	}
+
+	// modified order
+	o = testOrder(t, op, "dep1", 400000000)
+	o.Outputs[0].Blinded = []byte("modified")
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrOrderSignature) {
+		t.Errorf("Approve() should fail with ErrOrderSignature, got: %v", err)
+	}
+
+	// outputs do not sum up to amount
+	o = testOrder(t, op, "dep1", 500000000)
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrOrder) {
+		t.Errorf("Approve() should fail with ErrOrder, got: %v", err)
+	}
+
+	// deposit amount doesn't match
+	o = testOrder(t, op, "dep2", 400000000)
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrDeposit) {
+		t.Errorf("Approve() should fail with ErrDeposit, got: %v", err)
+	}
+
+	// unknown deposit
+	o = testOrder(t, op, "dep3", 400000000)
+	if _, err := m.Approve(dir, o, at); !errors.Is(err, ErrDeposit) {
+		t.Errorf("Approve() should fail with ErrDeposit, got: %v", err)
+	}
+
+	// no signing epoch
+	o = testOrder(t, op, "dep1", 400000000)
+	if _, err := m.Approve(dir, o, at.Add(-48*time.Hour)); err == nil {
+		t.Error("Approve() should fail before the first signing epoch")
+	}
+}
+
+func TestLoadOperators(t *testing.T) {
+	op, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), DefOperatorsFile)
+	data := "# operators\n\n" + op.MarshalID() + "\n"
+	if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	operators, err := LoadOperators(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(operators) != 1 || !operators[op.MarshalID()] {
+		t.Errorf("wrong operators: %v", operators)
+	}
+	if err := ioutil.WriteFile(filename, []byte("invalid\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOperators(filename); err == nil {
+		t.Error("LoadOperators() should fail")
+	}
+}
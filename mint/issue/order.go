@@ -0,0 +1,217 @@
+package issue
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/bits"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// Output is a DBC to be created for the beneficiary of an issuance order.
+type Output struct {
+	DBCType netconf.DBCType // type of the DBC
+	Blinded []byte          // blinded DBC, signed as is by the mints
+}
+
+// Order is an issuance order: the creation of DBCs (Outputs) with a total
+// value of Amount against a deposit of external funds with reference ID.
+// Orders are authorised by the signature of an operator.
+type Order struct {
+	ID          string              // deposit reference
+	Currency    string              // currency of deposit
+	Amount      uint64              // amount of deposit, last 8 digits are decimal places
+	Outputs     []Output            // DBCs to create
+	OperatorKey netconf.IdentityKey // identity key of authorising operator
+	Signature   string              // of all fields above by OperatorKey
+}
+
+// NewOrder returns a new issuance order of operator op for the deposit with
+// reference id. The order is signed with s, which must know the private key
+// of op.
+func NewOrder(
+	op *netconf.IdentityKey,
+	s netconf.Signer,
+	id, currency string,
+	amount uint64,
+	outputs []Output,
+) (*Order, error) {
+	o := &Order{
+		ID:          id,
+		Currency:    currency,
+		Amount:      amount,
+		Outputs:     outputs,
+		OperatorKey: netconf.IdentityKey{SigAlgo: op.SigAlgo, PubKey: op.PubKey},
+	}
+	enc, err := o.encode()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.Sign(op.PubKey, enc)
+	if err != nil {
+		return nil, err
+	}
+	o.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	if err := o.Verify(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// encode order (without signature).
+func (o *Order) encode() ([]byte, error) {
+	encodingScheme := []interface{}{
+		[]byte(o.OperatorKey.SigAlgo),
+		o.OperatorKey.PubKey,
+		[]byte(o.ID),
+		[]byte(o.Currency),
+		int64(o.Amount),
+		int64(len(o.Outputs)),
+	}
+	for _, out := range o.Outputs {
+		encodingScheme = append(encodingScheme,
+			[]byte(out.DBCType.Currency),
+			int64(out.DBCType.Amount),
+			out.Blinded,
+		)
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	return binencode.Encode(buf, encodingScheme...)
+}
+
+// Verify the operator signature of the order.
+func (o *Order) Verify() error {
+	sig, err := base64.RawURLEncoding.DecodeString(o.Signature)
+	if err != nil {
+		return err
+	}
+	enc, err := o.encode()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(o.OperatorKey.PubKey, enc, sig) {
+		return ErrOrderSignature
+	}
+	return nil
+}
+
+// Validate the order against the DBC types dbcTypes: the outputs must be of
+// the given DBC types and sum up to the amount of the order.
+func (o *Order) Validate(dbcTypes map[netconf.DBCType]bool) error {
+	if o.ID == "" {
+		return fmt.Errorf("%w: ID missing", ErrOrder)
+	}
+	if len(o.Outputs) == 0 {
+		return fmt.Errorf("%w: no outputs", ErrOrder)
+	}
+	var sum uint64
+	for _, out := range o.Outputs {
+		if out.DBCType.Currency != o.Currency {
+			return fmt.Errorf("%w: output currency %s differs from order currency %s",
+				ErrOrder, out.DBCType.Currency, o.Currency)
+		}
+		if !dbcTypes[out.DBCType] {
+			return fmt.Errorf("%w: %v", netconf.ErrDBCTypeNotDefined, out.DBCType)
+		}
+		if len(out.Blinded) == 0 {
+			return fmt.Errorf("%w: empty output", ErrOrder)
+		}
+		var carry uint64
+		sum, carry = bits.Add64(sum, out.DBCType.Amount, 0)
+		if carry != 0 {
+			return fmt.Errorf("%w: outputs overflow", ErrOrder)
+		}
+	}
+	if sum != o.Amount {
+		return fmt.Errorf("%w: outputs sum up to %s instead of %s", ErrOrder,
+			netconf.DBCType{Currency: o.Currency, Amount: sum},
+			netconf.DBCType{Currency: o.Currency, Amount: o.Amount})
+	}
+	return nil
+}
+
+// Counts returns the number of outputs per DBC type.
+func (o *Order) Counts() map[netconf.DBCType]uint64 {
+	counts := make(map[netconf.DBCType]uint64)
+	for _, out := range o.Outputs {
+		counts[out.DBCType]++
+	}
+	return counts
+}
+
+// EntryID returns the ID of the ledger entry for the outputs of the given DBC
+// type of order o. All entries of an order share the prefix o.ID + "/".
+func (o *Order) EntryID(dbcType netconf.DBCType) string {
+	return o.ID + "/" + dbcType.String()
+}
+
+// DBCs returns the DBCs created by order o, signed by the mints which approved
+// the order with the given issuances (one DBC per output, same order). The
+// issuances must be from the same signing epoch.
+func (o *Order) DBCs(issuances ...*Issuance) ([]*dbc.DBC, error) {
+	if len(issuances) == 0 {
+		return nil, fmt.Errorf("%w: no issuances", ErrOrder)
+	}
+	dbcs := make([]*dbc.DBC, len(o.Outputs))
+	for i, out := range o.Outputs {
+		dbcs[i] = &dbc.DBC{
+			DBCType: out.DBCType,
+			Epoch:   issuances[0].Epoch,
+			Message: out.Blinded,
+		}
+	}
+	for _, iss := range issuances {
+		if iss.OrderID != o.ID {
+			return nil, fmt.Errorf("%w: issuance of order %s", ErrOrder, iss.OrderID)
+		}
+		if iss.Epoch != issuances[0].Epoch {
+			return nil, fmt.Errorf("%w: issuances from epochs %d and %d", ErrOrder,
+				issuances[0].Epoch, iss.Epoch)
+		}
+		if len(iss.Signatures) != len(o.Outputs) {
+			return nil, fmt.Errorf("%w: %d signatures for %d outputs", ErrOrder,
+				len(iss.Signatures), len(o.Outputs))
+		}
+		for i, sig := range iss.Signatures {
+			dbcs[i].Signatures = append(dbcs[i].Signatures, dbc.Signature{
+				MintID:    iss.MintIdentityKey.MarshalID(),
+				PubKey:    sig.PubKey,
+				Signature: sig.Signature,
+				Time:      iss.Time,
+			})
+		}
+	}
+	return dbcs, nil
+}
+
+// LoadOrder loads an issuance order from filename.
+func LoadOrder(filename string) (*Order, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var o Order
+	if err := json.Unmarshal(data, &o); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Save the order to filename.
+func (o *Order) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(o)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
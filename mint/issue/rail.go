@@ -0,0 +1,45 @@
+package issue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Deposit is a deposit of external funds confirmed by a payment rail.
+type Deposit struct {
+	ID       string // deposit reference
+	Currency string // currency of deposit
+	Amount   uint64 // amount of deposit, last 8 digits are decimal places
+}
+
+// PaymentRail confirms deposits of external funds.
+type PaymentRail interface {
+	// Deposit returns the confirmed deposit with reference id. If no such
+	// deposit has been confirmed, an error wrapping ErrDeposit is returned.
+	Deposit(id string) (*Deposit, error)
+}
+
+// FileRail is a local stand-in for a payment rail which reads the confirmed
+// deposits from a JSON file (a list of Deposit objects).
+type FileRail struct {
+	Filename string
+}
+
+// Deposit implements PaymentRail.
+func (r *FileRail) Deposit(id string) (*Deposit, error) {
+	data, err := ioutil.ReadFile(r.Filename)
+	if err != nil {
+		return nil, err
+	}
+	var deposits []*Deposit
+	if err := json.Unmarshal(data, &deposits); err != nil {
+		return nil, err
+	}
+	for _, d := range deposits {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDeposit, id)
+}
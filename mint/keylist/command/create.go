@@ -40,6 +40,13 @@ func create(
 	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
 	confFilename := filepath.Join(netconf.DefMintDir, id+".json")
 
+	// lock key lists
+	locks, err := lockKeyLists(privFilename, confFilename)
+	if err != nil {
+		return err
+	}
+	defer locks.Unlock()
+
 	// make sure these files do not exist already
 	exists, err := file.Exists(privFilename)
 	if err != nil {
@@ -61,14 +68,18 @@ func create(
 	if err != nil {
 		return err
 	}
+	// make sure key lists have not been modified concurrently
+	if err := locks.Check(); err != nil {
+		return err
+	}
 	// save private key list
-	if err := mint.Save(privFilename, 0700); err != nil {
+	if err := mint.SavePrivKeyList(privFilename, ik); err != nil {
 		return err
 	}
 	// prune private keys
 	mint.PrunePrivKeys()
 	// save public configuration file
-	return mint.Save(confFilename, 0755)
+	return mint.Save(confFilename, 0644)
 }
 
 // Create implements the scrit-mint 'keylist create' command.
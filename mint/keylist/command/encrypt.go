@@ -0,0 +1,87 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func encrypt(homeDir, secKey string) error {
+	// load identity key
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+
+	// lock private key list
+	lock, err := netconf.LockFile(privFilename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	encrypted, err := netconf.IsEncryptedKeyList(privFilename)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		fmt.Printf("private key list '%s' is already encrypted\n", privFilename)
+		return nil
+	}
+
+	// migrate plaintext private key list
+	mint, err := netconf.LoadPrivKeyList(privFilename, ik)
+	if err != nil {
+		return err
+	}
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	if err := mint.SavePrivKeyList(privFilename, ik); err != nil {
+		return err
+	}
+	fmt.Printf("private key list '%s' encrypted\n", privFilename)
+	return nil
+}
+
+// Encrypt implements the scrit-mint 'keylist encrypt' command.
+func Encrypt(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Encrypt plaintext private key list (migration).\n")
+		fs.PrintDefaults()
+	}
+	secKey := fs.String("s", "", "Secret key file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	return encrypt(homeDir, *secKey)
+}
@@ -26,8 +26,15 @@ func extend(net *netconf.Network, homeDir, secKey string) error {
 	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
 	confFilename := filepath.Join(netconf.DefMintDir, id+".json")
 
+	// lock key lists
+	locks, err := lockKeyLists(privFilename, confFilename)
+	if err != nil {
+		return err
+	}
+	defer locks.Unlock()
+
 	// make sure these files exist already and are valid
-	mint, err := netconf.LoadMint(privFilename)
+	mint, err := netconf.LoadPrivKeyList(privFilename, ik)
 	if err != nil {
 		return err
 	}
@@ -40,14 +47,18 @@ func extend(net *netconf.Network, homeDir, secKey string) error {
 		return err
 	}
 
+	// make sure key lists have not been modified concurrently
+	if err := locks.Check(); err != nil {
+		return err
+	}
 	// save private key list
-	if err := mint.Save(privFilename, 0700); err != nil {
+	if err := mint.SavePrivKeyList(privFilename, ik); err != nil {
 		return err
 	}
 	// prune private keys
 	mint.PrunePrivKeys()
 	// save public configuration file
-	return mint.Save(confFilename, 0755)
+	return mint.Save(confFilename, 0644)
 }
 
 // Extend implements the scrit-mint 'keylist extend' command.
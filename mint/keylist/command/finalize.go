@@ -0,0 +1,107 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func finalize(net *netconf.Network, homeDir, respFile string) error {
+	resp, err := netconf.LoadKeyListResponse(respFile)
+	if err != nil {
+		return err
+	}
+	ik := &resp.MintIdentityKey
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+	confFilename := filepath.Join(netconf.DefMintDir, id+".json")
+
+	// make sure the 'mints' directory exists
+	if err := os.MkdirAll(netconf.DefMintDir, 0755); err != nil {
+		return err
+	}
+	// lock key lists
+	locks, err := lockKeyLists(privFilename, confFilename)
+	if err != nil {
+		return err
+	}
+	defer locks.Unlock()
+
+	// add identity signatures to prepared key list, the private keys stay
+	// encrypted
+	e, err := netconf.LoadEncryptedKeyList(privFilename)
+	if err != nil {
+		return err
+	}
+	if e.MintIdentityKey != id {
+		return netconf.ErrKeyListIdentity
+	}
+	mint := e.KeyList
+	if err := mint.Finalize(resp); err != nil {
+		return err
+	}
+	if err := mint.Validate(net); err != nil {
+		return err
+	}
+	if err := e.Add(mint); err != nil {
+		return err
+	}
+
+	// make sure key lists have not been modified concurrently
+	if err := locks.Check(); err != nil {
+		return err
+	}
+	// save private key list
+	if err := e.Save(privFilename); err != nil {
+		return err
+	}
+	// save public configuration file
+	if err := mint.Save(confFilename, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("key list '%s' finalized\n", confFilename)
+	return nil
+}
+
+// Finalize implements the scrit-mint 'keylist finalize' command.
+func Finalize(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [response]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Add identity signatures from key list response to prepared key list.\n")
+		fs.PrintDefaults()
+	}
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	respFile := DefKeyListResponseFile
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		respFile = fs.Arg(0)
+	default:
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	return finalize(net, homedir.ScritMint(), respFile)
+}
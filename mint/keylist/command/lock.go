@@ -0,0 +1,42 @@
+package command
+
+import (
+	"github.com/scritcash/scrit/netconf"
+)
+
+// keyListLocks locks the private and the public key list of a mint for
+// editing. The directories of both files must exist.
+type keyListLocks struct {
+	priv *netconf.FileLock
+	conf *netconf.FileLock
+}
+
+// lockKeyLists locks the private key list privFilename and the public key
+// list confFilename (always in that order to avoid deadlocks).
+func lockKeyLists(privFilename, confFilename string) (*keyListLocks, error) {
+	priv, err := netconf.LockFile(privFilename)
+	if err != nil {
+		return nil, err
+	}
+	conf, err := netconf.LockFile(confFilename)
+	if err != nil {
+		priv.Unlock()
+		return nil, err
+	}
+	return &keyListLocks{priv: priv, conf: conf}, nil
+}
+
+// Check makes sure that neither key list has been modified since it was
+// locked. Call it before saving the first key list.
+func (l *keyListLocks) Check() error {
+	if err := l.priv.Check(); err != nil {
+		return err
+	}
+	return l.conf.Check()
+}
+
+// Unlock releases both locks.
+func (l *keyListLocks) Unlock() {
+	l.conf.Unlock()
+	l.priv.Unlock()
+}
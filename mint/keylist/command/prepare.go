@@ -0,0 +1,180 @@
+package command
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// DefKeyListRequestFile defines the default file for key list requests.
+const DefKeyListRequestFile = "keylist-request.json"
+
+// DefKeyListResponseFile defines the default file for key list responses.
+const DefKeyListResponseFile = "keylist-response.json"
+
+// writeNewJSON writes v as JSON to filename, which must not exist.
+func writeNewJSON(filename string, v interface{}) error {
+	exists, err := file.Exists(filename)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("file '%s' exists already", filename)
+	}
+	jsn, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, jsn, 0644)
+}
+
+func prepare(
+	net *netconf.Network,
+	homeDir string,
+	ik *netconf.IdentityKey,
+	boxKey *[32]byte,
+	desc string,
+	urls []string,
+	outFile string,
+) error {
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+	if err := os.MkdirAll(filepath.Join(homeDir, netconf.DefPrivKeyListDir), 0755); err != nil {
+		return err
+	}
+	// lock private key list
+	lock, err := netconf.LockFile(privFilename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	exists, err := file.Exists(privFilename)
+	if err != nil {
+		return err
+	}
+	var req *netconf.KeyListRequest
+	if exists {
+		// extend existing key list, the private keys stay encrypted
+		e, err := netconf.LoadEncryptedKeyList(privFilename)
+		if err != nil {
+			return err
+		}
+		if e.MintIdentityKey != id {
+			return netconf.ErrKeyListIdentity
+		}
+		if boxKey != nil && e.BoxKey != netconf.MarshalBoxKey(boxKey) {
+			return fmt.Errorf("key list '%s' is not sealed to box key", privFilename)
+		}
+		mint := e.KeyList
+		req, err = mint.PrepareExtend(net)
+		if err != nil {
+			return err
+		}
+		if req == nil {
+			return fmt.Errorf("key list '%s' covers all epochs already", privFilename)
+		}
+		// seal new private keys to identity key (or box key)
+		if err := e.Add(mint); err != nil {
+			return err
+		}
+		if err := lock.Check(); err != nil {
+			return err
+		}
+		if err := writeNewJSON(outFile, req); err != nil {
+			return err
+		}
+		// save private key list with pending identity signatures
+		if err := e.Save(privFilename); err != nil {
+			return err
+		}
+	} else {
+		if desc == "" || len(urls) == 0 {
+			return fmt.Errorf("new key list requires option -desc and URLs")
+		}
+		mint, r, err := netconf.PrepareMint(desc, ik, urls, net)
+		if err != nil {
+			return err
+		}
+		req = r
+		if err := lock.Check(); err != nil {
+			return err
+		}
+		if err := writeNewJSON(outFile, req); err != nil {
+			return err
+		}
+		// save private key list with pending identity signatures, sealed to
+		// the identity key (or the box key of a threshold mint)
+		var e *netconf.EncryptedKeyList
+		if boxKey != nil {
+			e, err = mint.EncryptBoxKey(boxKey)
+		} else {
+			e, err = mint.Encrypt(ik)
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.Save(privFilename); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("sign '%s' with 'scrit-mint keylist sign' on offline host\n", outFile)
+	return nil
+}
+
+// Prepare implements the scrit-mint 'keylist prepare' command.
+func Prepare(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -identity key [-boxkey key] [-desc description url [...]]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Create or extend key list for %s without identity key.\n", netconf.DefNetConfFile)
+		fmt.Fprintf(os.Stderr, "Writes key list request to be signed offline with 'keylist sign'.\n")
+		fmt.Fprintf(os.Stderr, "Threshold mints seal the private keys to the box key from 'threshold boxkey'.\n")
+		fs.PrintDefaults()
+	}
+	boxKey := fs.String("boxkey", "", "Box key to seal private keys to (threshold mints)")
+	identity := fs.String("identity", "", "Identity key of mint")
+	desc := fs.String("desc", "", "Description of mint (name), for new key lists")
+	outFile := fs.String("o", DefKeyListRequestFile, "Key list request file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *identity == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -identity is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	ik, err := netconf.ParseIdentityKey(*identity)
+	if err != nil {
+		return err
+	}
+	var bk *[32]byte
+	if *boxKey != "" {
+		bk, err = netconf.ParseBoxKey(*boxKey)
+		if err != nil {
+			return err
+		}
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	return prepare(net, homedir.ScritMint(), ik, bk, *desc, fs.Args(), *outFile)
+}
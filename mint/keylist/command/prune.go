@@ -0,0 +1,103 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func prune(homeDir, secKey string, t time.Time) error {
+	// load identity key
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+
+	// lock private key list
+	lock, err := netconf.LockFile(privFilename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	encrypted, err := netconf.IsEncryptedKeyList(privFilename)
+	if err != nil {
+		return err
+	}
+	mint, err := netconf.LoadPrivKeyList(privFilename, ik)
+	if err != nil {
+		return err
+	}
+	n := mint.PruneExpired(t)
+	if n == 0 {
+		fmt.Printf("no expired private keys in '%s'\n", privFilename)
+		return nil
+	}
+	if err := lock.Check(); err != nil {
+		return err
+	}
+
+	// keep format of private key list
+	if encrypted {
+		err = mint.SavePrivKeyList(privFilename, ik)
+	} else {
+		err = mint.Save(privFilename, 0600)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("pruned %d expired private keys from '%s'\n", n, privFilename)
+	return nil
+}
+
+// Prune implements the scrit-mint 'keylist prune' command.
+func Prune(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Erase private keys of expired mint epochs from private key list.\n")
+		fs.PrintDefaults()
+	}
+	at := fs.String("at", "", "Prune as of given time (RFC3339)")
+	secKey := fs.String("s", "", "Secret key file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	t := time.Now()
+	if *at != "" {
+		var err error
+		t, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	return prune(homeDir, *secKey, t)
+}
@@ -0,0 +1,144 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func roll(
+	net *netconf.Network,
+	homeDir, secKey string,
+	dbcType netconf.DBCType,
+	t time.Time,
+) error {
+	// load identity key
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+	confFilename := filepath.Join(netconf.DefMintDir, id+".json")
+
+	// lock key lists
+	locks, err := lockKeyLists(privFilename, confFilename)
+	if err != nil {
+		return err
+	}
+	defer locks.Unlock()
+
+	// make sure these files exist already and are valid
+	mint, err := netconf.LoadPrivKeyList(privFilename, ik)
+	if err != nil {
+		return err
+	}
+	if _, err := netconf.LoadMint(confFilename); err != nil {
+		return err
+	}
+	if err := mint.Validate(net); err != nil {
+		return err
+	}
+
+	// roll key in signing epoch
+	epoch, err := net.SigningEpochAt(t)
+	if err != nil {
+		return err
+	}
+	if _, err := mint.RollKey(ik, epoch, dbcType, t); err != nil {
+		return err
+	}
+	if err := mint.Validate(net); err != nil {
+		return err
+	}
+
+	// make sure key lists have not been modified concurrently
+	if err := locks.Check(); err != nil {
+		return err
+	}
+	// save private key list
+	if err := mint.SavePrivKeyList(privFilename, ik); err != nil {
+		return err
+	}
+	// prune private keys
+	mint.PrunePrivKeys()
+	// save public configuration file
+	if err := mint.Save(confFilename, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("rolled key for %s in epoch %d at %s\n", dbcType, epoch,
+		t.UTC().Format(time.RFC3339))
+	return nil
+}
+
+// Roll implements the scrit-mint 'keylist roll' command.
+func Roll(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -currency currency -amount amount\n", argv0)
+		fmt.Fprintf(os.Stderr, "Replace signing key of DBC type in current signing epoch.\n")
+		fmt.Fprintf(os.Stderr, "DBCs signed with the replaced key can only be reissued afterwards.\n")
+		fs.PrintDefaults()
+	}
+	currency := fs.String("currency", "", "Currency of DBC type")
+	amount := fs.String("amount", "", "Amount of DBC type (decimal, e.g. 1.00)")
+	at := fs.String("at", "", "Time of key change (RFC3339, default: now)")
+	secKey := fs.String("s", "", "Secret key file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *currency == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -currency is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if *amount == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -amount is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	t := time.Now()
+	if *at != "" {
+		var err error
+		t, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	dbcType, err := netconf.ParseDBCType(*currency, *amount)
+	if err != nil {
+		return err
+	}
+	return roll(net, homeDir, *secKey, dbcType, t)
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func sign(homeDir, secKey, reqFile, outFile string) error {
+	req, err := netconf.LoadKeyListRequest(reqFile)
+	if err != nil {
+		return err
+	}
+	if err := req.Verify(); err != nil {
+		return err
+	}
+	// show what we are signing
+	fmt.Printf("key list request for %s:\n", req.MintIdentityKey.MarshalID())
+	for i, e := range req.MintEpochs {
+		fmt.Printf("epoch %d: %s - %s (validate until %s), %d keys\n", req.Start+i,
+			e.SignStart.UTC().Format(time.RFC3339), e.SignEnd.UTC().Format(time.RFC3339),
+			e.ValidateEnd.UTC().Format(time.RFC3339), len(e.KeyList))
+	}
+
+	// load identity key
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	resp, err := req.Sign(ik)
+	if err != nil {
+		return err
+	}
+	if err := writeNewJSON(outFile, resp); err != nil {
+		return err
+	}
+	fmt.Printf("finalize '%s' with 'scrit-mint keylist finalize' on online host\n", outFile)
+	return nil
+}
+
+// Sign implements the scrit-mint 'keylist sign' command.
+func Sign(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-s seckey.bin] [request]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Sign key list request with identity key (on offline host).\n")
+		fs.PrintDefaults()
+	}
+	secKey := fs.String("s", "", "Secret key file")
+	outFile := fs.String("o", DefKeyListResponseFile, "Key list response file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	reqFile := DefKeyListRequestFile
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		reqFile = fs.Arg(0)
+	default:
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	// no secpkg.UpToDate() here, we are offline
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	return sign(homeDir, *secKey, reqFile, *outFile)
+}
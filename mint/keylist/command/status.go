@@ -0,0 +1,132 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// mintEpochState returns the state of mint epoch e at time t.
+func mintEpochState(e *netconf.MintEpoch, t time.Time) string {
+	switch {
+	case t.Before(e.SignStart):
+		return "future"
+	case t.Before(e.SignEnd):
+		return "signing"
+	case t.Before(e.ValidateEnd):
+		return "validating"
+	default:
+		return "expired"
+	}
+}
+
+func status(net *netconf.Network, homeDir, secKey string, t time.Time) error {
+	// load identity key
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+	confFilename := filepath.Join(netconf.DefMintDir, id+".json")
+	priv, err := netconf.LoadPrivKeyList(privFilename, ik)
+	if err != nil {
+		return err
+	}
+	pub, err := netconf.LoadMint(confFilename)
+	if err != nil {
+		return err
+	}
+
+	// epoch overview
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "EPOCH\tSIGN START\tSIGN END\tVALIDATE END\tKEYS\tPRIVATE\tSTATE")
+	for i, e := range priv.MintEpochs {
+		var privKeys int
+		for _, k := range e.KeyList {
+			if len(k.PrivKey) > 0 {
+				privKeys++
+			}
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\t%d\t%s\n", i,
+			e.SignStart.Format(time.RFC3339), e.SignEnd.Format(time.RFC3339),
+			e.ValidateEnd.Format(time.RFC3339), len(e.KeyList), privKeys,
+			mintEpochState(e, t))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// consistency checks
+	r := netconf.KeyListStatus(priv, pub, net, t)
+	if len(r.Issues) == 0 {
+		fmt.Println("private and public key list are consistent")
+		return nil
+	}
+	if err := r.WriteText(os.Stdout); err != nil {
+		return err
+	}
+	if r.HasErrors() {
+		return errors.New("key lists are inconsistent")
+	}
+	return nil
+}
+
+// Status implements the scrit-mint 'keylist status' command.
+func Status(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Show key list status and check private and public key list for consistency.\n")
+		fs.PrintDefaults()
+	}
+	at := fs.String("at", "", "Show status as of given time (RFC3339)")
+	secKey := fs.String("s", "", "Secret key file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	t := time.Now()
+	if *at != "" {
+		var err error
+		t, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+	}
+	homeDir := homedir.ScritMint()
+	if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	return status(net, homeDir, *secKey, t)
+}
@@ -0,0 +1,142 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// loadPrivKeyList loads and decrypts the private key list of the mint with
+// the identity key from secKey.
+func loadPrivKeyList(homeDir, secKey string) (*netconf.Mint, error) {
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return nil, err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+	return netconf.LoadPrivKeyList(privFilename, ik)
+}
+
+// loadThresholdPrivKeyList loads the private key list of the threshold mint
+// with identity key ik and decrypts it with the box key of the mint.
+func loadThresholdPrivKeyList(homeDir string, ik *netconf.IdentityKey) (*netconf.Mint, error) {
+	id := ik.MarshalID()
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir, id+".json")
+	e, err := netconf.LoadEncryptedKeyList(privFilename)
+	if err != nil {
+		return nil, err
+	}
+	if e.MintIdentityKey != id {
+		return nil, netconf.ErrKeyListIdentity
+	}
+	priv, err := threshold.LoadBoxKey(filepath.Join(homeDir, threshold.DefThresholdDir,
+		threshold.DefBoxKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(priv[:])
+	return e.DecryptBoxKey(priv)
+}
+
+func unlock(
+	net *netconf.Network,
+	homeDir, secKey string,
+	ik *netconf.IdentityKey,
+	outFile string,
+) error {
+	// decrypt and validate private key list
+	var (
+		mint *netconf.Mint
+		err  error
+	)
+	if ik != nil {
+		mint, err = loadThresholdPrivKeyList(homeDir, ik)
+	} else {
+		mint, err = loadPrivKeyList(homeDir, secKey)
+	}
+	if err != nil {
+		return err
+	}
+	if err := mint.Validate(net); err != nil {
+		return err
+	}
+	exists, err := file.Exists(outFile)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("file '%s' exists already", outFile)
+	}
+	fmt.Fprintf(os.Stderr, "WARNING: writing plaintext private key list to '%s'\n",
+		outFile)
+	return mint.Save(outFile, 0600)
+}
+
+// Unlock implements the scrit-mint 'keylist unlock' command.
+func Unlock(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-s seckey.bin | -identity key] -o file\n", argv0)
+		fmt.Fprintf(os.Stderr, "Decrypt and validate private key list and write a plaintext copy to file.\n")
+		fmt.Fprintf(os.Stderr, "Avoid it, if possible ('keylist status' validates the key list in memory).\n")
+		fmt.Fprintf(os.Stderr, "Threshold mints (-identity) decrypt the key list with the box key.\n")
+		fs.PrintDefaults()
+	}
+	identity := fs.String("identity", "", "Identity key of threshold mint")
+	outFile := fs.String("o", "", "Write plaintext private key list to file (mode 0600)")
+	secKey := fs.String("s", "", "Secret key file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *outFile == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -o is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	homeDir := homedir.ScritMint()
+	var ik *netconf.IdentityKey
+	if *identity != "" {
+		if *secKey != "" {
+			fmt.Fprintf(os.Stderr, "%s: options -s and -identity exclude each other\n", argv0)
+			return flag.ErrHelp
+		}
+		k, err := netconf.ParseIdentityKey(*identity)
+		if err != nil {
+			return err
+		}
+		ik = k
+	} else if err := seckey.Check(homeDir, *secKey); err != nil {
+		return err
+	}
+	net, err := netconf.LoadNetwork(netconf.DefNetConfFile)
+	if err != nil {
+		return err
+	}
+	if err := net.Validate(); err != nil {
+		return err
+	}
+	return unlock(net, homeDir, *secKey, ik, *outFile)
+}
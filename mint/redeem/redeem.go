@@ -0,0 +1,385 @@
+// Package redeem implements the redemption of DBCs for external funds.
+//
+// A wallet redeems DBCs with a redemption Request, which spends the DBCs
+// without producing outputs. Every mint redeems the request on its own: it
+// verifies the DBCs, records them in its spendbook with redemption
+// commitments (see mintcom.NewRedemption), records the destroyed DBCs in its
+// destruction ledger (see netconf.Ledger), and logs the redemption for payout
+// processing. The commitments are returned to the wallet as a Receipt.
+package redeem
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/scritcash/scrit/mint/spendbook"
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// DefRequestFile defines the default name of the file redemption requests
+// are written to.
+const DefRequestFile = "redemption.json"
+
+// DefReceiptFile defines the default name of the file redemption receipts
+// are written to.
+const DefReceiptFile = "receipt.json"
+
+// DefRedemptionsDir defines the default name of the directory of the
+// redemption log in the mint home directory.
+const DefRedemptionsDir = "redemptions"
+
+// DefRedemptionsFile defines the default name of the redemption log in the
+// redemption log directory.
+const DefRedemptionsFile = "redemptions.json"
+
+// ErrRequest is returned if a redemption request is invalid.
+var ErrRequest = errors.New("redeem: invalid request")
+
+// ErrRedeemed is returned if the ID of a redemption request has been redeemed
+// already with other DBCs.
+var ErrRedeemed = errors.New("redeem: request redeemed already")
+
+// ErrReceipt is returned if a redemption receipt does not verify.
+var ErrReceipt = errors.New("redeem: invalid receipt")
+
+// Redemption is an entry of the redemption log of a mint, the payout for a
+// redeemed request.
+type Redemption struct {
+	RequestID string                // ID of redemption request
+	Payout    string                // payout destination
+	Currency  string                // currency of payout
+	Amount    uint64                // amount of payout, last 8 digits are decimal places
+	Epoch     int                   // signing epoch of redemption
+	Time      time.Time             // time of redemption
+	Entries   []netconf.LedgerEntry // destruction ledger entries
+}
+
+// RedemptionsFilename returns the filename of the redemption log in the mint
+// home directory homeDir.
+func RedemptionsFilename(homeDir string) string {
+	return filepath.Join(homeDir, DefRedemptionsDir, DefRedemptionsFile)
+}
+
+// LoadRedemptions loads the redemption log from filename. A missing log is
+// empty.
+func LoadRedemptions(filename string) ([]*Redemption, error) {
+	if _, err := atomicfile.Recover(filename, json.Valid); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var redemptions []*Redemption
+	if err := json.Unmarshal(data, &redemptions); err != nil {
+		return nil, fmt.Errorf("redeem: '%s': %w", filename, err)
+	}
+	return redemptions, nil
+}
+
+// Confirmed returns true, if all ledger entries of redemption rd are contained
+// unchanged in confirmed (see netconf.Federation.ConfirmedEntries) and their
+// value matches the payout of rd.
+func (rd *Redemption) Confirmed(confirmed map[string]netconf.LedgerEntry) bool {
+	if len(rd.Entries) == 0 {
+		return false
+	}
+	var sum uint64
+	for i := range rd.Entries {
+		e := &rd.Entries[i]
+		c, ok := confirmed[e.ID]
+		if !ok || !sameEntry(e, &c) || e.DBCType.Currency != rd.Currency {
+			return false
+		}
+		hi, value := bits.Mul64(e.Count, e.DBCType.Amount)
+		if hi != 0 {
+			return false
+		}
+		var carry uint64
+		sum, carry = bits.Add64(sum, value, 0)
+		if carry != 0 {
+			return false
+		}
+	}
+	return sum == rd.Amount
+}
+
+// logRedemption appends rd to the redemption log in the mint home directory
+// homeDir, unless the request has been logged already.
+func logRedemption(homeDir string, rd *Redemption) error {
+	filename := RedemptionsFilename(homeDir)
+	if err := os.MkdirAll(filepath.Dir(filename), 0700); err != nil {
+		return err
+	}
+	// lock
+	lock, err := netconf.LockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	// load
+	redemptions, err := LoadRedemptions(filename)
+	if err != nil {
+		return err
+	}
+	// edit
+	for _, o := range redemptions {
+		if o.RequestID == rd.RequestID {
+			return nil // logged already
+		}
+	}
+	redemptions = append(redemptions, rd)
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	jsn, err := netconf.MarshalCanonical(redemptions)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0600)
+}
+
+// Mint redeems DBCs for a mint of a federation.
+type Mint struct {
+	Federation  *netconf.Federation           // the federation of the mint
+	IdentityKey *netconf.IdentityKey          // identity key of the mint
+	PrivateKey  *[mintcom.PrivateKeySize]byte // private identity key, signs commitments
+	Signer      netconf.Signer                // knows private identity key, signs ledger
+	HomeDir     string                        // contains spendbook and redemption log
+}
+
+// requestID returns the ID of the redemption request of the ledger entry
+// with the given ID (see Request.EntryID).
+func requestID(entryID string) string {
+	if i := strings.LastIndex(entryID, "/"); i >= 0 {
+		return entryID[:i]
+	}
+	return entryID
+}
+
+// sameEntry returns true, if the ledger entries a and b record the same DBCs.
+func sameEntry(a, b *netconf.LedgerEntry) bool {
+	return a.ID == b.ID && a.DBCType == b.DBCType && a.Count == b.Count &&
+		strings.Join(a.Inputs, ",") == strings.Join(b.Inputs, ",")
+}
+
+// checkLedgers checks the destruction ledgers of all mints for the entries
+// of request r. DBCs destroyed by any mint for another request have been
+// spent federation-wide. It returns true, if the mint with ID mintID
+// recorded the entries of r already (a retry).
+func checkLedgers(
+	ledgers []*netconf.Ledger,
+	mintID string,
+	r *Request,
+	entries []netconf.LedgerEntry,
+) (bool, error) {
+	inputs := make(map[string]bool)
+	for _, e := range entries {
+		for _, in := range e.Inputs {
+			inputs[in] = true
+		}
+	}
+	var recorded int
+	for _, l := range ledgers {
+		own := l.MintIdentityKey.MarshalID() == mintID
+		for i := range l.Entries {
+			le := &l.Entries[i]
+			if requestID(le.ID) != r.ID {
+				for _, in := range le.Inputs {
+					if inputs[in] {
+						return false, fmt.Errorf("%w: %s destroyed for request %s",
+							spendbook.ErrSpent, in, requestID(le.ID))
+					}
+				}
+				continue
+			}
+			if !own {
+				continue // other mint redeemed the same request
+			}
+			var found bool
+			for j := range entries {
+				if sameEntry(le, &entries[j]) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false, fmt.Errorf("%w: %s with other DBCs", ErrRedeemed, r.ID)
+			}
+			recorded++
+		}
+	}
+	if recorded > 0 && recorded != len(entries) {
+		return false, fmt.Errorf("%w: %s partially", ErrRedeemed, r.ID)
+	}
+	return recorded > 0, nil
+}
+
+// Redeem redeems request r at time t. The inputs are recorded in the
+// spendbook of the mint, the destroyed DBCs in the destruction ledger of the
+// mint in the federation directory dir, and the payout in the redemption log.
+//
+// Inputs which have been spent already (at this mint or destroyed by any
+// mint for another request) fail with spendbook.ErrSpent and nothing is
+// recorded. Redeeming the same request again completes an interrupted
+// redemption and returns the same receipt, it doesn't record anything
+// twice. Inputs which can only be reissued (netconf.AcceptRecovery) are
+// rejected.
+func (m *Mint) Redeem(dir string, r *Request, t time.Time) (*Receipt, error) {
+	rc, entries, err := m.destroy(dir, r, t, netconf.Accept)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.Value()
+	if err != nil {
+		return nil, err
+	}
+	err = logRedemption(m.HomeDir, &Redemption{
+		RequestID: r.ID,
+		Payout:    r.Payout,
+		Currency:  r.Currency(),
+		Amount:    value,
+		Epoch:     rc.Epoch,
+		Time:      t,
+		Entries:   entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rc, nil
+}
+
+// Destroy destroys the inputs of request r at time t like Redeem, but
+// without logging a payout. Inputs which can only be reissued
+// (netconf.AcceptRecovery) are accepted, the caller must create DBCs of the
+// same value for them (see package reissue).
+func (m *Mint) Destroy(dir string, r *Request, t time.Time) (*Receipt, error) {
+	rc, _, err := m.destroy(dir, r, t, netconf.AcceptRecovery)
+	return rc, err
+}
+
+// destroy destroys the inputs of request r at time t, which must be at least
+// acceptable. It returns the receipt and the destruction ledger
+// entries of r.
+func (m *Mint) destroy(
+	dir string,
+	r *Request,
+	t time.Time,
+	acceptable netconf.Acceptance,
+) (*Receipt, []netconf.LedgerEntry, error) {
+	if err := r.Validate(); err != nil {
+		return nil, nil, err
+	}
+	// check mint
+	net := m.Federation.Network
+	epoch, err := net.SigningEpochAt(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	id := m.IdentityKey.MarshalID()
+	mints, err := net.MintsAt(t)
+	if err != nil {
+		return nil, nil, err
+	}
+	mint, ok := m.Federation.Mints[id]
+	if !mints[id] || !ok {
+		return nil, nil, fmt.Errorf("%w: %s", netconf.ErrLedgerMint, id)
+	}
+	if m.Federation.Revoked(id, epoch, t) {
+		return nil, nil, fmt.Errorf("%w: %s", netconf.ErrRevoked, id)
+	}
+	// check inputs
+	for i, in := range r.Inputs {
+		a, err := in.Verify(m.Federation, t)
+		if err != nil {
+			return nil, nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		if a < acceptable {
+			return nil, nil, fmt.Errorf("%w: input %d can only be reissued", ErrRequest, i)
+		}
+	}
+	entries := r.Entries(t)
+	// spend inputs and record them in ledger
+	sb, err := spendbook.Open(m.HomeDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sb.Close()
+	var pubKey [mintcom.PublicKeySize]byte
+	copy(pubKey[:], m.IdentityKey.PubKey)
+	mintID := mintcom.KeyID(pubKey[:])
+	proof := r.Proof()
+	hp := mintcom.Hash(proof)
+	rc := &Receipt{
+		RequestID:       r.ID,
+		MintIdentityKey: mint.MintIdentityKey,
+		Epoch:           epoch,
+	}
+	err = netconf.RecordLedger(dir, m.IdentityKey, m.Signer, netconf.LedgerDestroyed, epoch,
+		func(l *netconf.Ledger, ledgers []*netconf.Ledger) error {
+			// ledgers of previous epochs have been loaded with the
+			// federation, the current one is locked
+			var all []*netconf.Ledger
+			for e, ls := range m.Federation.Destroyed {
+				if e != epoch {
+					all = append(all, ls...)
+				}
+			}
+			all = append(all, ledgers...)
+			done, err := checkLedgers(all, id, r, entries)
+			if err != nil {
+				return err
+			}
+			for _, in := range r.Inputs {
+				hi := mintcom.Hash(in.Input())
+				com := sb.Lookup(mintcom.Hash(hi[:]))
+				if com != nil && com.HP != hp {
+					return fmt.Errorf("%w: %s", spendbook.ErrSpent,
+						hex.EncodeToString(com.HHI[:]))
+				}
+				if com == nil { // not a retry
+					com, err = mintcom.NewRedemption(mintID, in.Input(), proof, &pubKey,
+						m.PrivateKey)
+					if err != nil {
+						return err
+					}
+					if err := sb.Add(com, epoch, in.DBCType, netconf.DBCType{}); err != nil {
+						return err
+					}
+				}
+				rc.Commitments = append(rc.Commitments, com.Marshal())
+			}
+			// the spendbook is saved before the ledger: a DBC must never
+			// be destroyed without being spent
+			if err := sb.Save(); err != nil {
+				return err
+			}
+			if done {
+				return nil
+			}
+			for _, e := range entries {
+				if err := l.Add(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+	return rc, entries, nil
+}
@@ -0,0 +1,323 @@
+package redeem
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/mint/spendbook"
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/netconf/netconftest"
+)
+
+var (
+	oneEUR = netconf.DBCType{Currency: "EUR", Amount: 100000000}
+	twoEUR = netconf.DBCType{Currency: "EUR", Amount: 200000000}
+)
+
+type testFed struct {
+	fed     *netconf.Federation
+	iks     []*netconf.IdentityKey
+	privs   []*[mintcom.PrivateKeySize]byte
+	signers []netconf.Signer
+	at      time.Time // in first signing epoch
+}
+
+// newTestFed returns a 2-of-3 federation with the DBC types oneEUR and
+// twoEUR.
+func newTestFed(t *testing.T) *testFed {
+	f := netconftest.NewFederation(t, oneEUR, twoEUR)
+	tf := &testFed{
+		fed:   f.Federation,
+		iks:   f.IdentityKeys,
+		privs: f.PrivateKeys,
+		at:    f.At,
+	}
+	for _, s := range f.Signers {
+		tf.signers = append(tf.signers, s)
+	}
+	return tf
+}
+
+// dbc returns a new DBC of the given type signed by the first n mints.
+func (tf *testFed) dbc(t *testing.T, dbcType netconf.DBCType, msg string, n int) *dbc.DBC {
+	d := &dbc.DBC{DBCType: dbcType, Message: []byte(msg)}
+	for i := 0; i < n; i++ {
+		id := tf.iks[i].MarshalID()
+		k := tf.fed.Mints[id].MintEpochs[0].SigningKeyAt(dbcType, tf.at)
+		sig, err := tf.signers[i].Sign(k.PubKey, d.Message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Signatures = append(d.Signatures, dbc.Signature{
+			MintID:    id,
+			PubKey:    k.PubKey,
+			Signature: sig,
+			Time:      tf.at,
+		})
+	}
+	return d
+}
+
+// mint returns the i-th mint of the federation.
+func (tf *testFed) mint(t *testing.T, i int) *Mint {
+	return &Mint{
+		Federation:  tf.fed,
+		IdentityKey: tf.iks[i],
+		PrivateKey:  tf.privs[i],
+		Signer:      tf.signers[i],
+		HomeDir:     t.TempDir(),
+	}
+}
+
+func TestRedeem(t *testing.T) {
+	tf := newTestFed(t)
+	m := tf.mint(t, 0)
+	dir := t.TempDir()
+	inputs := []*dbc.DBC{
+		tf.dbc(t, twoEUR, "dbc 1", 2),
+		tf.dbc(t, oneEUR, "dbc 2", 2),
+		tf.dbc(t, oneEUR, "dbc 3", 3),
+	}
+	r, err := NewRequest("redeem1", "DE00 1234", inputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, DefRequestFile)
+	if err := r.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	r, err = LoadRequest(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := m.Redeem(dir, r, tf.at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// check receipt
+	filename = filepath.Join(dir, DefReceiptFile)
+	if err := rc.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	rc, err = LoadReceipt(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.Verify(r); err != nil {
+		t.Error(err)
+	}
+	other := *r
+	other.Payout = "DE00 5678"
+	if err := rc.Verify(&other); !errors.Is(err, ErrReceipt) {
+		t.Errorf("Verify() should fail with ErrReceipt, got: %v", err)
+	}
+
+	// check ledger
+	ledgers, err := netconf.LoadLedgers(filepath.Join(dir,
+		netconf.LedgerFilename(0, netconf.LedgerDestroyed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgers) != 1 {
+		t.Fatalf("%d ledgers instead of 1", len(ledgers))
+	}
+	if err := ledgers[0].Validate(tf.fed.Network); err != nil {
+		t.Error(err)
+	}
+	e := ledgers[0].Entry(r.EntryID(oneEUR))
+	if e == nil || e.Count != 2 {
+		t.Errorf("wrong ledger entry: %+v", e)
+	}
+
+	// check redemption log
+	redemptions, err := LoadRedemptions(RedemptionsFilename(m.HomeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redemptions) != 1 {
+		t.Fatalf("%d redemptions instead of 1", len(redemptions))
+	}
+	if rd := redemptions[0]; rd.Payout != r.Payout || rd.Currency != "EUR" ||
+		rd.Amount != 400000000 {
+		t.Errorf("wrong redemption: %+v", rd)
+	}
+
+	// DBCs can only be spent once
+	r2, err := NewRequest("redeem2", "DE00 1234", inputs[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Redeem(dir, r2, tf.at); !errors.Is(err, spendbook.ErrSpent) {
+		t.Errorf("Redeem() should fail with spendbook.ErrSpent, got: %v", err)
+	}
+	redemptions, err = LoadRedemptions(RedemptionsFilename(m.HomeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redemptions) != 1 {
+		t.Errorf("%d redemptions instead of 1", len(redemptions))
+	}
+}
+
+func TestRedeemRetry(t *testing.T) {
+	tf := newTestFed(t)
+	m := tf.mint(t, 0)
+	dir := t.TempDir()
+	r, err := NewRequest("redeem1", "DE00 1234", []*dbc.DBC{tf.dbc(t, oneEUR, "dbc", 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc1, err := m.Redeem(dir, r, tf.at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// simulate a failure after the ledger was saved
+	if err := os.Remove(RedemptionsFilename(m.HomeDir)); err != nil {
+		t.Fatal(err)
+	}
+	rc2, err := m.Redeem(dir, r, tf.at)
+	if err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+	if !bytes.Equal(rc1.Commitments[0], rc2.Commitments[0]) {
+		t.Error("retry returned different commitment")
+	}
+	if _, err := m.Redeem(dir, r, tf.at); err != nil {
+		t.Errorf("second retry failed: %v", err)
+	}
+	redemptions, err := LoadRedemptions(RedemptionsFilename(m.HomeDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(redemptions) != 1 {
+		t.Errorf("%d redemptions instead of 1", len(redemptions))
+	}
+	ledgers, err := netconf.LoadLedgers(filepath.Join(dir,
+		netconf.LedgerFilename(0, netconf.LedgerDestroyed)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledgers) != 1 || len(ledgers[0].Entries) != 1 {
+		t.Errorf("retry changed ledger: %+v", ledgers)
+	}
+
+	// same DBC with other payout
+	other := *r
+	other.Payout = "DE00 5678"
+	if _, err := m.Redeem(dir, &other, tf.at); !errors.Is(err, spendbook.ErrSpent) {
+		t.Errorf("Redeem() should fail with spendbook.ErrSpent, got: %v", err)
+	}
+}
+
+func TestRedeemFederationWide(t *testing.T) {
+	tf := newTestFed(t)
+	dir := t.TempDir()
+	d := tf.dbc(t, oneEUR, "dbc", 2)
+	r1, err := NewRequest("redeem1", "DE00 1234", []*dbc.DBC{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tf.mint(t, 0).Redeem(dir, r1, tf.at); err != nil {
+		t.Fatal(err)
+	}
+	// the same DBC redeemed at another mint with another request
+	r2, err := NewRequest("redeem2", "DE00 5678", []*dbc.DBC{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := tf.mint(t, 1)
+	if _, err := m.Redeem(dir, r2, tf.at); !errors.Is(err, spendbook.ErrSpent) {
+		t.Errorf("Redeem() should fail with spendbook.ErrSpent, got: %v", err)
+	}
+	if _, err := os.Stat(RedemptionsFilename(m.HomeDir)); !os.IsNotExist(err) {
+		t.Errorf("redemption logged: %v", err)
+	}
+	// the same request at another mint is fine
+	if _, err := m.Redeem(dir, r1, tf.at); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedeemInvalid(t *testing.T) {
+	tf := newTestFed(t)
+	m := tf.mint(t, 0)
+	dir := t.TempDir()
+	d := tf.dbc(t, oneEUR, "dbc", 2)
+	if _, err := NewRequest("", "DE00 1234", []*dbc.DBC{d}); !errors.Is(err, ErrRequest) {
+		t.Errorf("NewRequest() should fail with ErrRequest, got: %v", err)
+	}
+	if _, err := NewRequest("id", "", []*dbc.DBC{d}); !errors.Is(err, ErrRequest) {
+		t.Errorf("NewRequest() should fail with ErrRequest, got: %v", err)
+	}
+	if _, err := NewRequest("id", "DE00 1234", []*dbc.DBC{d, d}); !errors.Is(err, ErrRequest) {
+		t.Errorf("NewRequest() should fail with ErrRequest, got: %v", err)
+	}
+	usd := netconf.DBCType{Currency: "USD", Amount: 100000000}
+	_, err := NewRequest("id", "DE00 1234", []*dbc.DBC{d, {DBCType: usd}})
+	if !errors.Is(err, ErrRequest) {
+		t.Errorf("NewRequest() should fail with ErrRequest, got: %v", err)
+	}
+
+	// signatures must reach the quorum
+	r, err := NewRequest("id", "DE00 1234", []*dbc.DBC{tf.dbc(t, oneEUR, "dbc", 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Redeem(dir, r, tf.at); !errors.Is(err, dbc.ErrQuorum) {
+		t.Errorf("Redeem() should fail with dbc.ErrQuorum, got: %v", err)
+	}
+	sb, err := spendbook.Open(m.HomeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+	if sb.Len() != 0 {
+		t.Errorf("spendbook has %d entries instead of 0", sb.Len())
+	}
+}
+
+func TestRedemptionConfirmed(t *testing.T) {
+	entries := []netconf.LedgerEntry{
+		{ID: "redeem1/" + oneEUR.String(), DBCType: oneEUR, Count: 2, Inputs: []string{"a", "b"}},
+		{ID: "redeem1/" + twoEUR.String(), DBCType: twoEUR, Count: 1, Inputs: []string{"c"}},
+	}
+	confirmed := make(map[string]netconf.LedgerEntry)
+	for _, e := range entries {
+		confirmed[e.ID] = e
+	}
+	rd := &Redemption{
+		RequestID: "redeem1",
+		Currency:  "EUR",
+		Amount:    400000000,
+		Time:      time.Now(),
+		Entries:   entries,
+	}
+	if !rd.Confirmed(confirmed) {
+		t.Error("redemption should be confirmed")
+	}
+	// a confirmed entry with the same ID but other inputs
+	other := entries[0]
+	other.Inputs = []string{"a", "x"}
+	confirmed[other.ID] = other
+	if rd.Confirmed(confirmed) {
+		t.Error("redemption with other inputs should not be confirmed")
+	}
+	confirmed[other.ID] = entries[0]
+	// the payout does not match the destroyed DBCs
+	rd.Amount = 500000000
+	if rd.Confirmed(confirmed) {
+		t.Error("redemption with wrong amount should not be confirmed")
+	}
+	rd.Amount = 400000000
+	delete(confirmed, entries[1].ID)
+	if rd.Confirmed(confirmed) {
+		t.Error("redemption with unconfirmed entry should not be confirmed")
+	}
+}
@@ -0,0 +1,238 @@
+package redeem
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/bits"
+	"sort"
+	"time"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// Request is a redemption request: the DBCs Inputs are spent without outputs
+// and their value is paid out to Payout.
+type Request struct {
+	ID     string     // request reference, chosen by the wallet
+	Payout string     // payout destination (e.g., an IBAN)
+	Inputs []*dbc.DBC // DBCs to redeem
+}
+
+// NewRequest returns a new validated redemption request with reference id
+// which redeems inputs to the payout destination payout.
+func NewRequest(id, payout string, inputs []*dbc.DBC) (*Request, error) {
+	r := &Request{
+		ID:     id,
+		Payout: payout,
+		Inputs: inputs,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate the request: the inputs must be distinct DBCs of the same
+// currency.
+func (r *Request) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("%w: ID missing", ErrRequest)
+	}
+	if r.Payout == "" {
+		return fmt.Errorf("%w: payout destination missing", ErrRequest)
+	}
+	if len(r.Inputs) == 0 {
+		return fmt.Errorf("%w: no inputs", ErrRequest)
+	}
+	for i, in := range r.Inputs {
+		if in.DBCType.Currency != r.Inputs[0].DBCType.Currency {
+			return fmt.Errorf("%w: inputs with currencies %s and %s", ErrRequest,
+				r.Inputs[0].DBCType.Currency, in.DBCType.Currency)
+		}
+		for _, other := range r.Inputs[:i] {
+			if bytes.Equal(in.Input(), other.Input()) {
+				return fmt.Errorf("%w: duplicate input", ErrRequest)
+			}
+		}
+	}
+	if _, err := r.Value(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Currency returns the currency of the redeemed DBCs.
+func (r *Request) Currency() string {
+	if len(r.Inputs) == 0 {
+		return ""
+	}
+	return r.Inputs[0].DBCType.Currency
+}
+
+// Value returns the total value of the redeemed DBCs.
+func (r *Request) Value() (uint64, error) {
+	var sum uint64
+	for _, in := range r.Inputs {
+		var carry uint64
+		sum, carry = bits.Add64(sum, in.DBCType.Amount, 0)
+		if carry != 0 {
+			return 0, fmt.Errorf("%w: inputs overflow", ErrRequest)
+		}
+	}
+	return sum, nil
+}
+
+// Counts returns the number of inputs per DBC type.
+func (r *Request) Counts() map[netconf.DBCType]uint64 {
+	counts := make(map[netconf.DBCType]uint64)
+	for _, in := range r.Inputs {
+		counts[in.DBCType]++
+	}
+	return counts
+}
+
+// EntryID returns the ID of the ledger entry for the inputs of the given DBC
+// type of request r.
+func (r *Request) EntryID(dbcType netconf.DBCType) string {
+	return r.ID + "/" + dbcType.String()
+}
+
+// Entries returns the entries of the destruction ledger for request r at
+// time t, one per DBC type. They list the hex(HHI) of all inputs (sorted), to
+// detect DBCs redeemed for different requests at different mints.
+func (r *Request) Entries(t time.Time) []netconf.LedgerEntry {
+	inputs := make(map[netconf.DBCType][]string)
+	for _, in := range r.Inputs {
+		hi := mintcom.Hash(in.Input())
+		hhi := mintcom.Hash(hi[:])
+		inputs[in.DBCType] = append(inputs[in.DBCType], hex.EncodeToString(hhi[:]))
+	}
+	var entries []netconf.LedgerEntry
+	counts := r.Counts()
+	for _, dbcType := range netconf.DBCTypeCountsToSortedArray(counts) {
+		sort.Strings(inputs[dbcType])
+		entries = append(entries, netconf.LedgerEntry{
+			ID:      r.EntryID(dbcType),
+			DBCType: dbcType,
+			Count:   counts[dbcType],
+			Time:    t,
+			Inputs:  inputs[dbcType],
+		})
+	}
+	return entries
+}
+
+// Proof returns the proof of the redemption commitments of request r, which
+// binds them to the request reference and the payout destination.
+func (r *Request) Proof() []byte {
+	encodingScheme := []interface{}{
+		[]byte(r.ID),
+		[]byte(r.Payout),
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	buf := make([]byte, size)
+	enc, err := binencode.Encode(buf, encodingScheme...)
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	return enc
+}
+
+// LoadRequest loads a redemption request from filename.
+func LoadRequest(filename string) (*Request, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var r Request
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Save the request to filename (with mode 0600, because it contains bearer
+// certificates).
+func (r *Request) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(r)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0600)
+}
+
+// Receipt is the signed receipt of a redemption by a mint. It contains a
+// redemption commitment (see mintcom.NewRedemption) signed with the identity
+// key of the mint per input of the request (same order).
+type Receipt struct {
+	RequestID       string              // ID of redemption request
+	MintIdentityKey netconf.IdentityKey // identity key of redeeming mint
+	Epoch           int                 // signing epoch
+	Commitments     [][]byte            // marshalled redemption commitments
+}
+
+// Verify that receipt rc is a valid receipt for request r.
+func (rc *Receipt) Verify(r *Request) error {
+	if rc.RequestID != r.ID {
+		return fmt.Errorf("%w: receipt for request %s", ErrReceipt, rc.RequestID)
+	}
+	if len(rc.Commitments) != len(r.Inputs) {
+		return fmt.Errorf("%w: %d commitments for %d inputs", ErrReceipt,
+			len(rc.Commitments), len(r.Inputs))
+	}
+	if len(rc.MintIdentityKey.PubKey) != mintcom.PublicKeySize {
+		return fmt.Errorf("%w: invalid mint identity key", ErrReceipt)
+	}
+	var pubKey [mintcom.PublicKeySize]byte
+	copy(pubKey[:], rc.MintIdentityKey.PubKey)
+	mintID := mintcom.KeyID(pubKey[:])
+	hp := mintcom.Hash(r.Proof())
+	for i, m := range rc.Commitments {
+		com := new(mintcom.Commitment).Unmarshal(m)
+		if com == nil {
+			return fmt.Errorf("%w: cannot unmarshal commitment %d", ErrReceipt, i)
+		}
+		hi := mintcom.Hash(r.Inputs[i].Input())
+		hiok, ok := com.Verify(nil, &hi, &pubKey)
+		if !hiok || !ok {
+			return fmt.Errorf("%w: commitment %d does not verify", ErrReceipt, i)
+		}
+		if com.MintID != mintID || com.HP != hp || !com.IsRedemption() {
+			return fmt.Errorf("%w: commitment %d is not a redemption of the request",
+				ErrReceipt, i)
+		}
+	}
+	return nil
+}
+
+// LoadReceipt loads a redemption receipt from filename.
+func LoadReceipt(filename string) (*Receipt, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var rc Receipt
+	if err := json.Unmarshal(data, &rc); err != nil {
+		return nil, err
+	}
+	return &rc, nil
+}
+
+// Save the receipt to filename.
+func (rc *Receipt) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(rc)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
@@ -0,0 +1,263 @@
+// Package reissue implements the reissue of DBCs: DBCs are destroyed and new
+// DBCs of the same value are created in exchange.
+//
+// A reissue is the recovery path for DBCs which only reach the quorum with
+// the help of signatures by revoked signing keys (netconf.AcceptRecovery):
+// they can neither be transferred nor redeemed, but until the end of the
+// recovery period of the revocation they can be reissued. DBCs which are
+// fully acceptable can be reissued as well.
+//
+// Every mint reissues the request on its own: it destroys the inputs like a
+// redemption (see redeem.Mint.Destroy), without logging a payout, and signs
+// the outputs like an issuance order (see issue.Mint.Create). The receipt and
+// the output signatures are returned to the wallet as a Reissuance.
+package reissue
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/mint/issue"
+	"github.com/scritcash/scrit/mint/redeem"
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// DefRequestFile defines the default name of the file reissue requests are
+// written to.
+const DefRequestFile = "reissue.json"
+
+// DefReissuanceFile defines the default name of the file reissuances are
+// written to.
+const DefReissuanceFile = "reissuance.json"
+
+// idPrefix is prepended to the request ID in the ledgers, to keep reissues
+// apart from deposits and redemptions.
+const idPrefix = "reissue:"
+
+// ErrReissuance is returned if a reissuance does not match its request.
+var ErrReissuance = errors.New("reissue: invalid reissuance")
+
+// Request is a reissue request: the DBCs Inputs are destroyed and the DBCs
+// Outputs with the same total value are created.
+type Request struct {
+	ID      string         // request reference, chosen by the wallet
+	Inputs  []*dbc.DBC     // DBCs to reissue
+	Outputs []issue.Output // DBCs to create
+}
+
+// NewRequest returns a new validated reissue request with reference id which
+// reissues inputs as outputs.
+func NewRequest(id string, inputs []*dbc.DBC, outputs []issue.Output) (*Request, error) {
+	r := &Request{
+		ID:      id,
+		Inputs:  inputs,
+		Outputs: outputs,
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Validate the request: the inputs must be distinct DBCs of the same
+// currency and the outputs must sum up to their value. If the DBC types of
+// the outputs are defined is checked by the mints.
+func (r *Request) Validate() error {
+	if err := r.redemption().Validate(); err != nil {
+		return err
+	}
+	dbcTypes := make(map[netconf.DBCType]bool)
+	for _, out := range r.Outputs {
+		dbcTypes[out.DBCType] = true
+	}
+	return r.order().Validate(dbcTypes)
+}
+
+// payout returns the payout destination of the redemption of request r,
+// which binds the redemption commitments to the outputs.
+func (r *Request) payout() string {
+	var encodingScheme []interface{}
+	for _, out := range r.Outputs {
+		encodingScheme = append(encodingScheme,
+			[]byte(out.DBCType.Currency),
+			int64(out.DBCType.Amount),
+			out.Blinded,
+		)
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	buf := make([]byte, size)
+	enc, err := binencode.Encode(buf, encodingScheme...)
+	if err != nil {
+		panic(err) // cannot happen
+	}
+	h := mintcom.Hash(enc)
+	return idPrefix + hex.EncodeToString(h[:])
+}
+
+// redemption returns the redemption request which destroys the inputs of
+// request r.
+func (r *Request) redemption() *redeem.Request {
+	return &redeem.Request{
+		ID:     idPrefix + r.ID,
+		Payout: r.payout(),
+		Inputs: r.Inputs,
+	}
+}
+
+// order returns the (unsigned) issuance order which creates the outputs of
+// request r.
+func (r *Request) order() *issue.Order {
+	rd := r.redemption()
+	value, _ := rd.Value() // overflow is reported by Validate
+	return &issue.Order{
+		ID:       idPrefix + r.ID,
+		Currency: rd.Currency(),
+		Amount:   value,
+		Outputs:  r.Outputs,
+	}
+}
+
+// DBCs returns the DBCs created by request r, signed by the mints which
+// reissued the request with the given reissuances (one DBC per output, same
+// order). The reissuances must be from the same signing epoch.
+func (r *Request) DBCs(reissuances ...*Reissuance) ([]*dbc.DBC, error) {
+	var issuances []*issue.Issuance
+	for _, rs := range reissuances {
+		issuances = append(issuances, rs.Issuance)
+	}
+	return r.order().DBCs(issuances...)
+}
+
+// LoadRequest loads a reissue request from filename.
+func LoadRequest(filename string) (*Request, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var r Request
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// Save the request to filename (with mode 0600, because it contains bearer
+// certificates).
+func (r *Request) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(r)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0600)
+}
+
+// Reissuance is the reissue of a request by a mint.
+type Reissuance struct {
+	Receipt  *redeem.Receipt // receipt for the destroyed inputs
+	Issuance *issue.Issuance // signatures of the outputs
+}
+
+// Verify that reissuance rs is a valid reissuance for request r. The output
+// signatures are verified with the created DBCs (see Request.DBCs).
+func (rs *Reissuance) Verify(r *Request) error {
+	if rs.Receipt == nil || rs.Issuance == nil {
+		return fmt.Errorf("%w: incomplete", ErrReissuance)
+	}
+	if err := rs.Receipt.Verify(r.redemption()); err != nil {
+		return err
+	}
+	if rs.Issuance.OrderID != idPrefix+r.ID {
+		return fmt.Errorf("%w: issuance of order %s", ErrReissuance, rs.Issuance.OrderID)
+	}
+	if len(rs.Issuance.Signatures) != len(r.Outputs) {
+		return fmt.Errorf("%w: %d signatures for %d outputs", ErrReissuance,
+			len(rs.Issuance.Signatures), len(r.Outputs))
+	}
+	return nil
+}
+
+// LoadReissuance loads a reissuance from filename.
+func LoadReissuance(filename string) (*Reissuance, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var rs Reissuance
+	if err := json.Unmarshal(data, &rs); err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// Save the reissuance to filename.
+func (rs *Reissuance) Save(filename string) error {
+	jsn, err := netconf.MarshalCanonical(rs)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
+
+// Mint reissues DBCs for a mint of a federation.
+type Mint struct {
+	Federation  *netconf.Federation           // the federation of the mint
+	IdentityKey *netconf.IdentityKey          // identity key of the mint
+	PrivateKey  *[mintcom.PrivateKeySize]byte // private identity key, signs commitments
+	Signer      netconf.Signer                // knows private identity and signing keys
+	HomeDir     string                        // contains spendbook
+}
+
+// Reissue reissues request r at time t. The inputs are destroyed and the
+// outputs created like for redemptions and issuances, the destroyed and
+// created DBCs are recorded in the ledgers of the mint in the federation
+// directory dir.
+//
+// Reissuing the same request again completes an interrupted reissue, a
+// completed reissue fails with issue.ErrIssued.
+func (m *Mint) Reissue(dir string, r *Request, t time.Time) (*Reissuance, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	// check the outputs before the inputs are destroyed
+	net := m.Federation.Network
+	epoch, err := net.SigningEpochAt(t)
+	if err != nil {
+		return nil, err
+	}
+	o := r.order()
+	if err := o.Validate(net.DBCTypesAt(epoch)); err != nil {
+		return nil, err
+	}
+	rm := &redeem.Mint{
+		Federation:  m.Federation,
+		IdentityKey: m.IdentityKey,
+		PrivateKey:  m.PrivateKey,
+		Signer:      m.Signer,
+		HomeDir:     m.HomeDir,
+	}
+	rc, err := rm.Destroy(dir, r.redemption(), t)
+	if err != nil {
+		return nil, err
+	}
+	im := &issue.Mint{
+		Federation:  m.Federation,
+		IdentityKey: m.IdentityKey,
+		Signer:      m.Signer,
+	}
+	iss, err := im.Create(dir, o, t)
+	if err != nil {
+		return nil, err
+	}
+	return &Reissuance{Receipt: rc, Issuance: iss}, nil
+}
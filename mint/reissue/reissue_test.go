@@ -0,0 +1,172 @@
+package reissue
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/mint/issue"
+	"github.com/scritcash/scrit/mint/redeem"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/netconf/netconftest"
+)
+
+var (
+	oneEUR = netconf.DBCType{Currency: "EUR", Amount: 100000000}
+	twoEUR = netconf.DBCType{Currency: "EUR", Amount: 200000000}
+)
+
+// newDBC returns a new DBC of the given type signed by the first n mints of
+// federation f.
+func newDBC(t *testing.T, f *netconftest.Federation, dbcType netconf.DBCType, n int) *dbc.DBC {
+	d := &dbc.DBC{DBCType: dbcType, Message: []byte("dbc")}
+	for i := 0; i < n; i++ {
+		k := f.Mint(i).MintEpochs[0].SigningKeyAt(dbcType, f.At)
+		sig, err := f.Signers[i].Sign(k.PubKey, d.Message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Signatures = append(d.Signatures, dbc.Signature{
+			MintID:    f.MintID(i),
+			PubKey:    k.PubKey,
+			Signature: sig,
+			Time:      f.At,
+		})
+	}
+	return d
+}
+
+// mint returns the i-th mint of federation f.
+func mint(t *testing.T, f *netconftest.Federation, i int) *Mint {
+	return &Mint{
+		Federation:  f.Federation,
+		IdentityKey: f.IdentityKeys[i],
+		PrivateKey:  f.PrivateKeys[i],
+		Signer:      f.Signers[i],
+		HomeDir:     t.TempDir(),
+	}
+}
+
+func TestReissueRecovery(t *testing.T) {
+	f := netconftest.NewFederation(t, oneEUR, twoEUR)
+	d := newDBC(t, f, twoEUR, 2)
+	// the signing keys of the first mint are compromised after the DBC has
+	// been signed
+	compromised := f.At.Add(time.Minute)
+	rev, err := netconf.NewRevocation(f.IdentityKeys[0], nil, compromised,
+		compromised.Add(time.Hour), "identity key leaked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Revocations = map[string][]*netconf.Revocation{f.MintID(0): {rev}}
+	at := compromised.Add(time.Minute)
+	if a, err := d.Verify(f.Federation, at); err != nil || a != netconf.AcceptRecovery {
+		t.Fatalf("Verify() == %d, %v", a, err)
+	}
+
+	dir := t.TempDir()
+	// the DBC cannot be redeemed
+	rd, err := redeem.NewRequest("redeem1", "DE00 1234", []*dbc.DBC{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mint(t, f, 1)
+	rm := &redeem.Mint{
+		Federation:  m.Federation,
+		IdentityKey: m.IdentityKey,
+		PrivateKey:  m.PrivateKey,
+		Signer:      m.Signer,
+		HomeDir:     m.HomeDir,
+	}
+	if _, err := rm.Redeem(dir, rd, at); !errors.Is(err, redeem.ErrRequest) {
+		t.Errorf("Redeem() should fail with redeem.ErrRequest, got: %v", err)
+	}
+
+	// but it can be reissued by the other mints
+	r, err := NewRequest("reissue1", []*dbc.DBC{d}, []issue.Output{
+		{DBCType: oneEUR, Blinded: []byte("output 1")},
+		{DBCType: oneEUR, Blinded: []byte("output 2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, DefRequestFile)
+	if err := r.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	r, err = LoadRequest(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mint(t, f, 0).Reissue(dir, r, at); !errors.Is(err, netconf.ErrRevoked) {
+		t.Errorf("Reissue() should fail with netconf.ErrRevoked, got: %v", err)
+	}
+	var reissuances []*Reissuance
+	for _, i := range []int{1, 2} {
+		m := mint(t, f, i)
+		rs, err := m.Reissue(dir, r, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, DefReissuanceFile)
+		if err := rs.Save(filename); err != nil {
+			t.Fatal(err)
+		}
+		rs, err = LoadReissuance(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := rs.Verify(r); err != nil {
+			t.Error(err)
+		}
+		// a completed reissue is not repeated
+		if _, err := m.Reissue(dir, r, at); !errors.Is(err, issue.ErrIssued) {
+			t.Errorf("Reissue() should fail with issue.ErrIssued, got: %v", err)
+		}
+		reissuances = append(reissuances, rs)
+	}
+	dbcs, err := r.DBCs(reissuances...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range dbcs {
+		if a, err := d.Verify(f.Federation, at); err != nil || a != netconf.Accept {
+			t.Errorf("Verify() == %d, %v", a, err)
+		}
+	}
+
+	// destroyed and created DBCs are recorded in the ledgers
+	for _, kind := range []string{netconf.LedgerDestroyed, netconf.LedgerCreate} {
+		ledgers, err := netconf.LoadLedgers(filepath.Join(dir, netconf.LedgerFilename(0, kind)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ledgers) != 2 {
+			t.Errorf("%d %s ledgers instead of 2", len(ledgers), kind)
+		}
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	f := netconftest.NewFederation(t, oneEUR, twoEUR)
+	d := newDBC(t, f, twoEUR, 2)
+	outputs := []issue.Output{{DBCType: oneEUR, Blinded: []byte("output")}}
+	if _, err := NewRequest("reissue1", []*dbc.DBC{d}, outputs); !errors.Is(err, issue.ErrOrder) {
+		t.Errorf("NewRequest() should fail with issue.ErrOrder, got: %v", err)
+	}
+	// outputs of an undefined DBC type
+	halfEUR := netconf.DBCType{Currency: "EUR", Amount: 50000000}
+	var halves []issue.Output
+	for i := 0; i < 4; i++ {
+		halves = append(halves, issue.Output{DBCType: halfEUR, Blinded: []byte{byte(i)}})
+	}
+	r, err := NewRequest("reissue2", []*dbc.DBC{d}, halves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mint(t, f, 1).Reissue(t.TempDir(), r, f.At); !errors.Is(err, netconf.ErrDBCTypeNotDefined) {
+		t.Errorf("Reissue() should fail with netconf.ErrDBCTypeNotDefined, got: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package signer
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/scritcash/scrit/netconf"
+)
+
+// Client is a netconf.Signer which sends signing requests to a signer server
+// over a Unix domain socket.
+type Client struct {
+	mu   sync.Mutex // serializes requests
+	conn net.Conn
+	enc  *json.Encoder
+	dec  *json.Decoder
+}
+
+// Dial connects to the signer server listening on the Unix domain socket
+// socket.
+func Dial(socket string) (*Client, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		conn: conn,
+		enc:  json.NewEncoder(conn),
+		dec:  json.NewDecoder(conn),
+	}, nil
+}
+
+// Sign msg with the private key corresponding to pubKey on the signer server.
+func (c *Client) Sign(pubKey, msg []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.enc.Encode(&Request{PubKey: pubKey, Message: msg}); err != nil {
+		return nil, err
+	}
+	var resp Response
+	if err := c.dec.Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error == netconf.ErrPrivKeyMissing.Error() {
+		return nil, netconf.ErrPrivKeyMissing
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.Signature, nil
+}
+
+// Close the connection to the signer server.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package signer
+
+import (
+	"net"
+	"os"
+)
+
+// listen on socket and restrict it to the owner right after it has been
+// bound. The process-wide umask is left alone, therefore the socket should be
+// kept in a directory only accessible by the owner (like the mint home
+// directory) to avoid a window in which others can connect.
+func listen(socket string) (net.Listener, error) {
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
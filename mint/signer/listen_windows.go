@@ -0,0 +1,12 @@
+package signer
+
+import (
+	"net"
+)
+
+// There is no umask on Windows, the access to the socket is controlled by
+// the permissions of its directory.
+
+func listen(socket string) (net.Listener, error) {
+	return net.Listen("unix", socket)
+}
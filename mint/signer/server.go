@@ -0,0 +1,118 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/netconf"
+)
+
+// Server serves signing requests for a netconf.Signer.
+type Server struct {
+	signer netconf.Signer
+	policy Policy
+	mu     sync.Mutex // protects audit
+	audit  io.Writer
+}
+
+// NewServer returns a new server for the signer s which only serves signing
+// requests allowed by policy p and writes an audit log line for every
+// signing request to audit.
+func NewServer(s netconf.Signer, p Policy, audit io.Writer) *Server {
+	return &Server{signer: s, policy: p, audit: audit}
+}
+
+// Listen listens on the Unix domain socket socket, which is only accessible
+// by the owner. A socket left behind by a crashed server is removed, if no
+// server is listening on it anymore.
+func Listen(socket string) (net.Listener, error) {
+	fi, err := os.Lstat(socket)
+	if err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("signer: '%s' exists and is not a socket", socket)
+		}
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%w: %s", ErrListening, socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return listen(socket)
+}
+
+// Serve accepts connections on l and serves signing requests on them until l
+// is closed.
+func (s *Server) Serve(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn serves signing requests on conn, one JSON object per line.
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
+	for {
+		var req Request
+		if err := dec.Decode(&req); err != nil {
+			if err != io.EOF {
+				log.Printf("signer: %s", err)
+			}
+			return
+		}
+		var resp Response
+		var sig []byte
+		err := s.policy.Allow(req.PubKey, time.Now())
+		if err == nil {
+			sig, err = s.signer.Sign(req.PubKey, req.Message)
+		}
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Signature = sig
+		}
+		if err := s.log(&req, err); err != nil {
+			log.Printf("signer: cannot write audit log: %s", err)
+			return
+		}
+		if err := enc.Encode(&resp); err != nil {
+			log.Printf("signer: %s", err)
+			return
+		}
+	}
+}
+
+// log writes an audit log line for request req with result err.
+func (s *Server) log(req *Request, err error) error {
+	result := "ok"
+	if err != nil {
+		result = "error: " + err.Error()
+	}
+	h := sha256.Sum256(req.Message)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = fmt.Fprintf(s.audit, "%s %s %s %s\n",
+		time.Now().UTC().Format(time.RFC3339),
+		base64.RawURLEncoding.EncodeToString(req.PubKey),
+		hex.EncodeToString(h[:]), result)
+	return err
+}
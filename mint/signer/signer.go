@@ -0,0 +1,182 @@
+// Package signer implements signers for mint private keys.
+//
+// The file backend loads the private identity key and the private key list
+// of a mint from disk. The server makes any netconf.Signer available to other
+// processes over a Unix domain socket, restricted by a Policy, and writes an
+// audit log of all signing requests. The client implements netconf.Signer on
+// top of such a socket. That way the DBC signing keys can be kept in a
+// separate process.
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/scritcash/scrit/mint/identity"
+	"github.com/scritcash/scrit/netconf"
+)
+
+// DefSocketFile defines the default name of the signer socket in the mint
+// home directory.
+const DefSocketFile = "signer.sock"
+
+// DefAuditFile defines the default name of the signer audit log in the mint
+// home directory.
+const DefAuditFile = "signer.log"
+
+// ErrNotAllowed is returned if the signer server doesn't sign with a key.
+var ErrNotAllowed = errors.New("signer: signing with key not allowed")
+
+// ErrListening is returned if another signer server is listening on a socket
+// already.
+var ErrListening = errors.New("signer: server listening on socket already")
+
+// Policy decides which signing requests the signer server serves.
+type Policy interface {
+	// Allow returns an error, if the server must not sign with the private
+	// key corresponding to pubKey at time t.
+	Allow(pubKey []byte, t time.Time) error
+}
+
+// Request defines a signing request sent to the signer server.
+type Request struct {
+	PubKey  []byte // public key of the private key to sign with
+	Message []byte // message to sign
+}
+
+// Response defines the response of the signer server to a Request.
+type Response struct {
+	Signature []byte `json:",omitempty"` // signature, if successful
+	Error     string `json:",omitempty"` // error message, if not successful
+}
+
+// Chain is a netconf.Signer which signs with the first signer in the chain
+// which knows the private key.
+type Chain []netconf.Signer
+
+// Sign msg with the private key corresponding to pubKey.
+func (c Chain) Sign(pubKey, msg []byte) ([]byte, error) {
+	for _, s := range c {
+		sig, err := s.Sign(pubKey, msg)
+		if err != netconf.ErrPrivKeyMissing {
+			return sig, err
+		}
+	}
+	return nil, netconf.ErrPrivKeyMissing
+}
+
+// File is a signer for the private keys contained in the private key list of
+// a mint on disk.
+type File struct {
+	*netconf.KeySigner
+	mu       sync.Mutex
+	ik       *netconf.IdentityKey
+	mint     *netconf.Mint // protected by mu
+	filename string
+}
+
+// LoadFile loads the private identity key from secKey (see identity.Load)
+// and the corresponding private key list from homeDir and returns a signer
+// for all contained private keys.
+func LoadFile(homeDir, secKey string) (*File, error) {
+	sec, _, _, err := identity.Load(homeDir, secKey)
+	if err != nil {
+		return nil, err
+	}
+	ik := netconf.NewIdentityKeyEd25519Priv(sec)
+	privFilename := filepath.Join(homeDir, netconf.DefPrivKeyListDir,
+		ik.MarshalID()+".json")
+	mint, err := netconf.LoadPrivKeyList(privFilename, ik)
+	if err != nil {
+		return nil, err
+	}
+	return &File{
+		KeySigner: netconf.NewMintSigner(ik, mint),
+		ik:        ik,
+		mint:      mint,
+		filename:  privFilename,
+	}, nil
+}
+
+// IdentityKey returns the identity key of the mint.
+func (f *File) IdentityKey() *netconf.IdentityKey {
+	return f.ik
+}
+
+// Allow signing with the private key corresponding to pubKey at time t, if
+// it is a DBC signing key of the mint and t lies in its signing window. The
+// identity key is never allowed, it must not be exposed to other processes.
+func (f *File) Allow(pubKey []byte, t time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, e := range f.mint.MintEpochs {
+		for _, k := range e.KeyList {
+			if !bytes.Equal(k.PubKey, pubKey) {
+				continue
+			}
+			start, end := k.Window(e)
+			if t.Before(start) || !t.Before(end) {
+				return fmt.Errorf("%w: outside of signing window", ErrNotAllowed)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: not a DBC signing key", ErrNotAllowed)
+}
+
+// Prune erases all private keys whose validation period has ended at time t
+// from the signer and from the private key list on disk (see
+// netconf.Mint.PruneExpired). It returns the number of private keys erased on
+// disk. The private key list is reloaded, because it might have been extended
+// or pruned in the meantime: expired keys are removed from the signer, even if
+// they have been erased on disk already, and the keys of new epochs are added.
+// The private key list keeps its format (encrypted or plaintext).
+func (f *File) Prune(t time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	lock, err := netconf.LockFile(f.filename)
+	if err != nil {
+		return 0, err
+	}
+	defer lock.Unlock()
+	encrypted, err := netconf.IsEncryptedKeyList(f.filename)
+	if err != nil {
+		return 0, err
+	}
+	mint, err := netconf.LoadPrivKeyList(f.filename, f.ik)
+	if err != nil {
+		return 0, err
+	}
+	// update signer
+	for _, e := range mint.MintEpochs {
+		expired := !t.Before(e.ValidateEnd)
+		for _, k := range e.KeyList {
+			if expired {
+				f.Remove(k.PubKey)
+			} else {
+				f.Add(k.PrivKey)
+			}
+		}
+	}
+	f.mint = mint
+	n := mint.PruneExpired(t)
+	if n == 0 {
+		return 0, nil
+	}
+	if err := lock.Check(); err != nil {
+		return 0, err
+	}
+	if encrypted {
+		err = mint.SavePrivKeyList(f.filename, f.ik)
+	} else {
+		err = mint.Save(f.filename, 0600)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
@@ -0,0 +1,300 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/netconf"
+)
+
+// lockedBuffer is a bytes.Buffer which is safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// allowKeys allows signing with the contained public keys.
+type allowKeys map[string]bool
+
+func (a allowKeys) Allow(pubKey []byte, t time.Time) error {
+	if !a[string(pubKey)] {
+		return ErrNotAllowed
+	}
+	return nil
+}
+
+func TestClientServer(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	denied, deniedPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := netconf.NewKeySigner()
+	ks.Add(priv)
+	ks.Add(deniedPriv)
+
+	socket := filepath.Join(t.TempDir(), DefSocketFile)
+	l, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var audit lockedBuffer
+	p := allowKeys{string(pub): true, string(other): true}
+	go NewServer(ks, p, &audit).Serve(l)
+
+	c, err := Dial(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	msg := []byte("message")
+	sig, err := c.Sign(pub, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, msg, sig) {
+		t.Error("signature doesn't verify")
+	}
+	if _, err := c.Sign(other, msg); !errors.Is(err, netconf.ErrPrivKeyMissing) {
+		t.Errorf("Sign() should fail with ErrPrivKeyMissing, got: %v", err)
+	}
+	if _, err := c.Sign(denied, msg); err == nil {
+		t.Error("Sign() with key not allowed should fail")
+	}
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("audit log should have 3 lines, has %d", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], " ok") {
+		t.Errorf("unexpected audit log line: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], " error: ") {
+		t.Errorf("unexpected audit log line: %s", lines[1])
+	}
+}
+
+func TestChain(t *testing.T) {
+	idPub, idPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := netconf.NewKeySigner()
+	local.Add(idPriv)
+	remote := netconf.NewKeySigner()
+	remote.Add(priv)
+
+	socket := filepath.Join(t.TempDir(), DefSocketFile)
+	l, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go NewServer(remote, allowKeys{string(pub): true}, ioutil.Discard).Serve(l)
+	c, err := Dial(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	s := Chain{local, c}
+	msg := []byte("message")
+	for _, k := range []ed25519.PublicKey{idPub, pub} {
+		sig, err := s.Sign(k, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519.Verify(k, msg, sig) {
+			t.Error("signature doesn't verify")
+		}
+	}
+	// the server does not sign with the identity key
+	if _, err := (Chain{c}).Sign(idPub, msg); err == nil {
+		t.Error("Sign() with identity key on server should fail")
+	}
+	if _, err := (Chain{local}).Sign(pub, msg); !errors.Is(err, netconf.ErrPrivKeyMissing) {
+		t.Errorf("Sign() should fail with ErrPrivKeyMissing, got: %v", err)
+	}
+}
+
+func TestListen(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), DefSocketFile)
+	l, err := Listen(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := fi.Mode().Perm(); perm != 0600 {
+		t.Errorf("socket has mode %o instead of 0600", perm)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	// socket in use
+	if _, err := Listen(socket); !errors.Is(err, ErrListening) {
+		t.Errorf("Listen() should fail with ErrListening, got: %v", err)
+	}
+	// stale socket
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	if _, err := os.Stat(socket); err != nil {
+		t.Fatal(err)
+	}
+	l, err = Listen(socket)
+	if err != nil {
+		t.Fatalf("Listen() on stale socket failed: %v", err)
+	}
+	l.Close()
+}
+
+func TestFileAllow(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sk, err := netconf.NewSigningKey("EUR", 100000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &File{mint: &netconf.Mint{MintEpochs: []*netconf.MintEpoch{{
+		SignStart:   start,
+		SignEnd:     start.Add(24 * time.Hour),
+		ValidateEnd: start.Add(48 * time.Hour),
+		KeyList:     []*netconf.SigningKey{sk},
+	}}}}
+	if err := f.Allow(sk.PubKey, start); err != nil {
+		t.Error(err)
+	}
+	for _, at := range []time.Time{start.Add(-time.Second), start.Add(24 * time.Hour)} {
+		if err := f.Allow(sk.PubKey, at); !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("Allow() at %s should fail with ErrNotAllowed, got: %v", at, err)
+		}
+	}
+	ik, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Allow(ik.PubKey, start); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Allow() for identity key should fail with ErrNotAllowed, got: %v", err)
+	}
+}
+
+func TestFilePrune(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ik, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch := func(i int) *netconf.MintEpoch {
+		sk, err := netconf.NewSigningKey("EUR", 100000000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := start.Add(time.Duration(i) * 24 * time.Hour)
+		return &netconf.MintEpoch{
+			SignStart:   s,
+			SignEnd:     s.Add(24 * time.Hour),
+			ValidateEnd: s.Add(48 * time.Hour),
+			KeyList:     []*netconf.SigningKey{sk},
+		}
+	}
+	m := &netconf.Mint{
+		MintIdentityKey: netconf.IdentityKey{SigAlgo: ik.SigAlgo, PubKey: ik.PubKey},
+		MintEpochs:      []*netconf.MintEpoch{epoch(0), epoch(1)},
+	}
+	expired := m.MintEpochs[0].KeyList[0].PubKey
+	filename := filepath.Join(t.TempDir(), ik.MarshalID()+".json")
+	if err := m.Save(filename, 0600); err != nil { // plaintext list
+		t.Fatal(err)
+	}
+	f := &File{
+		KeySigner: netconf.NewMintSigner(ik, m),
+		ik:        ik,
+		mint:      m,
+		filename:  filename,
+	}
+
+	// prune and extend the private key list behind the signer's back
+	at := start.Add(48 * time.Hour)
+	m2, err := netconf.LoadPrivKeyList(filename, ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := m2.PruneExpired(at); n != 1 {
+		t.Fatalf("PruneExpired() = %d != 1", n)
+	}
+	m2.MintEpochs = append(m2.MintEpochs, epoch(2))
+	added := m2.MintEpochs[2].KeyList[0].PubKey
+	if err := m2.Save(filename, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := f.Prune(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("Prune() = %d != 0", n)
+	}
+	msg := []byte("message")
+	if _, err := f.Sign(expired, msg); !errors.Is(err, netconf.ErrPrivKeyMissing) {
+		t.Errorf("Sign() with expired key should fail with ErrPrivKeyMissing, got: %v", err)
+	}
+	if err := f.Allow(added, m2.MintEpochs[2].SignStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Sign(added, msg); err != nil {
+		t.Errorf("Sign() with key of new epoch failed: %v", err)
+	}
+
+	// pruning keeps the plaintext format
+	if n, err := f.Prune(start.Add(72 * time.Hour)); err != nil || n != 1 {
+		t.Fatalf("Prune() = %d, %v", n, err)
+	}
+	encrypted, err := netconf.IsEncryptedKeyList(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted {
+		t.Error("Prune() encrypted plaintext private key list")
+	}
+}
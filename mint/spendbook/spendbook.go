@@ -0,0 +1,158 @@
+// Package spendbook implements the spendbook of a mint.
+//
+// The spendbook records the commitments (see package mintcom) of all DBCs
+// spent at the mint, indexed by the hash of the hash of their input (HHI),
+// together with the signing epoch and the DBC types of input and output.
+// A DBC which is contained in the spendbook cannot be spent again. The
+// spendbook is kept in its own directory in the mint home directory, which is
+// locked while the spendbook is open. For audits it is exported as
+// netconf.CommitmentLog.
+package spendbook
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+	"github.com/scritcash/scrit/util/flock"
+)
+
+// DefSpendbookDir defines the default name of the spendbook directory in the
+// mint home directory.
+const DefSpendbookDir = "spendbook"
+
+// DefSpendbookFile defines the default name of the spendbook file in the
+// spendbook directory.
+const DefSpendbookFile = "spendbook.json"
+
+// ErrSpent is returned if a DBC has been spent already.
+var ErrSpent = errors.New("spendbook: DBC spent already")
+
+// Spendbook is an open spendbook.
+type Spendbook struct {
+	lock     *flock.Lock
+	filename string
+	entries  map[string]*netconf.CommitmentLogEntry // by hex(HHI)
+}
+
+// Open opens the spendbook in the mint home directory homeDir (it is created,
+// if it doesn't exist). It blocks until the spendbook directory is locked.
+// The spendbook must be closed with Close.
+func Open(homeDir string) (*Spendbook, error) {
+	dir := filepath.Join(homeDir, DefSpendbookDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	l, err := flock.LockDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sb := &Spendbook{
+		lock:     l,
+		filename: filepath.Join(dir, DefSpendbookFile),
+		entries:  make(map[string]*netconf.CommitmentLogEntry),
+	}
+	if err := sb.load(); err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return sb, nil
+}
+
+// load the spendbook file, if it exists.
+func (sb *Spendbook) load() error {
+	if _, err := atomicfile.Recover(sb.filename, json.Valid); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(sb.filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // empty spendbook
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, &sb.entries); err != nil {
+		return fmt.Errorf("spendbook: '%s': %w", sb.filename, err)
+	}
+	return nil
+}
+
+// Len returns the number of spent DBCs.
+func (sb *Spendbook) Len() int {
+	return len(sb.entries)
+}
+
+// Lookup returns the commitment for the DBC input whose hash hashes to hhi,
+// or nil, if the DBC has not been spent.
+func (sb *Spendbook) Lookup(hhi [mintcom.HashSize]byte) *mintcom.Commitment {
+	e, ok := sb.entries[hex.EncodeToString(hhi[:])]
+	if !ok {
+		return nil
+	}
+	return new(mintcom.Commitment).Unmarshal(e.Commitment)
+}
+
+// Add the commitment com for a DBC of type input spent in the given signing
+// epoch to the spendbook, output is the DBC type of the output (the zero
+// DBCType for redemptions). If the input of com has been spent already, an
+// error wrapping ErrSpent is returned. The spendbook is only changed on disk
+// by Save.
+func (sb *Spendbook) Add(
+	com *mintcom.Commitment,
+	epoch int,
+	input, output netconf.DBCType,
+) error {
+	key := hex.EncodeToString(com.HHI[:])
+	if _, ok := sb.entries[key]; ok {
+		return fmt.Errorf("%w: %s", ErrSpent, key)
+	}
+	sb.entries[key] = &netconf.CommitmentLogEntry{
+		Commitment: append([]byte(nil), com.Marshal()...),
+		Epoch:      epoch,
+		Input:      input,
+		Output:     output,
+	}
+	return nil
+}
+
+// Export the spendbook as commitment log of the mint with identity key ik,
+// signed with s (which must know the private identity key). The entries are
+// sorted by HHI.
+func (sb *Spendbook) Export(ik *netconf.IdentityKey, s netconf.Signer) (*netconf.CommitmentLog, error) {
+	var keys []string
+	for k := range sb.entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	entries := make([]netconf.CommitmentLogEntry, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, *sb.entries[k])
+	}
+	l := netconf.NewCommitmentLog(ik, entries)
+	if err := l.SignWith(s); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Save the spendbook to disk.
+func (sb *Spendbook) Save() error {
+	jsn, err := netconf.MarshalCanonical(sb.entries)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(sb.filename, jsn, 0600)
+}
+
+// Close the spendbook, unsaved additions are lost.
+func (sb *Spendbook) Close() error {
+	return sb.lock.Unlock()
+}
@@ -0,0 +1,123 @@
+package spendbook
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/netconf"
+)
+
+var oneEUR = netconf.DBCType{Currency: "EUR", Amount: 100000000}
+
+func testRedemption(t *testing.T, input string) *mintcom.Commitment {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testRedemptionWith(t, priv, input)
+}
+
+func testRedemptionWith(t *testing.T, priv ed25519.PrivateKey, input string) *mintcom.Commitment {
+	pub := priv.Public().(ed25519.PublicKey)
+	var pubKey [mintcom.PublicKeySize]byte
+	var privKey [mintcom.PrivateKeySize]byte
+	copy(pubKey[:], pub)
+	copy(privKey[:], priv)
+	com, err := mintcom.NewRedemption(mintcom.KeyID(pub), []byte(input),
+		[]byte("proof"), &pubKey, &privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return com
+}
+
+func TestSpendbook(t *testing.T) {
+	homeDir := t.TempDir()
+	sb, err := Open(homeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	com := testRedemption(t, "input 1")
+	if sb.Lookup(com.HHI) != nil {
+		t.Error("DBC spent in empty spendbook")
+	}
+	if err := sb.Add(com, 0, oneEUR, netconf.DBCType{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.Add(testRedemption(t, "input 1"), 0, oneEUR, netconf.DBCType{}); !errors.Is(err, ErrSpent) {
+		t.Errorf("Add() should fail with ErrSpent, got: %v", err)
+	}
+	if err := sb.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.Add(testRedemption(t, "input 2"), 0, oneEUR, netconf.DBCType{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sb.Close(); err != nil { // input 2 is not saved
+		t.Fatal(err)
+	}
+
+	// reopen
+	sb, err = Open(homeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+	if sb.Len() != 1 {
+		t.Fatalf("spendbook has %d entries instead of 1", sb.Len())
+	}
+	c := sb.Lookup(com.HHI)
+	if c == nil {
+		t.Fatal("spent DBC not found")
+	}
+	if _, ok := c.Matches(com); !ok {
+		t.Error("commitments do not match")
+	}
+	if !c.IsRedemption() {
+		t.Error("commitment is not a redemption")
+	}
+}
+
+func TestExport(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var privKey [64]byte
+	copy(privKey[:], priv)
+	ik := netconf.NewIdentityKeyEd25519Priv(&privKey)
+	s := netconf.NewKeySigner()
+	s.AddIdentityKey(ik)
+	sb, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+	for _, input := range []string{"input 1", "input 2"} {
+		err := sb.Add(testRedemptionWith(t, priv, input), 0, oneEUR, netconf.DBCType{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := sb.Export(ik, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Entries) != 2 {
+		t.Fatalf("%d log entries instead of 2", len(l.Entries))
+	}
+	filename := filepath.Join(t.TempDir(), netconf.DefCommitmentLogFile)
+	if err := l.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	l, err = netconf.LoadCommitmentLog(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Verify(); err != nil {
+		t.Error(err)
+	}
+}
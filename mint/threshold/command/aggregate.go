@@ -0,0 +1,80 @@
+package command
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/frost"
+	"github.com/scritcash/scrit/mint/threshold"
+)
+
+func aggregate(dir string, msg []byte) error {
+	var pub frost.PublicKey
+	if err := threshold.ReadJSON(filepath.Join(dir, threshold.DefPublicKeyFile), &pub); err != nil {
+		return err
+	}
+	commitments, err := readCommitments(dir)
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, threshold.SignatureShareGlob))
+	if err != nil {
+		return err
+	}
+	var shares []*frost.SignatureShare
+	for _, filename := range files {
+		var share frost.SignatureShare
+		if err := threshold.ReadJSON(filename, &share); err != nil {
+			return err
+		}
+		shares = append(shares, &share)
+	}
+	sig, err := frost.Aggregate(&pub, msg, commitments, shares)
+	if err != nil {
+		return err
+	}
+	ik, err := threshold.IdentityKey(&pub)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("signature by %s:\n", ik.MarshalID())
+	fmt.Println(base64.RawURLEncoding.EncodeToString(sig)) // this must be the last output line!
+	return nil
+}
+
+// Aggregate implements the scrit-mint 'threshold aggregate' command.
+func Aggregate(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -m message | -f file\n", argv0)
+		fmt.Fprintf(os.Stderr, "Aggregate signature shares into Ed25519 signature.\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	msg := fs.String("m", "", "Signed message")
+	msgFile := fs.String("f", "", "File containing signed message")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	m, err := readMessage(*msg, *msgFile)
+	if err != nil {
+		return err
+	}
+	return aggregate(*dir, m)
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func boxKey(homeDir string) error {
+	tdir, err := threshold.Dir(homeDir)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(tdir, threshold.DefBoxKeyFile)
+	exists, err := file.Exists(filename)
+	if err != nil {
+		return err
+	}
+	var pub *[32]byte
+	if exists {
+		pub, err = threshold.ReadBoxKey(filename)
+	} else {
+		pub, err = threshold.NewBoxKey(filename)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("box key stored in '%s':\n", filename)
+	fmt.Println(netconf.MarshalBoxKey(pub)) // this must be the last output line!
+	return nil
+}
+
+// BoxKey implements the scrit-mint 'threshold boxkey' command.
+func BoxKey(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Create box key the private key lists are sealed to (if it doesn't exist) and show it.\n")
+		fs.PrintDefaults()
+	}
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return boxKey(homedir.ScritMint())
+}
@@ -0,0 +1,48 @@
+// Package command implements the scrit-mint threshold commands.
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/scritcash/scrit/frost"
+	"github.com/scritcash/scrit/mint/threshold"
+)
+
+// readMessage returns the message to sign, either given directly as msg or
+// contained in the file msgFile.
+func readMessage(msg, msgFile string) ([]byte, error) {
+	if msg != "" && msgFile != "" {
+		return nil, errors.New("options -m and -f exclude each other")
+	}
+	if msgFile != "" {
+		return ioutil.ReadFile(msgFile)
+	}
+	if msg == "" {
+		return nil, errors.New("option -m or -f is mandatory")
+	}
+	return []byte(msg), nil
+}
+
+// readCommitments reads all signing commitments from the ceremony directory.
+func readCommitments(dir string) ([]*frost.Commitment, error) {
+	files, err := filepath.Glob(filepath.Join(dir, threshold.CommitmentGlob))
+	if err != nil {
+		return nil, err
+	}
+	var commitments []*frost.Commitment
+	for _, filename := range files {
+		var c frost.Commitment
+		if err := threshold.ReadJSON(filename, &c); err != nil {
+			return nil, err
+		}
+		if filepath.Base(filename) != threshold.CommitmentFile(c.ID) {
+			return nil, fmt.Errorf("file '%s' contains commitment of participant %d",
+				filename, c.ID)
+		}
+		commitments = append(commitments, &c)
+	}
+	return commitments, nil
+}
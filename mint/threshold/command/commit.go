@@ -0,0 +1,62 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/frost"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func commit(homeDir, dir string) error {
+	tdir := filepath.Join(homeDir, threshold.DefThresholdDir)
+	ks, err := threshold.LoadKeyShare(filepath.Join(tdir, threshold.DefKeyShareFile))
+	if err != nil {
+		return err
+	}
+	nonce, c, err := frost.Commit(ks)
+	if err != nil {
+		return err
+	}
+	// WriteJSON makes sure we do not overwrite an unused nonce
+	if err := threshold.WriteJSON(filepath.Join(tdir, threshold.DefNonceFile), nonce, 0600); err != nil {
+		return err
+	}
+	filename := filepath.Join(dir, threshold.CommitmentFile(ks.ID))
+	if err := threshold.WriteJSON(filename, c, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("send '%s' to all other signers\n", filename)
+	return nil
+}
+
+// Commit implements the scrit-mint 'threshold commit' command.
+func Commit(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Commit to nonce for threshold signing ceremony (round 1).\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return commit(homedir.ScritMint(), *dir)
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func dkgFinish(homeDir, dir string) error {
+	state, round1, err := loadDKG(homeDir, dir)
+	if err != nil {
+		return err
+	}
+	id := state.Secret.ID
+	var (
+		shares    []*threshold.SealedShare
+		filenames []string
+	)
+	for from := 1; from <= state.Secret.N; from++ {
+		if from == id {
+			continue
+		}
+		var sh threshold.SealedShare
+		filename := filepath.Join(dir, threshold.Round2File(from, id))
+		if err := threshold.ReadJSON(filename, &sh); err != nil {
+			return err
+		}
+		if sh.From != from {
+			return fmt.Errorf("file '%s' contains share of participant %d", filename, sh.From)
+		}
+		shares = append(shares, &sh)
+		filenames = append(filenames, filename)
+	}
+	ks, err := state.Finalize(round1, shares)
+	if err != nil {
+		return err
+	}
+	ik, err := threshold.IdentityKey(ks.Public)
+	if err != nil {
+		return err
+	}
+
+	// save key share and remove secret DKG state
+	tdir := filepath.Join(homeDir, threshold.DefThresholdDir)
+	if err := threshold.SaveKeyShare(filepath.Join(tdir, threshold.DefKeyShareFile), ks); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(tdir, threshold.DefDKGStateFile)); err != nil {
+		return err
+	}
+	// remove the secret shares for us from the ceremony directory
+	for _, filename := range filenames {
+		if err := os.Remove(filename); err != nil {
+			return err
+		}
+	}
+	// write public key (all participants write the same)
+	pubFilename := filepath.Join(dir, threshold.DefPublicKeyFile)
+	if err := os.Remove(pubFilename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := threshold.WriteJSON(pubFilename, ks.Public, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("%d-of-%d threshold identity key:\n", ks.Public.Threshold, ks.Public.N)
+	fmt.Println(ik.MarshalID()) // this must be the last output line!
+	return nil
+}
+
+// DKGFinish implements the scrit-mint 'threshold dkg-finish' command.
+func DKGFinish(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Verify secret shares and store key share (finish distributed key generation).\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return dkgFinish(homedir.ScritMint(), *dir)
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+// loadDKG loads the secret DKG state from homeDir and the round 1 files of
+// all participants from dir.
+func loadDKG(homeDir, dir string) (*threshold.DKGState, []*threshold.Round1, error) {
+	filename := filepath.Join(homeDir, threshold.DefThresholdDir, threshold.DefDKGStateFile)
+	state, err := threshold.LoadDKGState(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	var round1 []*threshold.Round1
+	for id := 1; id <= state.Secret.N; id++ {
+		var r1 threshold.Round1
+		if err := threshold.ReadJSON(filepath.Join(dir, threshold.Round1File(id)), &r1); err != nil {
+			return nil, nil, err
+		}
+		if r1.ID != id {
+			return nil, nil, fmt.Errorf("file '%s' contains package of participant %d",
+				threshold.Round1File(id), r1.ID)
+		}
+		round1 = append(round1, &r1)
+	}
+	return state, round1, nil
+}
+
+func dkgShare(homeDir, dir string) error {
+	state, round1, err := loadDKG(homeDir, dir)
+	if err != nil {
+		return err
+	}
+	shares, err := state.SealShares(round1)
+	if err != nil {
+		return err
+	}
+	for _, sh := range shares {
+		// the secret share is sealed to the receiver
+		filename := filepath.Join(dir, threshold.Round2File(sh.From, sh.To))
+		if err := threshold.WriteJSON(filename, sh, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("send '%s' to participant %d\n", filename, sh.To)
+	}
+	return nil
+}
+
+// DKGShare implements the scrit-mint 'threshold dkg-share' command.
+func DKGShare(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", argv0)
+		fmt.Fprintf(os.Stderr, "Verify round 1 of distributed key generation and create secret shares (round 2).\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return dkgShare(homedir.ScritMint(), *dir)
+}
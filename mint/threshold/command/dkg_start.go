@@ -0,0 +1,68 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func dkgStart(homeDir, dir string, id, t, n int) error {
+	tdir, err := threshold.Dir(homeDir)
+	if err != nil {
+		return err
+	}
+	state, r1, err := threshold.NewDKG(id, t, n)
+	if err != nil {
+		return err
+	}
+	// save encrypted secret state
+	if err := state.Save(filepath.Join(tdir, threshold.DefDKGStateFile)); err != nil {
+		return err
+	}
+	// write public round 1 package
+	filename := filepath.Join(dir, threshold.Round1File(id))
+	if err := threshold.WriteJSON(filename, r1, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("send '%s' to all other participants\n", filename)
+	return nil
+}
+
+// DKGStart implements the scrit-mint 'threshold dkg-start' command.
+func DKGStart(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -id id -t threshold -n participants\n", argv0)
+		fmt.Fprintf(os.Stderr, "Start distributed generation of threshold identity key (round 1).\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	id := fs.Int("id", 0, "Identifier of participant (1 to n)")
+	t := fs.Int("t", 0, "Number of participants required to sign")
+	n := fs.Int("n", 0, "Total number of participants")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *id == 0 || *t == 0 || *n == 0 {
+		fmt.Fprintf(os.Stderr, "%s: options -id, -t, and -n are mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return dkgStart(homedir.ScritMint(), *dir, *id, *t, *n)
+}
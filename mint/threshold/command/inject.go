@@ -0,0 +1,65 @@
+package command
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	keylist "github.com/scritcash/scrit/mint/keylist/command"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func inject(reqFile, outFile string, signatures []string) error {
+	r, err := netconf.LoadKeyListRequest(reqFile)
+	if err != nil {
+		return err
+	}
+	var sigs [][]byte
+	for _, s := range signatures {
+		sig, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		sigs = append(sigs, sig)
+	}
+	resp, err := r.Response(sigs)
+	if err != nil {
+		return err
+	}
+	if err := threshold.WriteJSON(outFile, resp, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("add '%s' with 'scrit-mint keylist finalize'\n", outFile)
+	return nil
+}
+
+// Inject implements the scrit-mint 'threshold inject' command.
+func Inject(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-r request] signature [...]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Write key list response with aggregated signatures (one per payload, in order).\n")
+		fs.PrintDefaults()
+	}
+	reqFile := fs.String("r", keylist.DefKeyListRequestFile, "Key list request file")
+	outFile := fs.String("o", keylist.DefKeyListResponseFile, "Key list response file")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return inject(*reqFile, *outFile, fs.Args())
+}
@@ -0,0 +1,72 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/log"
+	keylist "github.com/scritcash/scrit/mint/keylist/command"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func payloads(dir, reqFile string) error {
+	r, err := netconf.LoadKeyListRequest(reqFile)
+	if err != nil {
+		return err
+	}
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	for i, p := range r.Payloads {
+		filename := filepath.Join(dir, threshold.PayloadFile(r.Start+i))
+		exists, err := file.Exists(filename)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("file '%s' exists already", filename)
+		}
+		if err := ioutil.WriteFile(filename, p, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("sign '%s' with 'scrit-mint threshold sign -f'\n", filename)
+	}
+	return nil
+}
+
+// Payloads implements the scrit-mint 'threshold payloads' command.
+func Payloads(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [request]\n", argv0)
+		fmt.Fprintf(os.Stderr, "Write payloads of key list request to ceremony directory (one per mint epoch).\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	reqFile := keylist.DefKeyListRequestFile
+	switch fs.NArg() {
+	case 0:
+	case 1:
+		reqFile = fs.Arg(0)
+	default:
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	return payloads(*dir, reqFile)
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/frost"
+	"github.com/scritcash/scrit/mint/threshold"
+	"github.com/scritcash/scrit/util/homedir"
+)
+
+func sign(homeDir, dir string, msg []byte) error {
+	tdir := filepath.Join(homeDir, threshold.DefThresholdDir)
+	ks, err := threshold.LoadKeyShare(filepath.Join(tdir, threshold.DefKeyShareFile))
+	if err != nil {
+		return err
+	}
+	var nonce frost.Nonce
+	nonceFilename := filepath.Join(tdir, threshold.DefNonceFile)
+	if err := threshold.ReadJSON(nonceFilename, &nonce); err != nil {
+		return err
+	}
+	commitments, err := readCommitments(dir)
+	if err != nil {
+		return err
+	}
+	// remove nonce before signing, it must never be used twice
+	if err := os.Remove(nonceFilename); err != nil {
+		return err
+	}
+	share, err := frost.Sign(ks, &nonce, msg, commitments)
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(dir, threshold.SignatureShareFile(ks.ID))
+	if err := threshold.WriteJSON(filename, share, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("send '%s' to aggregator\n", filename)
+	return nil
+}
+
+// Sign implements the scrit-mint 'threshold sign' command.
+func Sign(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s -m message | -f file\n", argv0)
+		fmt.Fprintf(os.Stderr, "Create signature share for threshold signing ceremony (round 2).\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("dir", ".", "Ceremony directory")
+	msg := fs.String("m", "", "Message to sign")
+	msgFile := fs.String("f", "", "File containing message to sign")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	m, err := readMessage(*msg, *msgFile)
+	if err != nil {
+		return err
+	}
+	return sign(homedir.ScritMint(), *dir, m)
+}
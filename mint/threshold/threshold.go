@@ -0,0 +1,496 @@
+// Package threshold defines helper functions for threshold mint identity keys
+// (see package frost). The key shares and the DKG state are stored encrypted
+// with a passphrase in the mint home directory, the ceremony files are
+// exchanged between the operators. Secret shares in ceremony files are sealed
+// to their receiver.
+package threshold
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/frankbraun/codechain/util/terminal"
+	"github.com/scritcash/scrit/frost"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/atomicfile"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// DefThresholdDir defines the default sub-directory for threshold key files
+// in the mint home directory.
+const DefThresholdDir = "threshold"
+
+// DefDKGStateFile defines the default file for the secret state of a running
+// distributed key generation.
+const DefDKGStateFile = "dkg.json"
+
+// DefKeyShareFile defines the default file for the encrypted key share.
+const DefKeyShareFile = "keyshare.json"
+
+// DefBoxKeyFile defines the default file for the encrypted box key, the
+// private key lists of the mint are sealed to (see netconf.EncryptBoxKey).
+const DefBoxKeyFile = "boxkey.json"
+
+// DefNonceFile defines the default file for the secret nonce of a running
+// signing ceremony.
+const DefNonceFile = "nonce.json"
+
+// DefPublicKeyFile defines the default ceremony file for the public
+// information about the threshold key.
+const DefPublicKeyFile = "threshold-pubkey.json"
+
+// Round1File returns the ceremony file name for the DKG round 1 package of
+// participant id.
+func Round1File(id int) string {
+	return fmt.Sprintf("dkg1-%d.json", id)
+}
+
+// Round2File returns the ceremony file name for the DKG round 2 package from
+// participant from to participant to.
+func Round2File(from, to int) string {
+	return fmt.Sprintf("dkg2-%d-to-%d.json", from, to)
+}
+
+// CommitmentFile returns the ceremony file name for the signing commitment
+// of participant id.
+func CommitmentFile(id int) string {
+	return fmt.Sprintf("commit-%d.json", id)
+}
+
+// CommitmentGlob matches all signing commitment ceremony files.
+const CommitmentGlob = "commit-*.json"
+
+// SignatureShareFile returns the ceremony file name for the signature share
+// of participant id.
+func SignatureShareFile(id int) string {
+	return fmt.Sprintf("sigshare-%d.json", id)
+}
+
+// SignatureShareGlob matches all signature share ceremony files.
+const SignatureShareGlob = "sigshare-*.json"
+
+// PayloadFile returns the ceremony file name for the key list payload of mint
+// epoch epoch.
+func PayloadFile(epoch int) string {
+	return fmt.Sprintf("keylist-payload-%d.bin", epoch)
+}
+
+// IdentityKey returns the mint identity key corresponding to the group key of
+// the threshold public key pub.
+func IdentityKey(pub *frost.PublicKey) (*netconf.IdentityKey, error) {
+	return netconf.ParseIdentityKey("ed25519-" + encodeKey(pub.GroupKey))
+}
+
+// ReadJSON reads the JSON file filename into v.
+func ReadJSON(filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON writes v as JSON to filename with perm (atomically, a crash never
+// leaves a truncated key share or nonce behind). The file must not exist.
+func WriteJSON(filename string, v interface{}, perm os.FileMode) error {
+	exists, err := file.Exists(filename)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("file '%s' exists already", filename)
+	}
+	jsn, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, perm)
+}
+
+// Dir returns the threshold key directory in homeDir and makes sure it
+// exists.
+func Dir(homeDir string) (string, error) {
+	dir := filepath.Join(homeDir, DefThresholdDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ErrDecrypt is returned if a threshold key file cannot be decrypted.
+var ErrDecrypt = errors.New("threshold: cannot decrypt")
+
+// passphraseBox is data encrypted with a key derived from a passphrase.
+type passphraseBox struct {
+	Salt  []byte // Argon2id salt
+	Nonce []byte // secretbox nonce
+	Box   []byte // encrypted data
+}
+
+// deriveKey derives the encryption key from pass and salt (same parameters
+// as codechain keyfiles).
+func deriveKey(pass, salt []byte) *[32]byte {
+	var key [32]byte
+	copy(key[:], argon2.IDKey(pass, salt, 1, 64*1024, 4, 32))
+	return &key
+}
+
+// readPassphrase reads the passphrase for what from the terminal. If confirm
+// is true the passphrase has to be entered twice.
+func readPassphrase(what string, confirm bool) ([]byte, error) {
+	if seckey.TestPass != "" {
+		return []byte(seckey.TestPass), nil
+	}
+	fmt.Printf("passphrase for %s:\n", what)
+	return terminal.ReadPassphrase(syscall.Stdin, confirm)
+}
+
+// seal encrypts data with a passphrase for what read from the terminal.
+func seal(what string, data []byte) (*passphraseBox, error) {
+	pass, err := readPassphrase(what, true)
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(pass)
+	b := &passphraseBox{
+		Salt:  make([]byte, 32),
+		Nonce: make([]byte, 24),
+	}
+	if _, err := io.ReadFull(rand.Reader, b.Salt); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(rand.Reader, b.Nonce); err != nil {
+		return nil, err
+	}
+	key := deriveKey(pass, b.Salt)
+	defer bzero.Bytes(key[:])
+	var nonce [24]byte
+	copy(nonce[:], b.Nonce)
+	b.Box = secretbox.Seal(nil, data, &nonce, key)
+	return b, nil
+}
+
+// open decrypts the box with a passphrase for what read from the terminal.
+func (b *passphraseBox) open(what string) ([]byte, error) {
+	if len(b.Nonce) != 24 {
+		return nil, fmt.Errorf("%w %s: invalid nonce", ErrDecrypt, what)
+	}
+	pass, err := readPassphrase(what, false)
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(pass)
+	key := deriveKey(pass, b.Salt)
+	defer bzero.Bytes(key[:])
+	var nonce [24]byte
+	copy(nonce[:], b.Nonce)
+	data, ok := secretbox.Open(nil, b.Box, &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrDecrypt, what)
+	}
+	return data, nil
+}
+
+// encryptedKeyShare defines the format of an encrypted key share file.
+type encryptedKeyShare struct {
+	ID            int              // identifier of participant
+	Public        *frost.PublicKey // public group information
+	passphraseBox                  // encrypted secret share
+}
+
+// SaveKeyShare encrypts ks with a passphrase read from the terminal and saves
+// it to filename (mode 0600). The file must not exist.
+func SaveKeyShare(filename string, ks *frost.KeyShare) error {
+	b, err := seal("key share", ks.Secret)
+	if err != nil {
+		return err
+	}
+	e := &encryptedKeyShare{
+		ID:            ks.ID,
+		Public:        ks.Public,
+		passphraseBox: *b,
+	}
+	return WriteJSON(filename, e, 0600)
+}
+
+// LoadKeyShare loads the key share from filename and decrypts it with a
+// passphrase read from the terminal.
+func LoadKeyShare(filename string) (*frost.KeyShare, error) {
+	var e encryptedKeyShare
+	if err := ReadJSON(filename, &e); err != nil {
+		return nil, err
+	}
+	fmt.Printf("opening key share: %s\n", filename)
+	secret, err := e.open("key share")
+	if err != nil {
+		return nil, err
+	}
+	return &frost.KeyShare{ID: e.ID, Secret: secret, Public: e.Public}, nil
+}
+
+// encryptedBoxKey defines the format of an encrypted box key file.
+type encryptedBoxKey struct {
+	PubKey        []byte // X25519 public key
+	passphraseBox        // encrypted X25519 private key
+}
+
+// NewBoxKey generates a new box key, encrypts it with a passphrase read from
+// the terminal, and saves it to filename (mode 0600). The file must not
+// exist. The public box key is returned.
+func NewBoxKey(filename string) (*[32]byte, error) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(priv[:])
+	b, err := seal("box key", priv[:])
+	if err != nil {
+		return nil, err
+	}
+	e := &encryptedBoxKey{
+		PubKey:        pub[:],
+		passphraseBox: *b,
+	}
+	if err := WriteJSON(filename, e, 0600); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+// readBoxKey reads the encrypted box key from filename.
+func readBoxKey(filename string) (*encryptedBoxKey, error) {
+	var e encryptedBoxKey
+	if err := ReadJSON(filename, &e); err != nil {
+		return nil, err
+	}
+	if len(e.PubKey) != 32 {
+		return nil, fmt.Errorf("threshold: invalid box key: %s", filename)
+	}
+	return &e, nil
+}
+
+// ReadBoxKey returns the public box key stored in filename, which doesn't
+// require the passphrase.
+func ReadBoxKey(filename string) (*[32]byte, error) {
+	e, err := readBoxKey(filename)
+	if err != nil {
+		return nil, err
+	}
+	var pub [32]byte
+	copy(pub[:], e.PubKey)
+	return &pub, nil
+}
+
+// LoadBoxKey loads the box key from filename and decrypts the private key
+// with a passphrase read from the terminal.
+func LoadBoxKey(filename string) (*[32]byte, error) {
+	e, err := readBoxKey(filename)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("opening box key: %s\n", filename)
+	key, err := e.open("box key")
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(key)
+	if len(key) != 32 {
+		return nil, fmt.Errorf("%w box key: invalid content", ErrDecrypt)
+	}
+	var priv [32]byte
+	copy(priv[:], key)
+	return &priv, nil
+}
+
+// Round1 is the public round 1 file of a participant in the distributed key
+// generation: the round 1 package and the X25519 public key the secret
+// shares for the participant are sealed to.
+type Round1 struct {
+	frost.Round1Package
+	BoxKey []byte // X25519 public key
+}
+
+// SealedShare is a round 2 package sealed to the receiver, which can be
+// exchanged over the shared ceremony directory.
+type SealedShare struct {
+	From  int    // identifier of sender
+	To    int    // identifier of receiver
+	Nonce []byte // box nonce
+	Box   []byte // secret share sealed to receiver by sender
+}
+
+// DKGState is the secret state of a participant between the rounds of the
+// distributed key generation.
+type DKGState struct {
+	Secret *frost.Round1Secret // secret polynomial
+	BoxKey []byte              // X25519 private key to open secret shares
+}
+
+// encryptedDKGState defines the format of an encrypted DKG state file.
+type encryptedDKGState struct {
+	ID            int // identifier of participant
+	passphraseBox     // encrypted DKGState
+}
+
+// NewDKG starts the distributed key generation for participant id of a
+// t-of-n threshold key (round 1).
+func NewDKG(id, t, n int) (*DKGState, *Round1, error) {
+	sec, pkg, err := frost.DKGRound1(id, t, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := &DKGState{Secret: sec, BoxKey: priv[:]}
+	return s, &Round1{Round1Package: *pkg, BoxKey: pub[:]}, nil
+}
+
+// Save encrypts the DKG state with a passphrase read from the terminal and
+// saves it to filename (mode 0600). The file must not exist.
+func (s *DKGState) Save(filename string) error {
+	jsn, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	defer bzero.Bytes(jsn)
+	b, err := seal("DKG state", jsn)
+	if err != nil {
+		return err
+	}
+	return WriteJSON(filename, &encryptedDKGState{ID: s.Secret.ID, passphraseBox: *b}, 0600)
+}
+
+// LoadDKGState loads the DKG state from filename and decrypts it with a
+// passphrase read from the terminal.
+func LoadDKGState(filename string) (*DKGState, error) {
+	var e encryptedDKGState
+	if err := ReadJSON(filename, &e); err != nil {
+		return nil, err
+	}
+	fmt.Printf("opening DKG state: %s\n", filename)
+	jsn, err := e.open("DKG state")
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(jsn)
+	var s DKGState
+	if err := json.Unmarshal(jsn, &s); err != nil {
+		return nil, err
+	}
+	if s.Secret == nil || len(s.BoxKey) != 32 {
+		return nil, fmt.Errorf("%w DKG state: invalid content", ErrDecrypt)
+	}
+	return &s, nil
+}
+
+// boxKey returns the box key of participant id from round 1.
+func boxKey(round1 []*Round1, id int) (*[32]byte, error) {
+	for _, r := range round1 {
+		if r.ID == id {
+			if len(r.BoxKey) != 32 {
+				return nil, fmt.Errorf("threshold: invalid box key of participant %d", id)
+			}
+			var key [32]byte
+			copy(key[:], r.BoxKey)
+			return &key, nil
+		}
+	}
+	return nil, fmt.Errorf("threshold: round 1 package of participant %d missing", id)
+}
+
+// packages returns the frost round 1 packages contained in round1.
+func packages(round1 []*Round1) []*frost.Round1Package {
+	pkgs := make([]*frost.Round1Package, 0, len(round1))
+	for _, r := range round1 {
+		pkgs = append(pkgs, &r.Round1Package)
+	}
+	return pkgs
+}
+
+// privKey returns the private box key of the DKG state.
+func (s *DKGState) privKey() *[32]byte {
+	var key [32]byte
+	copy(key[:], s.BoxKey)
+	return &key
+}
+
+// SealShares verifies the round 1 files of all participants and returns the
+// secret shares for the other participants, each sealed to its receiver
+// (round 2).
+func (s *DKGState) SealShares(round1 []*Round1) ([]*SealedShare, error) {
+	round2, err := frost.DKGRound2(s.Secret, packages(round1))
+	if err != nil {
+		return nil, err
+	}
+	priv := s.privKey()
+	defer bzero.Bytes(priv[:])
+	var shares []*SealedShare
+	for _, pkg := range round2 {
+		peer, err := boxKey(round1, pkg.To)
+		if err != nil {
+			return nil, err
+		}
+		var nonce [24]byte
+		if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+			return nil, err
+		}
+		shares = append(shares, &SealedShare{
+			From:  pkg.From,
+			To:    pkg.To,
+			Nonce: nonce[:],
+			Box:   box.Seal(nil, pkg.Share, &nonce, peer, priv),
+		})
+		bzero.Bytes(pkg.Share)
+	}
+	return shares, nil
+}
+
+// Finalize opens the secret shares sealed to the participant, verifies them
+// and returns the key share of the participant.
+func (s *DKGState) Finalize(round1 []*Round1, shares []*SealedShare) (*frost.KeyShare, error) {
+	priv := s.privKey()
+	defer bzero.Bytes(priv[:])
+	var round2 []*frost.Round2Package
+	for _, sh := range shares {
+		if sh.To != s.Secret.ID {
+			return nil, fmt.Errorf("threshold: share of participant %d is for %d",
+				sh.From, sh.To)
+		}
+		peer, err := boxKey(round1, sh.From)
+		if err != nil {
+			return nil, err
+		}
+		if len(sh.Nonce) != 24 {
+			return nil, fmt.Errorf("%w share of participant %d: invalid nonce",
+				ErrDecrypt, sh.From)
+		}
+		var nonce [24]byte
+		copy(nonce[:], sh.Nonce)
+		share, ok := box.Open(nil, sh.Box, &nonce, peer, priv)
+		if !ok {
+			return nil, fmt.Errorf("%w share of participant %d", ErrDecrypt, sh.From)
+		}
+		round2 = append(round2, &frost.Round2Package{From: sh.From, To: sh.To, Share: share})
+	}
+	return frost.DKGFinalize(s.Secret, packages(round1), round2)
+}
+
+// encodeKey encodes a public key as base64 (like netconf identity keys).
+func encodeKey(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
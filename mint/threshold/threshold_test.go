@@ -0,0 +1,332 @@
+package threshold
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/frankbraun/codechain/util/seckey"
+	"github.com/scritcash/scrit/frost"
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/def"
+)
+
+// setTestPass sets the passphrase used instead of the terminal.
+func setTestPass(t *testing.T, pass string) {
+	old := seckey.TestPass
+	seckey.TestPass = pass
+	t.Cleanup(func() { seckey.TestPass = old })
+}
+
+// dkg runs the distributed key generation for t-of-n participants over the
+// files in dir and returns the key shares.
+func dkg(t *testing.T, dir string, threshold, n int) []*frost.KeyShare {
+	var (
+		states []*DKGState
+		round1 []*Round1
+	)
+	for id := 1; id <= n; id++ {
+		state, r1, err := NewDKG(id, threshold, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, fmt.Sprintf("%d-%s", id, DefDKGStateFile))
+		if err := state.Save(filename); err != nil {
+			t.Fatal(err)
+		}
+		state, err = LoadDKGState(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename = filepath.Join(dir, Round1File(id))
+		if err := WriteJSON(filename, r1, 0644); err != nil {
+			t.Fatal(err)
+		}
+		var r Round1
+		if err := ReadJSON(filename, &r); err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, state)
+		round1 = append(round1, &r)
+	}
+	shares := make(map[int][]*SealedShare)
+	for _, state := range states {
+		sealed, err := state.SealShares(round1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sh := range sealed {
+			shares[sh.To] = append(shares[sh.To], sh)
+		}
+	}
+	var keyShares []*frost.KeyShare
+	for i, state := range states {
+		ks, err := state.Finalize(round1, shares[i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		filename := filepath.Join(dir, fmt.Sprintf("%d-%s", i+1, DefKeyShareFile))
+		if err := SaveKeyShare(filename, ks); err != nil {
+			t.Fatal(err)
+		}
+		ks, err = LoadKeyShare(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyShares = append(keyShares, ks)
+	}
+	return keyShares
+}
+
+// sign msg with the given key shares and return the commitments and
+// signature shares.
+func sign(
+	t *testing.T,
+	signers []*frost.KeyShare,
+	msg []byte,
+) ([]*frost.Commitment, []*frost.SignatureShare) {
+	var (
+		nonces      []*frost.Nonce
+		commitments []*frost.Commitment
+		shares      []*frost.SignatureShare
+	)
+	for _, ks := range signers {
+		nonce, c, err := frost.Commit(ks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nonces = append(nonces, nonce)
+		commitments = append(commitments, c)
+	}
+	for i, ks := range signers {
+		share, err := frost.Sign(ks, nonces[i], msg, commitments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		shares = append(shares, share)
+	}
+	return commitments, shares
+}
+
+func TestDKGSign(t *testing.T) {
+	setTestPass(t, "passphrase")
+	keyShares := dkg(t, t.TempDir(), 2, 3)
+	ik, err := IdentityKey(keyShares[0].Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ks := range keyShares[1:] {
+		other, err := IdentityKey(ks.Public)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if other.MarshalID() != ik.MarshalID() {
+			t.Fatal("participants have different identity keys")
+		}
+	}
+	msg := []byte("key list encoding")
+	for _, signers := range [][]*frost.KeyShare{
+		keyShares[:2],
+		{keyShares[2], keyShares[0]},
+		keyShares,
+	} {
+		commitments, shares := sign(t, signers, msg)
+		sig, err := frost.Aggregate(keyShares[0].Public, msg, commitments, shares)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ed25519.Verify(ik.PubKey, msg, sig) {
+			t.Error("signature doesn't verify with identity key")
+		}
+	}
+}
+
+// thresholdSign signs msg with the given key shares and returns the
+// aggregated signature.
+func thresholdSign(t *testing.T, signers []*frost.KeyShare, msg []byte) []byte {
+	commitments, shares := sign(t, signers, msg)
+	sig, err := frost.Aggregate(signers[0].Public, msg, commitments, shares)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func TestThresholdMint(t *testing.T) {
+	setTestPass(t, "passphrase")
+	dir := t.TempDir()
+	keyShares := dkg(t, dir, 2, 3)
+	ik, err := IdentityKey(keyShares[0].Public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := netconf.DefStartTime()
+	net := netconf.NewNetwork(1, 1, start, start.Add(def.SigningPeriod),
+		start.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]netconf.IdentityKey{*ik})
+	net.DBCTypeAdd(netconf.DBCType{Currency: "EUR", Amount: 100000000})
+
+	// prepare key list sealed to the box key
+	filename := filepath.Join(dir, DefBoxKeyFile)
+	boxKey, err := NewBoxKey(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, req, err := netconf.PrepareMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := m.EncryptBoxKey(boxKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Decrypt(ik); !errors.Is(err, netconf.ErrKeyListDecrypt) {
+		t.Errorf("Decrypt() should fail with netconf.ErrKeyListDecrypt, got: %v", err)
+	}
+
+	// sign key list payloads with 2-of-3 key shares
+	var sigs [][]byte
+	for _, p := range req.Payloads {
+		sigs = append(sigs, thresholdSign(t, []*frost.KeyShare{keyShares[2], keyShares[0]}, p))
+	}
+	if _, err := req.Response(sigs[:len(sigs)-1]); err == nil {
+		t.Error("Response() with missing signature should fail")
+	}
+	bad := append([]byte{}, sigs[0]...)
+	bad[0] ^= 1
+	if _, err := req.Response(append([][]byte{bad}, sigs[1:]...)); !errors.Is(err, netconf.ErrKeySignature) {
+		t.Errorf("Response() should fail with netconf.ErrKeySignature, got: %v", err)
+	}
+	resp, err := req.Response(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mint := e.KeyList
+	if err := mint.Finalize(resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := mint.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Add(mint); err != nil {
+		t.Fatal(err)
+	}
+
+	// the private keys can be opened with the box key
+	priv, err := LoadBoxKey(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := e.DecryptBoxKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.DecryptBoxKey(&[32]byte{1}); !errors.Is(err, netconf.ErrKeyListDecrypt) {
+		t.Errorf("DecryptBoxKey() should fail with netconf.ErrKeyListDecrypt, got: %v", err)
+	}
+
+	// replace threshold identity key
+	newKey, err := netconf.NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := thresholdSign(t, keyShares[:2], []byte(newKey.MarshalID()))
+	r := netconf.NewKeyReplacement(newKey, ik, base64.RawURLEncoding.EncodeToString(sig))
+	if err := r.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDKGFailures(t *testing.T) {
+	setTestPass(t, "passphrase")
+	dir := t.TempDir()
+	var (
+		states []*DKGState
+		round1 []*Round1
+	)
+	for id := 1; id <= 3; id++ {
+		state, r1, err := NewDKG(id, 2, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		states = append(states, state)
+		round1 = append(round1, r1)
+	}
+	sealed, err := states[0].SealShares(round1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// sealed[0] is from 1 to 2
+
+	// share for another participant
+	_, err = states[2].Finalize(round1, sealed)
+	if err == nil {
+		t.Error("Finalize() with share for other participant should fail")
+	}
+
+	// share cannot be opened by another participant
+	sh := *sealed[0]
+	sh.To = 3
+	if _, err := states[2].Finalize(round1, []*SealedShare{&sh}); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Finalize() should fail with ErrDecrypt, got: %v", err)
+	}
+
+	// tampered share
+	sh = *sealed[0]
+	sh.Box = append([]byte{}, sh.Box...)
+	sh.Box[0] ^= 1
+	if _, err := states[1].Finalize(round1, []*SealedShare{&sh}); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Finalize() should fail with ErrDecrypt, got: %v", err)
+	}
+
+	// wrong passphrase
+	filename := filepath.Join(dir, DefDKGStateFile)
+	if err := states[0].Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	setTestPass(t, "wrong")
+	if _, err := LoadDKGState(filename); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("LoadDKGState() should fail with ErrDecrypt, got: %v", err)
+	}
+}
+
+func TestSignFailures(t *testing.T) {
+	setTestPass(t, "passphrase")
+	keyShares := dkg(t, t.TempDir(), 2, 3)
+	pub := keyShares[0].Public
+	msg := []byte("message")
+
+	// too few signers
+	nonce, c, err := frost.Commit(keyShares[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = frost.Sign(keyShares[0], nonce, msg, []*frost.Commitment{c})
+	if !errors.Is(err, frost.ErrTooFewSigners) {
+		t.Errorf("Sign() should fail with ErrTooFewSigners, got: %v", err)
+	}
+
+	// identity commitment
+	_, other, err := frost.Commit(keyShares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Binding = edwards25519.NewIdentityPoint().Bytes()
+	_, err = frost.Sign(keyShares[0], nonce, msg, []*frost.Commitment{c, other})
+	if !errors.Is(err, frost.ErrIdentityCommitment) {
+		t.Errorf("Sign() should fail with ErrIdentityCommitment, got: %v", err)
+	}
+
+	// signature share for other message
+	commitments, shares := sign(t, keyShares[:2], []byte("other message"))
+	if _, err := frost.Aggregate(pub, msg, commitments, shares); !errors.Is(err, frost.ErrSignatureShare) {
+		t.Errorf("Aggregate() should fail with ErrSignatureShare, got: %v", err)
+	}
+}
@@ -41,12 +41,25 @@ type Commitment struct {
 	marshalled []byte          // Marshalled commitment.
 }
 
+// EmptyOutput is the output hash of redemptions, that is, of commitments
+// which spend their input without creating an output.
+var EmptyOutput [HashSize]byte
+
 // NewCommitment creates a new commitment from the given parameters.
 func NewCommitment(mintID uint64, input, output, proof []byte, publicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) (*Commitment, error) {
+	return newCommitment(mintID, input, Hash(output), proof, publicKey, privateKey)
+}
+
+// NewRedemption creates a new commitment which spends input without output (the output hash is EmptyOutput).
+func NewRedemption(mintID uint64, input, proof []byte, publicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) (*Commitment, error) {
+	return newCommitment(mintID, input, EmptyOutput, proof, publicKey, privateKey)
+}
+
+func newCommitment(mintID uint64, input []byte, ho [HashSize]byte, proof []byte, publicKey *[PublicKeySize]byte, privateKey *[PrivateKeySize]byte) (*Commitment, error) {
 	com := &Commitment{
 		MintID:     mintID,
 		CreateTime: Now(),
-		HO:         Hash(output),
+		HO:         ho,
 		HP:         Hash(proof),
 	}
 	if err := RandomBytes(com.Random[:]); err != nil {
@@ -122,6 +135,11 @@ func (com *Commitment) VerifyLookup(hhi *[HashSize]byte, hi *[HashSize]byte, key
 	return com.Verify(hhi, hi, publicKey)
 }
 
+// IsRedemption returns true if the commitment spends its input without output.
+func (com *Commitment) IsRedemption() bool {
+	return com.HO == EmptyOutput
+}
+
 // Matches tests if the parameter commitment d matches the receiver commitment com. It does not perform verification of the commitments.
 // It returns same==false if the commitments are for different outputs. It returns ok==true if the commitments match.
 func (com *Commitment) Matches(d *Commitment) (same, ok bool) {
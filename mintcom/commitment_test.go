@@ -313,3 +313,48 @@ func TestMatches(t *testing.T) {
 	}
 
 }
+
+// TestNewRedemption verifies NewRedemption.
+func TestNewRedemption(t *testing.T) {
+	// TestData
+	tMintID := uint64(1)
+	tInput := []byte("Test Input")
+	tProof := []byte("Test Proof")
+	tPublicKey := &[PublicKeySize]byte{0xd4, 0x4b, 0xda, 0x03, 0x6c, 0x56, 0x72, 0xf0, 0xcc, 0x2a, 0x0e, 0xc5, 0x4b, 0xc8, 0x3f, 0x1a, 0xd0, 0xf3, 0x14, 0x94, 0xc0, 0xdc, 0xec, 0xea, 0xa1, 0xaf, 0x8e, 0xbb, 0xdb, 0x3e, 0x42, 0x95}
+	tPrivateKey := &[PrivateKeySize]byte{0xbd, 0x3c, 0xca, 0xda, 0x6e, 0x56, 0xdb, 0xa7, 0x56, 0x63, 0x81, 0x6c, 0x81, 0x5d, 0x6b, 0x54, 0x2e, 0xb5, 0x0e, 0x80, 0x3b, 0x21, 0x9e, 0x10, 0xbc, 0xdf, 0x9f, 0xe6, 0x66, 0x49, 0x10, 0x13, 0xd4, 0x4b, 0xda, 0x03, 0x6c, 0x56, 0x72, 0xf0, 0xcc, 0x2a, 0x0e, 0xc5, 0x4b, 0xc8, 0x3f, 0x1a, 0xd0, 0xf3, 0x14, 0x94, 0xc0, 0xdc, 0xec, 0xea, 0xa1, 0xaf, 0x8e, 0xbb, 0xdb, 0x3e, 0x42, 0x95}
+
+	td, err := NewRedemption(tMintID, tInput, tProof, tPublicKey, tPrivateKey)
+	if err != nil {
+		t.Fatalf("NewRedemption returned unexpected error: %s", err)
+	}
+	if !td.IsRedemption() {
+		t.Error("Redemption not recognized")
+	}
+	hi := Hash(tInput)
+	if hiok, ok := td.Verify(nil, &hi, tPublicKey); !hiok || !ok {
+		t.Error("Redemption does not verify")
+	}
+	// Unmarshalled redemption.
+	tu := new(Commitment).Unmarshal(td.Marshal())
+	if tu == nil || !tu.IsRedemption() || !tu.VerifySignature(tPublicKey) {
+		t.Error("Unmarshalled redemption not recognized")
+	}
+	// Commitments with output are no redemptions.
+	tc, err := NewCommitment(tMintID, tInput, nil, tProof, tPublicKey, tPrivateKey)
+	if err != nil {
+		t.Fatalf("NewCommitment returned unexpected error: %s", err)
+	}
+	if tc.IsRedemption() {
+		t.Error("Commitment recognized as redemption")
+	}
+}
+
+// TestKeyID verifies KeyID.
+func TestKeyID(t *testing.T) {
+	if KeyID([]byte("key 1")) == KeyID([]byte("key 2")) {
+		t.Error("KeyID not unique")
+	}
+	if KeyID([]byte("key 1")) != KeyID([]byte("key 1")) {
+		t.Error("KeyID not deterministic")
+	}
+}
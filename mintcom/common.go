@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	_ "crypto/sha256" // used hashing algorithm
+	"encoding/binary"
 	"errors"
 	"time"
 )
@@ -44,6 +45,12 @@ func Hash(i []byte) [HashSize]byte {
 	return *r
 }
 
+// KeyID returns the public ID of the mint with the given public key: the first 8 bytes of Hash(publicKey) (big-endian).
+func KeyID(publicKey []byte) uint64 {
+	h := Hash(publicKey)
+	return binary.BigEndian.Uint64(h[0:8])
+}
+
 // HMAC returns an HMAC for msg using key.
 func HMAC(msg, key []byte) [HashSize]byte {
 	r := new([HashSize]byte)
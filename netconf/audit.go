@@ -0,0 +1,389 @@
+package netconf
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/scritcash/scrit/mintcom"
+)
+
+// AuditView is the view of the federation or of a single mint of the DBCs of
+// a DBC type.
+type AuditView struct {
+	MintID   string  // ID of mint, empty for the view of the federation
+	DBCType  DBCType // DBC type
+	Issued   uint64  // number of DBCs created by issuance
+	Redeemed uint64  // number of DBCs destroyed by redemption
+	Inputs   uint64  // number of DBCs spent by reissue
+	Outputs  uint64  // number of DBCs created by reissue
+}
+
+// value returns the value of count DBCs of amount (in base units).
+func value(count, amount uint64) *big.Int {
+	v := new(big.Int).SetUint64(count)
+	return v.Mul(v, new(big.Int).SetUint64(amount))
+}
+
+// formatValue formats the value v (in base units) with all decimal places.
+func formatValue(v *big.Int) string {
+	return new(big.Rat).SetFrac(v, big.NewInt(1e8)).FloatString(AmountDecimals)
+}
+
+// add the counts of o to v.
+func (v *AuditView) add(o *AuditView) {
+	v.Issued += o.Issued
+	v.Redeemed += o.Redeemed
+	v.Inputs += o.Inputs
+	v.Outputs += o.Outputs
+}
+
+// equal returns true, if v and o have the same counts.
+func (v *AuditView) equal(o *AuditView) bool {
+	return v.Issued == o.Issued && v.Redeemed == o.Redeemed &&
+		v.Inputs == o.Inputs && v.Outputs == o.Outputs
+}
+
+// String returns the counts of the view.
+func (v *AuditView) String() string {
+	return fmt.Sprintf("issued %d, redeemed %d, inputs %d, outputs %d",
+		v.Issued, v.Redeemed, v.Inputs, v.Outputs)
+}
+
+// auditViews are the views of all DBC types in an epoch.
+type auditViews map[DBCType]*AuditView
+
+// imbalances returns an error for every currency in vs whose value of outputs
+// plus redemption exceeds the value of inputs plus issuance. Values are
+// compared per currency, because a reissue can change the denomination.
+func (vs auditViews) imbalances() []error {
+	in := make(map[string]*big.Int)
+	out := make(map[string]*big.Int)
+	for dbcType, v := range vs {
+		c := dbcType.Currency
+		if _, ok := in[c]; !ok {
+			in[c] = new(big.Int)
+			out[c] = new(big.Int)
+		}
+		in[c].Add(in[c], value(v.Inputs, dbcType.Amount))
+		in[c].Add(in[c], value(v.Issued, dbcType.Amount))
+		out[c].Add(out[c], value(v.Outputs, dbcType.Amount))
+		out[c].Add(out[c], value(v.Redeemed, dbcType.Amount))
+	}
+	var currencies []string
+	for c := range in {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	var errs []error
+	for _, c := range currencies {
+		if out[c].Cmp(in[c]) > 0 {
+			errs = append(errs, fmt.Errorf(
+				"%w: %s: outputs and redemption %s exceed inputs and issuance %s",
+				ErrAuditImbalance, c, formatValue(out[c]), formatValue(in[c])))
+		}
+	}
+	return errs
+}
+
+// get returns the view of dbcType, which is created if necessary.
+func (vs auditViews) get(mintID string, dbcType DBCType) *AuditView {
+	v, ok := vs[dbcType]
+	if !ok {
+		v = &AuditView{MintID: mintID, DBCType: dbcType}
+		vs[dbcType] = v
+	}
+	return v
+}
+
+// view returns a copy of the view of dbcType (an empty one, if vs doesn't
+// contain it).
+func (vs auditViews) view(mintID string, dbcType DBCType) AuditView {
+	if v, ok := vs[dbcType]; ok {
+		return *v
+	}
+	return AuditView{MintID: mintID, DBCType: dbcType}
+}
+
+// spendRecord identifies the content of a commitment log entry, the
+// commitment itself is created by every mint on its own and therefore not
+// compared.
+type spendRecord struct {
+	Epoch  int
+	Input  DBCType
+	Output DBCType
+}
+
+// confirmedSpends returns the spends (by epoch) which have been recorded
+// identically by at least a quorum of mints. Conflicting spends of the same
+// DBC are reported as errors in r, spends with fewer recordings as warnings.
+func (f *Federation) confirmedSpends(
+	logs map[string]*CommitmentLog,
+	r *ValidationReport,
+) map[int][]spendRecord {
+	var hhis []string
+	variants := make(map[string][]spendRecord)
+	mints := make(map[string]map[spendRecord][]string)
+	for _, id := range sortedKeys(logs) {
+		for _, e := range logs[id].Entries {
+			com := new(mintcom.Commitment).Unmarshal(e.Commitment)
+			hhi := hex.EncodeToString(com.HHI[:])
+			rec := spendRecord{e.Epoch, e.Input, e.Output}
+			if _, ok := mints[hhi]; !ok {
+				hhis = append(hhis, hhi)
+				mints[hhi] = make(map[spendRecord][]string)
+			}
+			if _, ok := mints[hhi][rec]; !ok {
+				variants[hhi] = append(variants[hhi], rec)
+			}
+			mints[hhi][rec] = append(mints[hhi][rec], id)
+		}
+	}
+	confirmed := make(map[int][]spendRecord)
+	for _, hhi := range hhis {
+		if len(variants[hhi]) > 1 {
+			for _, rec := range variants[hhi] {
+				for _, mintID := range mints[hhi][rec] {
+					r.addError(rec.Epoch, mintID, fmt.Errorf("%w: %s: %s -> %s",
+						ErrAuditConflict, hhi, rec.Input, rec.Output))
+				}
+			}
+		}
+		var found bool
+		for _, rec := range variants[hhi] {
+			q := f.Network.NetworkEpochs[rec.Epoch].QuorumM
+			if uint64(len(mints[hhi][rec])) >= q {
+				confirmed[rec.Epoch] = append(confirmed[rec.Epoch], rec)
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.addWarning(variants[hhi][0].Epoch, "", fmt.Errorf(
+				"%w: spend of %s not recorded by quorum", ErrAuditDivergence, hhi))
+		}
+	}
+	return confirmed
+}
+
+// sortedKeys returns the keys of logs in sorted order.
+func sortedKeys(logs map[string]*CommitmentLog) []string {
+	var keys []string
+	for k := range logs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ledgerViews adds the entries of the ledgers of the mint with the given ID
+// (of all mints, if mintID is empty) to the issued (LedgerCreate) or redeemed
+// (LedgerDestroyed) counts of views.
+func ledgerViews(views auditViews, mintID string, ledgers []*Ledger) {
+	for _, l := range ledgers {
+		if l.MintIdentityKey.MarshalID() != mintID {
+			continue
+		}
+		for _, e := range l.Entries {
+			v := views.get(mintID, e.DBCType)
+			if l.Kind == LedgerCreate {
+				v.Issued += e.Count
+			} else {
+				v.Redeemed += e.Count
+			}
+		}
+	}
+}
+
+// Audit audits the supply of the federation against the commitment logs of
+// its mints (the exported spendbooks). The view of the federation is
+// computed from the ledger entries and spends recorded by a quorum of mints,
+// the view of every mint from its own ledgers and commitment log. For every
+// currency the value of the outputs must never exceed the value of the inputs
+// plus issuance minus redemption, neither for the federation nor for any mint
+// (measured against the issuance and redemption of the federation). Values
+// are compared per currency, because a reissue can change the denomination. Mints
+// whose view diverges from the federation in an epoch they were part of the
+// network are reported as warnings.
+//
+// Audit returns the views of the federation and of all mints summed up over
+// all epochs, sorted by mint ID and DBC type, and the report of all issues.
+func (f *Federation) Audit(logs []*CommitmentLog) ([]AuditView, *ValidationReport) {
+	var r ValidationReport
+	valid := make(map[string]*CommitmentLog)
+	for _, l := range logs {
+		id := l.MintIdentityKey.MarshalID()
+		if err := l.Validate(f.Network); err != nil {
+			r.addError(NoEpoch, id, err)
+			continue
+		}
+		if _, ok := valid[id]; ok {
+			r.addError(NoEpoch, id, ErrCommitmentLogDuplicate)
+			continue
+		}
+		valid[id] = l
+	}
+
+	// view of the federation per epoch
+	spends := f.confirmedSpends(valid, &r)
+	fedViews := make([]auditViews, len(f.Network.NetworkEpochs))
+	for i, e := range f.Network.NetworkEpochs {
+		fedViews[i] = make(auditViews)
+		for _, rec := range confirmedEntries(f.Created[i], i, e.QuorumM, &r) {
+			fedViews[i].get("", rec.DBCType).Issued += rec.Count
+		}
+		for _, rec := range confirmedEntries(f.Destroyed[i], i, e.QuorumM, &r) {
+			fedViews[i].get("", rec.DBCType).Redeemed += rec.Count
+		}
+		for _, rec := range spends[i] {
+			if rec.Output != (DBCType{}) {
+				fedViews[i].get("", rec.Input).Inputs++
+				fedViews[i].get("", rec.Output).Outputs++
+			}
+		}
+	}
+
+	// view of the mints per epoch
+	mintIDs := make(map[string]bool)
+	for id := range f.Network.AllMints() {
+		mintIDs[id] = true
+	}
+	for id := range valid {
+		mintIDs[id] = true
+	}
+	mintViews := make(map[string][]auditViews)
+	for id := range mintIDs {
+		views := make([]auditViews, len(f.Network.NetworkEpochs))
+		logRedeemed := make([]map[DBCType]uint64, len(f.Network.NetworkEpochs))
+		for i := range f.Network.NetworkEpochs {
+			views[i] = make(auditViews)
+			logRedeemed[i] = make(map[DBCType]uint64)
+			ledgerViews(views[i], id, f.Created[i])
+			ledgerViews(views[i], id, f.Destroyed[i])
+		}
+		l, ok := valid[id]
+		if !ok {
+			r.addWarning(NoEpoch, id, fmt.Errorf("%w: no commitment log", ErrAuditDivergence))
+		} else {
+			for _, e := range l.Entries {
+				if e.Output == (DBCType{}) {
+					logRedeemed[e.Epoch][e.Input]++
+					continue
+				}
+				views[e.Epoch].get(id, e.Input).Inputs++
+				views[e.Epoch].get(id, e.Output).Outputs++
+			}
+			// redemptions in the commitment log must match the destruction
+			// ledgers
+			for i := range views {
+				dbcTypes := make(map[DBCType]bool)
+				for dbcType := range logRedeemed[i] {
+					dbcTypes[dbcType] = true
+				}
+				for dbcType, v := range views[i] {
+					if v.Redeemed > 0 {
+						dbcTypes[dbcType] = true
+					}
+				}
+				for _, dbcType := range DBCTypeMapToSortedArray(dbcTypes) {
+					v := views[i].view(id, dbcType)
+					if v.Redeemed != logRedeemed[i][dbcType] {
+						r.addWarning(i, id, fmt.Errorf(
+							"%w: %s: %d redemptions in commitment log, %d in ledger",
+							ErrAuditDivergence, dbcType, logRedeemed[i][dbcType], v.Redeemed))
+					}
+				}
+			}
+		}
+		mintViews[id] = views
+	}
+
+	// check balances and divergence epoch by epoch
+	fedTotal := make(auditViews)
+	mintTotals := make(map[string]auditViews)
+	for id := range mintIDs {
+		mintTotals[id] = make(auditViews)
+	}
+	for i, e := range f.Network.NetworkEpochs {
+		for dbcType, v := range fedViews[i] {
+			fedTotal.get("", dbcType).add(v)
+		}
+		for _, err := range fedTotal.imbalances() {
+			r.addError(i, "", err)
+		}
+		mints, err := f.Network.MintsAt(e.SignStart)
+		if err != nil {
+			r.addError(i, "", err)
+			continue
+		}
+		for _, id := range sortedIDs(mintIDs) {
+			totals := mintTotals[id]
+			for dbcType, v := range mintViews[id][i] {
+				totals.get(id, dbcType).add(v)
+			}
+			// mints only see the spends they signed, their outputs are
+			// measured against the issuance and redemption of the federation
+			check := make(auditViews)
+			checkTypes := viewTypes(totals)
+			for dbcType := range fedTotal {
+				checkTypes[dbcType] = true
+			}
+			for dbcType := range checkTypes {
+				v := totals.view(id, dbcType)
+				fv := fedTotal.view("", dbcType)
+				v.Issued, v.Redeemed = fv.Issued, fv.Redeemed
+				check[dbcType] = &v
+			}
+			for _, err := range check.imbalances() {
+				r.addError(i, id, err)
+			}
+			if !mints[id] {
+				continue
+			}
+			dbcTypes := viewTypes(fedViews[i])
+			for dbcType := range mintViews[id][i] {
+				dbcTypes[dbcType] = true
+			}
+			for _, dbcType := range DBCTypeMapToSortedArray(dbcTypes) {
+				mv := mintViews[id][i].view(id, dbcType)
+				fv := fedViews[i].view("", dbcType)
+				if !mv.equal(&fv) {
+					r.addWarning(i, id, fmt.Errorf("%w: %s: %s (federation: %s)",
+						ErrAuditDivergence, dbcType, &mv, &fv))
+				}
+			}
+		}
+	}
+
+	// collect totals
+	var views []AuditView
+	for _, dbcType := range DBCTypeMapToSortedArray(viewTypes(fedTotal)) {
+		views = append(views, *fedTotal[dbcType])
+	}
+	for _, id := range sortedIDs(mintIDs) {
+		for _, dbcType := range DBCTypeMapToSortedArray(viewTypes(mintTotals[id])) {
+			views = append(views, *mintTotals[id][dbcType])
+		}
+	}
+	return views, &r
+}
+
+// viewTypes returns the set of DBC types in views.
+func viewTypes(views auditViews) map[DBCType]bool {
+	dbcTypes := make(map[DBCType]bool)
+	for dbcType := range views {
+		dbcTypes[dbcType] = true
+	}
+	return dbcTypes
+}
+
+// sortedIDs returns the IDs in ids in sorted order.
+func sortedIDs(ids map[string]bool) []string {
+	var sorted []string
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
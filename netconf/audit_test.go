@@ -0,0 +1,226 @@
+package netconf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/mintcom"
+)
+
+var (
+	testTwoEUR   = DBCType{Currency: "EUR", Amount: 200000000}
+	testFiftyEUR = DBCType{Currency: "EUR", Amount: 5000000000}
+)
+
+// testSpend returns a commitment log entry of ik for spending input in epoch
+// 0 (a redemption, if out is the zero DBCType).
+func testSpend(t *testing.T, ik *IdentityKey, input string, in, out DBCType) CommitmentLogEntry {
+	var pubKey [mintcom.PublicKeySize]byte
+	var privKey [mintcom.PrivateKeySize]byte
+	copy(pubKey[:], ik.PubKey)
+	copy(privKey[:], ik.privKey)
+	var (
+		com *mintcom.Commitment
+		err error
+	)
+	if out == (DBCType{}) {
+		com, err = mintcom.NewRedemption(mintcom.KeyID(ik.PubKey), []byte(input),
+			[]byte("proof"), &pubKey, &privKey)
+	} else {
+		com, err = mintcom.NewCommitment(mintcom.KeyID(ik.PubKey), []byte(input),
+			[]byte("output of "+input), []byte("proof"), &pubKey, &privKey)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return CommitmentLogEntry{
+		Commitment: com.Marshal(),
+		Input:      in,
+		Output:     out,
+	}
+}
+
+// signedCommitmentLog returns a commitment log of ik with the given entries.
+func signedCommitmentLog(t *testing.T, ik *IdentityKey, entries ...CommitmentLogEntry) *CommitmentLog {
+	l := NewCommitmentLog(ik, entries)
+	s := NewKeySigner()
+	s.AddIdentityKey(ik)
+	if err := l.SignWith(s); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestCommitmentLogValidate(t *testing.T) {
+	net, iks := ledgerTestNetwork(t)
+	ik := iks[0]
+	valid := []CommitmentLogEntry{
+		testSpend(t, ik, "dbc 1", testEUR, DBCType{}),
+		testSpend(t, ik, "dbc 2", testEUR, testEUR),
+	}
+	l := signedCommitmentLog(t, ik, valid...)
+	if err := l.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+
+	// manipulated log
+	l.Entries = l.Entries[:1]
+	if err := l.Validate(net); !errors.Is(err, ErrCommitmentLogSignature) {
+		t.Errorf("Validate() should fail with ErrCommitmentLogSignature, got: %v", err)
+	}
+
+	// malformed identity key in an exported log must not panic
+	short := signedCommitmentLog(t, ik, valid...)
+	short.MintIdentityKey.PubKey = short.MintIdentityKey.PubKey[:16]
+	if err := short.Validate(net); !errors.Is(err, ErrCommitmentLogEntry) {
+		t.Errorf("Validate() should fail with ErrCommitmentLogEntry, got: %v", err)
+	}
+
+	outsider, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	usd := DBCType{Currency: "USD", Amount: 100000000}
+	mismatch := testSpend(t, ik, "dbc 3", testEUR, testEUR)
+	mismatch.Output = DBCType{}
+	wrongEpoch := valid[0]
+	wrongEpoch.Epoch = 1
+	tests := []struct {
+		name string
+		l    *CommitmentLog
+		err  error
+	}{
+		{"duplicate input", signedCommitmentLog(t, ik, valid[0], valid[0]), ErrCommitmentLogEntry},
+		{"output mismatch", signedCommitmentLog(t, ik, mismatch), ErrCommitmentLogEntry},
+		{"unknown epoch", signedCommitmentLog(t, ik, wrongEpoch), ErrCommitmentLogEntry},
+		{"foreign commitment", signedCommitmentLog(t, iks[1], valid[0]), ErrCommitmentLogEntry},
+		{"unknown mint", signedCommitmentLog(t, outsider,
+			testSpend(t, outsider, "dbc 1", testEUR, DBCType{})), ErrCommitmentLogEntry},
+		{"undefined input", signedCommitmentLog(t, ik,
+			testSpend(t, ik, "dbc 4", usd, DBCType{})), ErrDBCTypeNotDefined},
+		{"undefined output", signedCommitmentLog(t, ik,
+			testSpend(t, ik, "dbc 5", testEUR, usd)), ErrDBCTypeNotDefined},
+	}
+	for _, tt := range tests {
+		if err := tt.l.Validate(net); !errors.Is(err, tt.err) {
+			t.Errorf("%s: Validate() should fail with %v, got: %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+// countIssues returns the number of issues with the given code in r.
+func countIssues(r *ValidationReport, code string) int {
+	var n int
+	for _, i := range r.Issues {
+		if i.Code == code {
+			n++
+		}
+	}
+	return n
+}
+
+func TestAudit(t *testing.T) {
+	net, iks := ledgerTestNetwork(t)
+	net.DBCTypeAdd(testTwoEUR)
+	net.DBCTypeAdd(testFiftyEUR)
+	at := net.NetworkEpochs[0].SignStart.Add(time.Hour)
+	dep1 := LedgerEntry{ID: "dep1", DBCType: testEUR, Count: 10, Time: at}
+	wd1 := LedgerEntry{ID: "wd1", DBCType: testEUR, Count: 1, Time: at}
+	f := &Federation{
+		Network: net,
+		Created: map[int][]*Ledger{0: {
+			signedLedger(t, iks[0], LedgerCreate, dep1),
+			signedLedger(t, iks[1], LedgerCreate, dep1),
+			signedLedger(t, iks[2], LedgerCreate, dep1),
+		}},
+		Destroyed: map[int][]*Ledger{0: {
+			signedLedger(t, iks[0], LedgerDestroyed, wd1),
+			signedLedger(t, iks[1], LedgerDestroyed, wd1),
+		}},
+	}
+	// mint 0 and 1 redeemed dbc 1, all mints reissued dbc 2 and dbc 3
+	entries := func(ik *IdentityKey, redeemed bool) []CommitmentLogEntry {
+		e := []CommitmentLogEntry{
+			testSpend(t, ik, "dbc 2", testEUR, testEUR),
+			testSpend(t, ik, "dbc 3", testEUR, testEUR),
+		}
+		if redeemed {
+			e = append(e, testSpend(t, ik, "dbc 1", testEUR, DBCType{}))
+		}
+		return e
+	}
+	logs := []*CommitmentLog{
+		signedCommitmentLog(t, iks[0], entries(iks[0], true)...),
+		signedCommitmentLog(t, iks[1], entries(iks[1], true)...),
+		signedCommitmentLog(t, iks[2], entries(iks[2], false)...),
+	}
+	views, r := f.Audit(logs)
+	if r.HasErrors() {
+		t.Fatal(r.Err())
+	}
+	if len(views) != 4 {
+		t.Fatalf("%d views instead of 4", len(views))
+	}
+	v := views[0]
+	if v.MintID != "" || v.Issued != 10 || v.Redeemed != 1 || v.Inputs != 2 || v.Outputs != 2 {
+		t.Errorf("wrong federation view: %+v", v)
+	}
+	// mint 2 didn't redeem dbc 1
+	if n := countIssues(r, "ErrAuditDivergence"); n != 1 {
+		t.Errorf("%d divergences reported instead of 1:\n%s", n, r.Marshal())
+	}
+	if r.Issues[0].MintID != iks[2].MarshalID() {
+		t.Errorf("divergence of wrong mint reported: %s", r.Issues[0].MintID)
+	}
+
+	// a reissue into another denomination recorded by all mints
+	reissued := make([]*CommitmentLog, len(logs))
+	for i, redeemed := range []bool{true, true, false} {
+		reissued[i] = signedCommitmentLog(t, iks[i], append(entries(iks[i], redeemed),
+			testSpend(t, iks[i], "dbc 4", testEUR, testTwoEUR))...)
+	}
+	_, r = f.Audit(reissued)
+	if r.HasErrors() {
+		t.Errorf("reissue into another denomination reported:\n%s", r.Marshal())
+	}
+
+	// a mint which creates DBCs out of thin air
+	logs[2] = signedCommitmentLog(t, iks[2], append(entries(iks[2], false),
+		testSpend(t, iks[2], "dbc 4", testEUR, testFiftyEUR))...)
+	_, r = f.Audit(logs)
+	if !errors.Is(r.Err(), ErrAuditImbalance) {
+		t.Errorf("imbalance not reported: %v", r.Err())
+	}
+	if n := countIssues(r, "ErrAuditImbalance"); n != 1 {
+		t.Errorf("%d imbalances reported instead of 1:\n%s", n, r.Marshal())
+	}
+
+	// outputs recorded by a quorum
+	logs[0] = signedCommitmentLog(t, iks[0], append(entries(iks[0], true),
+		testSpend(t, iks[0], "dbc 4", testEUR, testFiftyEUR))...)
+	_, r = f.Audit(logs)
+	var fedImbalance bool
+	for _, i := range r.Issues {
+		if i.Code == "ErrAuditImbalance" && i.MintID == "" {
+			fedImbalance = true
+		}
+	}
+	if !fedImbalance {
+		t.Errorf("imbalance of federation not reported:\n%s", r.Marshal())
+	}
+
+	// the same DBC spent differently
+	logs[1] = signedCommitmentLog(t, iks[1], append(entries(iks[1], true),
+		testSpend(t, iks[1], "dbc 4", testEUR, testEUR))...)
+	_, r = f.Audit(logs)
+	if n := countIssues(r, "ErrAuditConflict"); n != 3 {
+		t.Errorf("%d conflicts reported instead of 3:\n%s", n, r.Marshal())
+	}
+
+	// duplicate logs
+	_, r = f.Audit(append(logs, logs[0]))
+	if !errors.Is(r.Err(), ErrCommitmentLogDuplicate) {
+		t.Errorf("duplicate log not reported: %v", r.Err())
+	}
+}
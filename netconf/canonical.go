@@ -0,0 +1,151 @@
+package netconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// The canonical JSON encoding of federation files is defined as follows:
+//
+//   - object keys are sorted (byte-wise),
+//   - objects and arrays are indented with two spaces, the file ends with a
+//     newline,
+//   - times are encoded in UTC as RFC 3339 strings (with fractional seconds
+//     only if present),
+//   - byte strings (signatures, public keys) are encoded in standard base64
+//     with padding (RFC 4648, section 4),
+//   - numbers are encoded as integers and HTML characters are not escaped.
+//
+// All files written by this package use the canonical encoding.
+
+// utcCopy returns a copy of v in which all time.Time values reachable from v
+// are converted to UTC. Pointers, slices and arrays are copied deeply, v itself
+// is not modified. Only exported struct fields are considered, maps are shared.
+func utcCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(utcCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(utcCopy(v.Elem()))
+		return c
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return reflect.ValueOf(t.UTC())
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" { // exported
+				c.Field(i).Set(utcCopy(v.Field(i)))
+			}
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(utcCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(utcCopy(v.Index(i)))
+		}
+		return c
+	}
+	return v
+}
+
+// MarshalCanonical returns the canonical JSON encoding of v. Times contained
+// in v are encoded in UTC, v is not modified.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	if rv := reflect.ValueOf(v); rv.IsValid() {
+		v = utcCopy(rv).Interface()
+	}
+	jsn, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// decode generically to sort keys, keep numbers as they are
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(jsn))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeStrict decodes data into v and fails on unknown fields, which would
+// otherwise be lost when v is encoded again.
+func decodeStrict(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("netconf: trailing data after JSON value")
+	}
+	return nil
+}
+
+// CanonicalFile returns the canonical JSON encoding of the federation file
+// with content data. The type of the file is determined by its filename
+// (relative to the federation directory): DefNetConfFile, a mint in
+// DefMintDir, a revocation list in DefRevocationDir, or a ledger list in an
+// epoch directory of DefDBCDir.
+func CanonicalFile(filename string, data []byte) ([]byte, error) {
+	var v interface{}
+	name := filepath.ToSlash(filepath.Clean(filename))
+	switch {
+	case name == DefNetConfFile:
+		v = new(Network)
+	case path.Dir(name) == DefMintDir:
+		v = new(Mint)
+	case path.Dir(name) == DefRevocationDir:
+		v = new([]*Revocation)
+	case path.Dir(path.Dir(name)) == DefDBCDir:
+		v = new([]*Ledger)
+	default:
+		return nil, fmt.Errorf("netconf: unknown federation file '%s'", filename)
+	}
+	if err := decodeStrict(data, v); err != nil {
+		return nil, fmt.Errorf("netconf: cannot decode '%s': %w", filename, err)
+	}
+	return MarshalCanonical(v)
+}
+
+// IsCanonical returns true, if data is the canonical JSON encoding of the
+// federation file filename (see CanonicalFile).
+func IsCanonical(filename string, data []byte) (bool, error) {
+	canonical, err := CanonicalFile(filename, data)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(data, canonical), nil
+}
@@ -0,0 +1,90 @@
+package netconf
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	loc := time.FixedZone("CET", 3600)
+	start := time.Date(2020, 1, 18, 1, 0, 0, 0, loc)
+	end := start.Add(time.Hour)
+	k := &SigningKey{
+		Currency:  "EUR",
+		Amount:    100000000,
+		SignStart: &start,
+		SignEnd:   &end,
+	}
+	jsn, err := MarshalCanonical(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(jsn)
+	if !strings.Contains(s, `"SignStart": "2020-01-18T00:00:00Z"`) {
+		t.Errorf("time not encoded in UTC:\n%s", s)
+	}
+	if strings.Index(s, `"Amount"`) > strings.Index(s, `"Currency"`) {
+		t.Errorf("keys not sorted:\n%s", s)
+	}
+	if !strings.HasSuffix(s, "}\n") {
+		t.Error("canonical encoding should end with newline")
+	}
+	if k.SignStart.Location() != loc || k.SignEnd.Location() != loc {
+		t.Error("MarshalCanonical() modified its argument")
+	}
+	if start.Location() != loc {
+		t.Error("MarshalCanonical() modified time behind pointer")
+	}
+
+	// times in values (not only behind pointers) are encoded in UTC
+	jsn, err = MarshalCanonical(struct{ T time.Time }{start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(jsn), `"T": "2020-01-18T00:00:00Z"`) {
+		t.Errorf("time not encoded in UTC:\n%s", jsn)
+	}
+}
+
+func TestCanonicalFile(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", DefNetConfFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, err := CanonicalFile(DefNetConfFile, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := IsCanonical(DefNetConfFile, canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("canonical encoding should be canonical")
+	}
+	// canonical encoding must be loadable and equal
+	n1, err := unmarshalNetwork(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2, err := unmarshalNetwork(canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n1.Marshal() != n2.Marshal() {
+		t.Error("canonical encoding changed network")
+	}
+
+	// unknown fields are not silently dropped
+	unknown := strings.Replace(string(data), `"NetworkEpochs"`, `"Unknown": 1, "NetworkEpochs"`, 1)
+	if _, err := CanonicalFile(DefNetConfFile, []byte(unknown)); err == nil {
+		t.Error("CanonicalFile() should fail for unknown fields")
+	}
+	// unknown file type
+	if _, err := CanonicalFile("other.json", data); err == nil {
+		t.Error("CanonicalFile() should fail for unknown file")
+	}
+}
@@ -0,0 +1,184 @@
+package netconf
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/mintcom"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// CommitmentLogEntry records a DBC spent at a mint.
+type CommitmentLogEntry struct {
+	Commitment []byte  // marshalled mintcom.Commitment
+	Epoch      int     // signing epoch of the spend
+	Input      DBCType // type of the spent DBC
+	Output     DBCType // type of the output, zero for redemptions
+}
+
+// CommitmentLog is the spendbook of a mint exported for audits: the
+// commitments for all DBCs spent at the mint together with the DBC types of
+// their inputs and outputs.
+type CommitmentLog struct {
+	MintIdentityKey IdentityKey          // identity key of the recording mint
+	Entries         []CommitmentLogEntry // spent DBCs
+	Signature       string               // of all fields above by MintIdentityKey
+}
+
+// NewCommitmentLog returns a new commitment log with the given entries for
+// the mint with identity key ik. It has to be signed before it is exported.
+func NewCommitmentLog(ik *IdentityKey, entries []CommitmentLogEntry) *CommitmentLog {
+	return &CommitmentLog{
+		MintIdentityKey: IdentityKey{SigAlgo: ik.SigAlgo, PubKey: ik.PubKey},
+		Entries:         entries,
+	}
+}
+
+// encode commitment log (without signature).
+func (l *CommitmentLog) encode() ([]byte, error) {
+	encodingScheme := []interface{}{
+		[]byte(l.MintIdentityKey.SigAlgo),
+		l.MintIdentityKey.PubKey,
+		int64(len(l.Entries)),
+	}
+	for _, e := range l.Entries {
+		encodingScheme = append(encodingScheme,
+			e.Commitment,
+			int64(e.Epoch),
+			[]byte(e.Input.Currency),
+			int64(e.Input.Amount),
+			[]byte(e.Output.Currency),
+			int64(e.Output.Amount),
+		)
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	return binencode.Encode(buf, encodingScheme...)
+}
+
+// SignWith signs the commitment log with signer s, which must know the
+// private key of the mint identity key. The signature is verified, because s
+// might be a remote signer.
+func (l *CommitmentLog) SignWith(s Signer) error {
+	enc, err := l.encode()
+	if err != nil {
+		return err
+	}
+	sig, err := s.Sign(l.MintIdentityKey.PubKey, enc)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(l.MintIdentityKey.PubKey, enc, sig) {
+		return ErrCommitmentLogSignature
+	}
+	l.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	return nil
+}
+
+// Verify the signature of the commitment log.
+func (l *CommitmentLog) Verify() error {
+	sig, err := base64.RawURLEncoding.DecodeString(l.Signature)
+	if err != nil {
+		return err
+	}
+	enc, err := l.encode()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(l.MintIdentityKey.PubKey, enc, sig) {
+		return ErrCommitmentLogSignature
+	}
+	return nil
+}
+
+// Validate the commitment log against the network configuration n. Every
+// entry must be a commitment signed by the mint identity key in a signing
+// epoch the mint was part of the network, for a DBC type defined so far.
+// Redemptions (see mintcom.NewRedemption) must not have an output type, all
+// other commitments must have one.
+func (l *CommitmentLog) Validate(n *Network) error {
+	// check the key length first, ed25519.Verify panics on malformed keys
+	if len(l.MintIdentityKey.PubKey) != mintcom.PublicKeySize {
+		return fmt.Errorf("%w: invalid mint identity key", ErrCommitmentLogEntry)
+	}
+	if err := l.Verify(); err != nil {
+		return err
+	}
+	id := l.MintIdentityKey.MarshalID()
+	var pubKey [mintcom.PublicKeySize]byte
+	copy(pubKey[:], l.MintIdentityKey.PubKey)
+	mintID := mintcom.KeyID(pubKey[:])
+	hhis := make(map[[mintcom.HashSize]byte]bool)
+	for i, e := range l.Entries {
+		if e.Epoch < 0 || e.Epoch >= len(n.NetworkEpochs) {
+			return fmt.Errorf("%w: %d: unknown epoch %d", ErrCommitmentLogEntry, i, e.Epoch)
+		}
+		mints, err := n.MintsAt(n.NetworkEpochs[e.Epoch].SignStart)
+		if err != nil {
+			return err
+		}
+		if !mints[id] {
+			return fmt.Errorf("%w: %d: mint not in epoch %d", ErrCommitmentLogEntry, i, e.Epoch)
+		}
+		com := new(mintcom.Commitment).Unmarshal(e.Commitment)
+		if com == nil {
+			return fmt.Errorf("%w: %d: cannot unmarshal commitment", ErrCommitmentLogEntry, i)
+		}
+		if com.MintID != mintID || !com.VerifySignature(&pubKey) {
+			return fmt.Errorf("%w: %d: commitment signature does not verify",
+				ErrCommitmentLogEntry, i)
+		}
+		if hhis[com.HHI] {
+			return fmt.Errorf("%w: %d: duplicate input %s", ErrCommitmentLogEntry, i,
+				hex.EncodeToString(com.HHI[:]))
+		}
+		hhis[com.HHI] = true
+		if com.IsRedemption() != (e.Output == DBCType{}) {
+			return fmt.Errorf("%w: %d: output type doesn't match commitment",
+				ErrCommitmentLogEntry, i)
+		}
+		// inputs can be of every DBC type defined so far, outputs must be of
+		// a DBC type of the epoch
+		defined := false
+		for j := 0; j <= e.Epoch && !defined; j++ {
+			defined = n.DBCTypesAt(j)[e.Input]
+		}
+		if !defined {
+			return fmt.Errorf("%w: %d: %v", ErrDBCTypeNotDefined, i, e.Input)
+		}
+		if e.Output != (DBCType{}) && !n.DBCTypesAt(e.Epoch)[e.Output] {
+			return fmt.Errorf("%w: %d: %v", ErrDBCTypeNotDefined, i, e.Output)
+		}
+	}
+	return nil
+}
+
+// LoadCommitmentLog loads a commitment log from filename.
+func LoadCommitmentLog(filename string) (*CommitmentLog, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var l CommitmentLog
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Save the commitment log to filename. If filename exists already it will be
+// overwritten!
+func (l *CommitmentLog) Save(filename string) error {
+	jsn, err := MarshalCanonical(l)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
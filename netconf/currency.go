@@ -0,0 +1,231 @@
+package netconf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// AmountDecimals is the number of decimal places of DBC amounts, that is,
+// the last 8 digits of DBCType.Amount are decimal places.
+const AmountDecimals = 8
+
+// Currency defines the metadata of a currency or asset DBCs can be issued
+// in.
+type Currency struct {
+	Code     string // ISO 4217 code or custom asset code
+	Decimals int    // decimal places of smallest unit (at most AmountDecimals)
+	Symbol   string // display symbol (optional)
+}
+
+// isoCurrencies contains the ISO 4217 currencies (with their minor units).
+// Precious metals have no minor units, they use AmountDecimals.
+var isoCurrencies = []Currency{
+	{"AED", 2, ""}, {"AFN", 2, "؋"}, {"ALL", 2, ""}, {"AMD", 2, "֏"},
+	{"ANG", 2, ""}, {"AOA", 2, ""}, {"ARS", 2, ""}, {"AUD", 2, "A$"},
+	{"AWG", 2, ""}, {"AZN", 2, "₼"}, {"BAM", 2, ""}, {"BBD", 2, ""},
+	{"BDT", 2, "৳"}, {"BGN", 2, ""}, {"BHD", 3, ""}, {"BIF", 0, ""},
+	{"BMD", 2, ""}, {"BND", 2, ""}, {"BOB", 2, ""}, {"BRL", 2, "R$"},
+	{"BSD", 2, ""}, {"BTN", 2, ""}, {"BWP", 2, ""}, {"BYN", 2, ""},
+	{"BZD", 2, ""}, {"CAD", 2, "CA$"}, {"CDF", 2, ""}, {"CHF", 2, ""},
+	{"CLF", 4, ""}, {"CLP", 0, ""}, {"CNY", 2, "CN¥"}, {"COP", 2, ""},
+	{"CRC", 2, "₡"}, {"CUP", 2, ""}, {"CVE", 2, ""}, {"CZK", 2, ""},
+	{"DJF", 0, ""}, {"DKK", 2, ""}, {"DOP", 2, ""}, {"DZD", 2, ""},
+	{"EGP", 2, ""}, {"ERN", 2, ""}, {"ETB", 2, ""}, {"EUR", 2, "€"},
+	{"FJD", 2, ""}, {"FKP", 2, ""}, {"GBP", 2, "£"}, {"GEL", 2, "₾"},
+	{"GHS", 2, "₵"}, {"GIP", 2, ""}, {"GMD", 2, ""}, {"GNF", 0, ""},
+	{"GTQ", 2, ""}, {"GYD", 2, ""}, {"HKD", 2, "HK$"}, {"HNL", 2, ""},
+	{"HTG", 2, ""}, {"HUF", 2, ""}, {"IDR", 2, ""}, {"ILS", 2, "₪"},
+	{"INR", 2, "₹"}, {"IQD", 3, ""}, {"IRR", 2, ""}, {"ISK", 0, ""},
+	{"JMD", 2, ""}, {"JOD", 3, ""}, {"JPY", 0, "¥"}, {"KES", 2, ""},
+	{"KGS", 2, ""}, {"KHR", 2, ""}, {"KMF", 0, ""}, {"KPW", 2, ""},
+	{"KRW", 0, "₩"}, {"KWD", 3, ""}, {"KYD", 2, ""}, {"KZT", 2, "₸"},
+	{"LAK", 2, ""}, {"LBP", 2, ""}, {"LKR", 2, ""}, {"LRD", 2, ""},
+	{"LSL", 2, ""}, {"LYD", 3, ""}, {"MAD", 2, ""}, {"MDL", 2, ""},
+	{"MGA", 2, ""}, {"MKD", 2, ""}, {"MMK", 2, ""}, {"MNT", 2, "₮"},
+	{"MOP", 2, ""}, {"MRU", 2, ""}, {"MUR", 2, ""}, {"MVR", 2, ""},
+	{"MWK", 2, ""}, {"MXN", 2, "MX$"}, {"MYR", 2, ""}, {"MZN", 2, ""},
+	{"NAD", 2, ""}, {"NGN", 2, "₦"}, {"NIO", 2, ""}, {"NOK", 2, ""},
+	{"NPR", 2, ""}, {"NZD", 2, "NZ$"}, {"OMR", 3, ""}, {"PAB", 2, ""},
+	{"PEN", 2, ""}, {"PGK", 2, ""}, {"PHP", 2, "₱"}, {"PKR", 2, ""},
+	{"PLN", 2, "zł"}, {"PYG", 0, "₲"}, {"QAR", 2, ""}, {"RON", 2, ""},
+	{"RSD", 2, ""}, {"RUB", 2, "₽"}, {"RWF", 0, ""}, {"SAR", 2, ""},
+	{"SBD", 2, ""}, {"SCR", 2, ""}, {"SDG", 2, ""}, {"SEK", 2, ""},
+	{"SGD", 2, ""}, {"SHP", 2, ""}, {"SLE", 2, ""}, {"SOS", 2, ""},
+	{"SRD", 2, ""}, {"SSP", 2, ""}, {"STN", 2, ""}, {"SVC", 2, ""},
+	{"SYP", 2, ""}, {"SZL", 2, ""}, {"THB", 2, "฿"}, {"TJS", 2, ""},
+	{"TMT", 2, ""}, {"TND", 3, ""}, {"TOP", 2, ""}, {"TRY", 2, "₺"},
+	{"TTD", 2, ""}, {"TWD", 2, "NT$"}, {"TZS", 2, ""}, {"UAH", 2, "₴"},
+	{"UGX", 0, ""}, {"USD", 2, "$"}, {"UYI", 0, ""}, {"UYU", 2, ""},
+	{"UYW", 4, ""}, {"UZS", 2, ""}, {"VED", 2, ""}, {"VES", 2, ""},
+	{"VND", 0, "₫"}, {"VUV", 0, ""}, {"WST", 2, ""}, {"XAF", 0, ""},
+	{"XCD", 2, ""}, {"XOF", 0, ""}, {"XPF", 0, ""}, {"YER", 2, ""},
+	{"ZAR", 2, ""}, {"ZMW", 2, ""}, {"ZWG", 2, ""},
+	// precious metals (troy ounce)
+	{"XAG", AmountDecimals, ""}, {"XAU", AmountDecimals, ""},
+	{"XPD", AmountDecimals, ""}, {"XPT", AmountDecimals, ""},
+}
+
+// assetCodeRegexp defines valid custom asset codes.
+var assetCodeRegexp = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,11}$`)
+
+// currencies is the currency registry.
+var currencies = struct {
+	sync.RWMutex
+	m map[string]Currency
+}{m: make(map[string]Currency)}
+
+func init() {
+	for _, c := range isoCurrencies {
+		currencies.m[c.Code] = c
+	}
+}
+
+// RegisterCurrency registers the custom asset c in the currency registry.
+// Asset codes consist of 2 to 12 uppercase letters and digits (starting with
+// a letter) and must not be registered already (which includes all ISO 4217
+// codes). Custom assets of a network are declared in its configuration
+// instead (see Network.CurrencyAdd), they are registered on load.
+func RegisterCurrency(c Currency) error {
+	if err := c.validateAsset(); err != nil {
+		return err
+	}
+	currencies.Lock()
+	defer currencies.Unlock()
+	if _, ok := currencies.m[c.Code]; ok {
+		return fmt.Errorf("netconf: currency %s already registered", c.Code)
+	}
+	currencies.m[c.Code] = c
+	return nil
+}
+
+// declareCurrency registers the custom asset c declared in a network
+// configuration. Declaring the same asset again has no effect, conflicting
+// definitions (which includes all ISO 4217 codes) are an error.
+func declareCurrency(c Currency) error {
+	if err := c.validateAsset(); err != nil {
+		return err
+	}
+	for _, iso := range isoCurrencies {
+		if iso.Code == c.Code {
+			return fmt.Errorf("%w: %s is an ISO 4217 code", ErrAssetInvalid, c.Code)
+		}
+	}
+	currencies.Lock()
+	defer currencies.Unlock()
+	if r, ok := currencies.m[c.Code]; ok {
+		if r != c {
+			return fmt.Errorf("%w: %s conflicts with registered currency", ErrAssetInvalid, c.Code)
+		}
+		return nil
+	}
+	currencies.m[c.Code] = c
+	return nil
+}
+
+// validateAsset makes sure c is a valid custom asset definition.
+func (c Currency) validateAsset() error {
+	if !assetCodeRegexp.MatchString(c.Code) {
+		return fmt.Errorf("%w: invalid asset code '%s'", ErrAssetInvalid, c.Code)
+	}
+	if c.Decimals < 0 || c.Decimals > AmountDecimals {
+		return fmt.Errorf("%w: %s: decimals must be between 0 and %d",
+			ErrAssetInvalid, c.Code, AmountDecimals)
+	}
+	return nil
+}
+
+// LookupCurrency returns the registered currency with the given code.
+func LookupCurrency(code string) (Currency, error) {
+	currencies.RLock()
+	defer currencies.RUnlock()
+	c, ok := currencies.m[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %s", ErrCurrencyUnknown, code)
+	}
+	return c, nil
+}
+
+// unit returns the number of amount units per smallest unit of c.
+func (c Currency) unit() uint64 {
+	u := uint64(1)
+	for i := c.Decimals; i < AmountDecimals; i++ {
+		u *= 10
+	}
+	return u
+}
+
+// FormatAmount formats amount (with AmountDecimals decimal places) as
+// decimal string with the decimal places of currency c, more decimal places
+// are only shown if necessary.
+func (c Currency) FormatAmount(amount uint64) string {
+	s := fmt.Sprintf("%d.%0*d", amount/1e8, AmountDecimals, amount%1e8)
+	// trim trailing zeros up to the decimal places of currency
+	i := strings.IndexByte(s, '.')
+	s = strings.TrimRight(s, "0")
+	if min := i + 1 + c.Decimals; len(s) < min {
+		s += strings.Repeat("0", min-len(s))
+	}
+	return strings.TrimSuffix(s, ".")
+}
+
+// ParseAmount parses the decimal string s (e.g., "1.50") as amount of
+// currency c. The amount must not have more decimal places than c.
+func (c Currency) ParseAmount(s string) (uint64, error) {
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" || strings.ContainsAny(intPart+fracPart, "+-") {
+		return 0, fmt.Errorf("netconf: cannot parse amount '%s'", s)
+	}
+	if len(strings.TrimRight(fracPart, "0")) > c.Decimals {
+		return 0, fmt.Errorf("%w: %s has %d decimal places: %s",
+			ErrAmountPrecision, c.Code, c.Decimals, s)
+	}
+	fracPart += strings.Repeat("0", AmountDecimals)
+	fracPart = fracPart[:AmountDecimals]
+	i, err := strconv.ParseUint(intPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("netconf: cannot parse amount '%s': %w", s, err)
+	}
+	f, err := strconv.ParseUint(fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("netconf: cannot parse amount '%s': %w", s, err)
+	}
+	if i > (^uint64(0)-f)/1e8 {
+		return 0, fmt.Errorf("netconf: amount '%s' too large", s)
+	}
+	return i*1e8 + f, nil
+}
+
+// ParseAmount parses a DBC type given as amount and currency code
+// separated by whitespace (e.g., "1.50 EUR").
+func ParseAmount(s string) (DBCType, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return DBCType{}, fmt.Errorf("netconf: cannot parse DBC type '%s' (expected 'amount currency')", s)
+	}
+	return ParseDBCType(fields[1], fields[0])
+}
+
+// ParseDBCType returns the DBC type for the given currency code and decimal
+// amount string (e.g., "EUR" and "1.50"). Amounts of unknown currencies are
+// parsed with AmountDecimals decimal places, use DBCType.Validate to make
+// sure the currency is registered.
+func ParseDBCType(currency, amount string) (DBCType, error) {
+	if currency == "" {
+		return DBCType{}, fmt.Errorf("netconf: DBC currency missing")
+	}
+	c := DBCType{Currency: currency}.currency()
+	a, err := c.ParseAmount(amount)
+	if err != nil {
+		return DBCType{}, err
+	}
+	if a == 0 {
+		return DBCType{}, fmt.Errorf("netconf: DBC amount must not be 0")
+	}
+	return DBCType{Currency: c.Code, Amount: a}, nil
+}
@@ -0,0 +1,121 @@
+package netconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	testCases := []struct {
+		s      string
+		dbc    DBCType
+		errStr bool
+	}{
+		{"1.50 EUR", DBCType{Currency: "EUR", Amount: 150000000}, false},
+		{"1 EUR", DBCType{Currency: "EUR", Amount: 100000000}, false},
+		{"0.01 EUR", DBCType{Currency: "EUR", Amount: 1000000}, false},
+		{"1.500 EUR", DBCType{Currency: "EUR", Amount: 150000000}, false},
+		{"100 JPY", DBCType{Currency: "JPY", Amount: 10000000000}, false},
+		{"0.00000001 XAU", DBCType{Currency: "XAU", Amount: 1}, false},
+		{"0.001 EUR", DBCType{}, true},
+		{"1.5 JPY", DBCType{}, true},
+		{"0 EUR", DBCType{}, true},
+		{"-1 EUR", DBCType{}, true},
+		{"1,50 EUR", DBCType{}, true},
+		{".5 EUR", DBCType{}, true},
+		{"EUR", DBCType{}, true},
+		{"184467440737.09551616 XAU", DBCType{}, true}, // overflow
+	}
+	for _, testCase := range testCases {
+		dbc, err := ParseAmount(testCase.s)
+		if testCase.errStr {
+			if err == nil {
+				t.Errorf("ParseAmount(%q) should fail", testCase.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseAmount(%q) failed: %v", testCase.s, err)
+			continue
+		}
+		if dbc != testCase.dbc {
+			t.Errorf("ParseAmount(%q) = %v, want %v", testCase.s, dbc, testCase.dbc)
+		}
+	}
+}
+
+func TestDBCTypeString(t *testing.T) {
+	testCases := []struct {
+		dbc     DBCType
+		str     string
+		display string
+	}{
+		{DBCType{Currency: "EUR", Amount: 150000000}, "1.50 EUR", "€1.50"},
+		{DBCType{Currency: "EUR", Amount: 100000000}, "1.00 EUR", "€1.00"},
+		{DBCType{Currency: "EUR", Amount: 150000}, "0.0015 EUR", "€0.0015"},
+		{DBCType{Currency: "JPY", Amount: 10000000000}, "100 JPY", "¥100"},
+		{DBCType{Currency: "CHF", Amount: 500000000}, "5.00 CHF", "5.00 CHF"},
+		{DBCType{Currency: "XYZ", Amount: 123456789}, "1.23456789 XYZ", "1.23456789 XYZ"},
+	}
+	for _, testCase := range testCases {
+		if s := testCase.dbc.String(); s != testCase.str {
+			t.Errorf("String() = %q, want %q", s, testCase.str)
+		}
+		if s := testCase.dbc.Display(); s != testCase.display {
+			t.Errorf("Display() = %q, want %q", s, testCase.display)
+		}
+		// round trip (only possible for amounts with valid precision)
+		if errors.Is(testCase.dbc.Validate(), ErrAmountPrecision) {
+			continue
+		}
+		dbc, err := ParseAmount(testCase.dbc.String())
+		if err != nil {
+			t.Error(err)
+		} else if dbc != testCase.dbc {
+			t.Errorf("ParseAmount(String()) = %v, want %v", dbc, testCase.dbc)
+		}
+	}
+}
+
+func TestDBCTypeValidate(t *testing.T) {
+	if err := (DBCType{Currency: "EUR", Amount: 100000000}).Validate(); err != nil {
+		t.Error(err)
+	}
+	err := DBCType{Currency: "EUR", Amount: 100000}.Validate()
+	if !errors.Is(err, ErrAmountPrecision) {
+		t.Errorf("Validate() should fail with ErrAmountPrecision, got: %v", err)
+	}
+	err = DBCType{Currency: "XYZ", Amount: 100000000}.Validate()
+	if !errors.Is(err, ErrCurrencyUnknown) {
+		t.Errorf("Validate() should fail with ErrCurrencyUnknown, got: %v", err)
+	}
+}
+
+func TestRegisterCurrency(t *testing.T) {
+	gold := Currency{Code: "GOLDG", Decimals: 3, Symbol: "g"}
+	if err := RegisterCurrency(gold); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterCurrency(gold); err == nil {
+		t.Error("RegisterCurrency() should fail for registered asset")
+	}
+	if err := RegisterCurrency(Currency{Code: "USD", Decimals: 2}); err == nil {
+		t.Error("RegisterCurrency() should fail for ISO 4217 code")
+	}
+	if err := RegisterCurrency(Currency{Code: "gold", Decimals: 2}); err == nil {
+		t.Error("RegisterCurrency() should fail for invalid code")
+	}
+	if err := RegisterCurrency(Currency{Code: "SILVER", Decimals: 9}); err == nil {
+		t.Error("RegisterCurrency() should fail for too many decimals")
+	}
+	dbc, err := ParseAmount("1.25 GOLDG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dbc.Validate(); err != nil {
+		t.Error(err)
+	}
+	if s := dbc.Display(); s != "g1.250" {
+		t.Errorf("Display() = %q", s)
+	}
+}
@@ -1,6 +1,7 @@
 package netconf
 
 import (
+	"fmt"
 	"sort"
 )
 
@@ -10,6 +11,49 @@ type DBCType struct {
 	Amount   uint64 // the amount per DBC, last 8 digits are decimal places
 }
 
+// currency returns the registered currency of the DBC type. Unknown
+// currencies are shown with all AmountDecimals decimal places.
+func (d DBCType) currency() Currency {
+	c, err := LookupCurrency(d.Currency)
+	if err != nil {
+		return Currency{Code: d.Currency, Decimals: AmountDecimals}
+	}
+	return c
+}
+
+// String returns the DBC type as decimal amount and currency code (e.g.,
+// "1.50 EUR"), the format parsed by ParseAmount.
+func (d DBCType) String() string {
+	return d.currency().FormatAmount(d.Amount) + " " + d.Currency
+}
+
+// Display returns the DBC type for humans, with the currency symbol instead of
+// the currency code, if the currency has one (e.g., "€1.50").
+func (d DBCType) Display() string {
+	c := d.currency()
+	if c.Symbol == "" {
+		return d.String()
+	}
+	return c.Symbol + c.FormatAmount(d.Amount)
+}
+
+// Validate makes sure the currency of the DBC type is registered and the
+// amount doesn't have more decimal places than the currency.
+func (d DBCType) Validate() error {
+	c, err := LookupCurrency(d.Currency)
+	if err != nil {
+		return err
+	}
+	if d.Amount == 0 {
+		return fmt.Errorf("netconf: DBC amount must not be 0")
+	}
+	if d.Amount%c.unit() != 0 {
+		return fmt.Errorf("%w: %s has %d decimal places: %d", ErrAmountPrecision,
+			c.Code, c.Decimals, d.Amount)
+	}
+	return nil
+}
+
 // DBCTypeMapToSortedArray takes a map of DBCTypes and converts it to a sorted
 // array.
 func DBCTypeMapToSortedArray(m map[DBCType]bool) []DBCType {
@@ -27,3 +71,13 @@ func DBCTypeMapToSortedArray(m map[DBCType]bool) []DBCType {
 	})
 	return dbcTypes
 }
+
+// DBCTypeCountsToSortedArray returns the DBC types counted in counts as a
+// sorted array.
+func DBCTypeCountsToSortedArray(counts map[DBCType]uint64) []DBCType {
+	m := make(map[DBCType]bool)
+	for t := range counts {
+		m[t] = true
+	}
+	return DBCTypeMapToSortedArray(m)
+}
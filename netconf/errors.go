@@ -45,3 +45,206 @@ var ErrDBCTypesOverlap = errors.New("netconf: the DBCTypesAdded and DBCTypesRemo
 
 // ErrNoURL is returned if a mint has no defined URL.
 var ErrNoURL = errors.New("netconf: mint has no defined URL")
+
+// ErrMintAlreadyAdded is returned if a mint is added which is already part of
+// the network.
+var ErrMintAlreadyAdded = errors.New("netconf: mint already added")
+
+// ErrMintNotAdded is returned if a mint is removed or replaced which is not
+// part of the network.
+var ErrMintNotAdded = errors.New("netconf: mint not added")
+
+// ErrDBCTypeAlreadyDefined is returned if a DBC type is added which is
+// already defined.
+var ErrDBCTypeAlreadyDefined = errors.New("netconf: DBC type already defined")
+
+// ErrDBCTypeNotDefined is returned if a DBC type is removed which is not
+// defined.
+var ErrDBCTypeNotDefined = errors.New("netconf: DBC type not defined")
+
+// ErrKeyReplacementSignature is returned if the signature of a mint identity
+// key replacement does not verify.
+var ErrKeyReplacementSignature = errors.New("netconf: key replacement signature does not verify")
+
+// ErrMintEpochsTooMany is returned if a mint has more epochs than the network.
+var ErrMintEpochsTooMany = errors.New("netconf: mint has more epochs than network")
+
+// ErrKeyListLength is returned if the length of a key list doesn't equal the
+// number of DBC types.
+var ErrKeyListLength = errors.New("netconf: lenght of key list doesn't equal number of DBC types")
+
+// ErrKeyCurrencyMismatch is returned if a key currency does not match the
+// DBC type.
+var ErrKeyCurrencyMismatch = errors.New("netconf: key currency does not match DBC type")
+
+// ErrKeyAmountMismatch is returned if a key amount does not match the DBC
+// type.
+var ErrKeyAmountMismatch = errors.New("netconf: key amount does not match DBC type")
+
+// ErrKeyListSignatures is returned if a key list has the wrong number of
+// signatures.
+var ErrKeyListSignatures = errors.New("netconf: wrong number of key list signatures")
+
+// ErrKeySignature is returned if a key signature of a key list doesn't verify.
+var ErrKeySignature = errors.New("netconf: key signature doesn't verify")
+
+// ErrIdentitySignature is returned if the identity key signature of a key
+// list doesn't verify.
+var ErrIdentitySignature = errors.New("netconf: identity key signature doesn't verify")
+
+// ErrSignStartMismatch is returned if the signing start of a mint epoch
+// doesn't match the network epoch.
+var ErrSignStartMismatch = errors.New("netconf: signing start mismatch")
+
+// ErrSignEndMismatch is returned if the signing end of a mint epoch doesn't
+// match the network epoch.
+var ErrSignEndMismatch = errors.New("netconf: signing end mismatch")
+
+// ErrValidateEndMismatch is returned if the validation end of a mint epoch
+// doesn't match the network epoch.
+var ErrValidateEndMismatch = errors.New("netconf: validation end mismatch")
+
+// ErrNoSigningEpoch is returned if no valid signing epoch exists.
+var ErrNoSigningEpoch = errors.New("netconf: no valid signing epoch found")
+
+// ErrNoQuorum is returned if not enough mints are available to reach the
+// quorum in the present.
+var ErrNoQuorum = errors.New("netconf: not enough mints to reach quorum in present")
+
+// ErrMintLoad is returned if a mint configuration cannot be loaded.
+var ErrMintLoad = errors.New("netconf: cannot load mint")
+
+// ErrFormerIdentity is returned if a mint epoch has been signed by an
+// identity key which was not replaced by the identity key of the mint.
+var ErrFormerIdentity = errors.New("netconf: key list signed by identity key not replaced by mint")
+
+// ErrPrivKeyMissing is returned if a private key is required, but missing.
+var ErrPrivKeyMissing = errors.New("netconf: private key missing")
+
+// ErrRevocationSignature is returned if the signature of a revocation does
+// not verify.
+var ErrRevocationSignature = errors.New("netconf: revocation signature does not verify")
+
+// ErrRevocationMint is returned if a revocation is for the wrong or an
+// unknown mint.
+var ErrRevocationMint = errors.New("netconf: revocation for wrong or unknown mint")
+
+// ErrRevocationEpoch is returned if a revocation refers to an undefined mint
+// epoch.
+var ErrRevocationEpoch = errors.New("netconf: revocation for undefined epoch")
+
+// ErrRevocationRecoveryEnd is returned if the recovery period of a revocation
+// ends before the compromise time.
+var ErrRevocationRecoveryEnd = errors.New("netconf: revocation recovery ends before compromise")
+
+// ErrRevoked is returned if the signing keys of a mint are revoked.
+var ErrRevoked = errors.New("netconf: mint signing keys revoked")
+
+// ErrKeyWindow is returned if the signing windows of the keys for a DBC type
+// do not cover the signing epoch without gaps or overlaps.
+var ErrKeyWindow = errors.New("netconf: key signing windows do not cover signing epoch")
+
+// ErrKeyListDecrypt is returned if a private key list cannot be decrypted.
+var ErrKeyListDecrypt = errors.New("netconf: cannot decrypt private key list")
+
+// ErrKeyListIdentity is returned if a private key list belongs to a different
+// identity key.
+var ErrKeyListIdentity = errors.New("netconf: private key list belongs to different identity")
+
+// ErrBoxKey is returned if a box key private key lists are sealed to is
+// invalid.
+var ErrBoxKey = errors.New("netconf: invalid box key")
+
+// ErrKeyListMismatch is returned if the private and the public key list of
+// a mint do not match.
+var ErrKeyListMismatch = errors.New("netconf: private and public key list do not match")
+
+// ErrDBCTypeMissing is returned if a key list has no keys for a DBC type of
+// the network.
+var ErrDBCTypeMissing = errors.New("netconf: key list misses DBC type")
+
+// ErrMintEpochsMissing is returned if a key list doesn't cover all epochs of
+// the network.
+var ErrMintEpochsMissing = errors.New("netconf: key list misses epochs")
+
+// ErrPrivKeyExpired is returned if a private key list contains private keys
+// whose validation period has ended.
+var ErrPrivKeyExpired = errors.New("netconf: expired private keys can be erased")
+
+// ErrConcurrentModification is returned if a configuration file has been
+// modified by another process while it was edited.
+var ErrConcurrentModification = errors.New("netconf: file modified concurrently")
+
+// ErrVersionUnsupported is returned if a file has a newer format version than
+// supported by this version of the software.
+var ErrVersionUnsupported = errors.New("netconf: file format version not supported")
+
+// ErrCurrencyUnknown is returned if a currency code is not in the currency
+// registry.
+var ErrCurrencyUnknown = errors.New("netconf: unknown currency")
+
+// ErrAssetInvalid is returned if the definition of a custom asset is invalid
+// or conflicts with a registered currency.
+var ErrAssetInvalid = errors.New("netconf: invalid custom asset")
+
+// ErrAmountPrecision is returned if an amount has more decimal places than
+// its currency.
+var ErrAmountPrecision = errors.New("netconf: amount has too many decimal places")
+
+// ErrDBCSeriesInvalid is returned if a denomination series scheme is invalid
+// or the series is empty.
+var ErrDBCSeriesInvalid = errors.New("netconf: invalid denomination series")
+
+// ErrLedgerSignature is returned if the signature of a DBC ledger does not
+// verify.
+var ErrLedgerSignature = errors.New("netconf: ledger signature does not verify")
+
+// ErrLedgerMint is returned if a DBC ledger is recorded by a mint which is
+// not part of the network in the ledger's epoch.
+var ErrLedgerMint = errors.New("netconf: ledger mint not in network epoch")
+
+// ErrLedgerEpoch is returned if a DBC ledger refers to the wrong epoch.
+var ErrLedgerEpoch = errors.New("netconf: ledger has wrong epoch")
+
+// ErrLedgerEntry is returned if a DBC ledger contains an invalid entry.
+var ErrLedgerEntry = errors.New("netconf: invalid ledger entry")
+
+// ErrLedgerDuplicate is returned if a ledger file contains more than one
+// ledger of the same mint.
+var ErrLedgerDuplicate = errors.New("netconf: duplicate ledger of mint")
+
+// ErrLedgerQuorum is returned if a ledger entry is not recorded by a quorum
+// of mints, it doesn't count towards the supply.
+var ErrLedgerQuorum = errors.New("netconf: ledger entry not recorded by quorum")
+
+// ErrLedgerConflict is returned if mints recorded different ledger entries
+// with the same ID.
+var ErrLedgerConflict = errors.New("netconf: conflicting ledger entries")
+
+// ErrSupplyNegative is returned if more DBCs of a DBC type have been destroyed
+// than created.
+var ErrSupplyNegative = errors.New("netconf: more DBCs destroyed than created")
+
+// ErrCommitmentLogSignature is returned if the signature of a commitment log
+// does not verify.
+var ErrCommitmentLogSignature = errors.New("netconf: commitment log signature does not verify")
+
+// ErrCommitmentLogEntry is returned if a commitment log contains an invalid
+// entry.
+var ErrCommitmentLogEntry = errors.New("netconf: invalid commitment log entry")
+
+// ErrCommitmentLogDuplicate is returned if more than one commitment log of
+// the same mint is audited.
+var ErrCommitmentLogDuplicate = errors.New("netconf: duplicate commitment log of mint")
+
+// ErrAuditImbalance is returned if the value of the outputs of a currency
+// exceeds the value of the inputs plus issuance minus redemption.
+var ErrAuditImbalance = errors.New("netconf: outputs exceed inputs plus issuance minus redemption")
+
+// ErrAuditDivergence is returned if the view of a mint diverges from the
+// view of the federation.
+var ErrAuditDivergence = errors.New("netconf: view of mint diverges from federation")
+
+// ErrAuditConflict is returned if mints recorded different spends of the same
+// DBC.
+var ErrAuditConflict = errors.New("netconf: conflicting spends of DBC")
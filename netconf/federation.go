@@ -1,111 +1,297 @@
 package netconf
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"log"
 	"os"
-	"path/filepath"
+	"path"
+	"time"
 
 	"github.com/frankbraun/codechain/secpkg"
-	"github.com/frankbraun/codechain/util/file"
 )
 
 // A Federation of Scrit mints.
 type Federation struct {
-	Network *Network         // the federation network
-	Mints   map[string]*Mint // all mints in the network
+	Network     *Network                 // the federation network
+	Mints       map[string]*Mint         // all mints in the network
+	Revocations map[string][]*Revocation // revocations of mint signing keys
+	Created     map[int][]*Ledger        // issuance ledgers by epoch
+	Destroyed   map[int][]*Ledger        // destruction ledgers by epoch
 }
 
-// upToDate ensures that the federation directory dir is up-to-date, if it
-// contains a .secpkg file.
-func upToDate(dir string) error {
-	fn := filepath.Join(dir, ".secpkg")
-	exists, err := file.Exists(fn)
-	if err != nil {
-		return err
+// Acceptance defines if a set of DBC signatures is acceptable.
+type Acceptance int
+
+// Acceptance values, see Federation.AcceptSignatures.
+const (
+	Reject         Acceptance = iota // signatures do not reach the quorum
+	AcceptRecovery                   // DBC can only be reissued (recovery)
+	Accept                           // signatures reach the quorum
+)
+
+// MintSignature describes a verified DBC signature of a mint.
+type MintSignature struct {
+	MintID string    // ID of mint identity key
+	Time   time.Time // signing time
+	Rolled bool      // signing key has been rolled (see MintEpoch.Rolled)
+}
+
+// revocation returns the revocation which revokes the signing keys of the
+// mint with the given ID for the given epoch at time t, or nil.
+func (f *Federation) revocation(mintID string, epoch int, t time.Time) *Revocation {
+	for _, r := range f.Revocations[mintID] {
+		if r.Revokes(epoch, t) {
+			return r
+		}
 	}
-	if !exists {
-		return nil // nothing to do
+	return nil
+}
+
+// Revoked returns true, if the signing keys of the mint with the given ID are
+// revoked for the given epoch at time t.
+func (f *Federation) Revoked(mintID string, epoch int, t time.Time) bool {
+	return f.revocation(mintID, epoch, t) != nil
+}
+
+// AcceptSignatures decides at time t if the DBC signatures sigs from the
+// signing keys of mints for the given epoch are acceptable. The caller must
+// have verified the signatures. Signatures only count, if they have been made
+// in the given signing epoch (see Network.SigningEpochAt) and not after t.
+// Signatures from revoked keys do not count towards the quorum, except during
+// the recovery period of the revocation, where they allow the DBC to be
+// reissued. Signatures from rolled keys allow the DBC to be reissued as long
+// as the epoch validates. The signing time is claimed by the signer,
+// therefore revocations and rolls are checked at time t.
+func (f *Federation) AcceptSignatures(epoch int, sigs []MintSignature, t time.Time) Acceptance {
+	if epoch < 0 || epoch >= len(f.Network.NetworkEpochs) {
+		return Reject
 	}
-	pkg, err := secpkg.Load(fn)
+	var valid, recovery uint64
+	seen := make(map[string]bool)
+	for _, s := range sigs {
+		if seen[s.MintID] {
+			continue
+		}
+		if _, ok := f.Mints[s.MintID]; !ok {
+			continue
+		}
+		if e, err := f.Network.SigningEpochAt(s.Time); err != nil || e != epoch || s.Time.After(t) {
+			continue // not signed in epoch
+		}
+		seen[s.MintID] = true
+		r := f.revocation(s.MintID, epoch, t)
+		switch {
+		case r == nil && !s.Rolled:
+			valid++
+		case r == nil || t.Before(r.RecoveryEnd):
+			recovery++
+		}
+	}
+	q := f.Network.NetworkEpochs[epoch].QuorumM
+	if valid >= q {
+		return Accept
+	}
+	if valid+recovery >= q {
+		return AcceptRecovery
+	}
+	return Reject
+}
+
+// upToDate ensures that the federation in fsys is up-to-date, if it contains
+// a .secpkg file.
+func upToDate(fsys fs.FS) error {
+	data, err := fs.ReadFile(fsys, secpkg.File)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // nothing to do
+		}
+		return err
+	}
+	var pkg secpkg.Package
+	if err := json.Unmarshal(data, &pkg); err != nil {
 		return err
 	}
 	return secpkg.UpToDate(pkg.Name)
 }
 
-func (f *Federation) validate() error {
+// validate the federation at time t and add all found issues to the report r.
+func (f *Federation) validate(r *ValidationReport, t time.Time) {
 	// for every mint we make sure the mint epochs match with the network epoch
-	for _, m := range f.Mints {
+	for id, m := range f.Mints {
 		for i, e := range m.MintEpochs {
+			if i >= len(f.Network.NetworkEpochs) {
+				break // reported by Mint.Validate
+			}
 			if e.SignStart != f.Network.NetworkEpochs[i].SignStart {
-				return errors.New("netconf: signing start mismatch")
+				r.addError(i, id, ErrSignStartMismatch)
 			}
 			if e.SignEnd != f.Network.NetworkEpochs[i].SignEnd {
-				return errors.New("netconf: signing end mismatch")
+				r.addError(i, id, ErrSignEndMismatch)
 			}
 			if e.ValidateEnd != f.Network.NetworkEpochs[i].ValidateEnd {
-				return errors.New("netconf: validation end mismatch")
+				r.addError(i, id, ErrValidateEndMismatch)
 			}
 		}
 	}
 
-	// now we make sure that in the present we have enough mint epochs (quorum)
-	i, err := f.Network.CurrentEpoch()
-	if err != nil {
-		return err
+	// now we make sure that at time t we have enough mint epochs (quorum).
+	// If the network didn't start yet, we check the first epoch.
+	var i int
+	if len(f.Network.NetworkEpochs) > 0 &&
+		t.Before(f.Network.NetworkEpochs[0].SignStart) {
+		i = 0
+	} else {
+		var err error
+		i, err = f.Network.SigningEpochAt(t)
+		if err != nil {
+			r.addError(NoEpoch, "", err)
+			return
+		}
 	}
 	var q uint64
-	for _, m := range f.Mints {
+	for id, m := range f.Mints {
+		if f.Revoked(id, i, t) {
+			r.addWarning(i, id, ErrRevoked)
+			continue
+		}
 		if len(m.MintEpochs) > i {
 			q++
 		}
 	}
 	if q < f.Network.NetworkEpochs[i].QuorumM {
-		return errors.New("netconf: not enough mints to reach quorum in present")
+		r.addError(i, "", ErrNoQuorum)
 	}
+}
 
-	return nil
+// LoadOptions defines the options for LoadFederationFS.
+type LoadOptions struct {
+	Logger       *log.Logger // progress is logged here, nothing is logged if nil
+	SkipUpToDate bool        // skip the Codechain .secpkg up-to-date check
+	At           time.Time   // evaluate federation at this time (zero: now)
 }
 
 // LoadFederation loads a Scrit mint federation configuration from the given
-// directory and validates it.
+// directory and validates it. Progress is printed to stdout and mints which
+// cannot be loaded are reported as warnings on stderr.
 func LoadFederation(dir string) (*Federation, error) {
-	var f Federation
-	filename := filepath.Join(dir, DefNetConfFile)
-	fmt.Printf("loading '%s'\n", filename)
-	n, err := LoadNetwork(filename)
+	return loadFederation(dir, time.Time{})
+}
+
+// loadFederation loads the federation in dir like LoadFederation, evaluated at
+// time at (zero: now).
+func loadFederation(dir string, at time.Time) (*Federation, error) {
+	opts := &LoadOptions{Logger: log.New(os.Stdout, "", 0), At: at}
+	f, r, err := LoadFederationFS(os.DirFS(dir), opts)
 	if err != nil {
 		return nil, err
 	}
-	if err := upToDate(dir); err != nil {
-		return nil, err
+	for _, i := range r.Issues {
+		if i.Severity == SeverityWarning {
+			fmt.Fprintf(os.Stderr, "WARNING %s\n", i.String())
+		}
 	}
-	fmt.Printf("validate '%s'\n", filename)
-	if err := n.Validate(); err != nil {
+	if err := r.Err(); err != nil {
 		return nil, err
 	}
+	return f, nil
+}
+
+// LoadFederationReport loads a Scrit mint federation configuration from the
+// given directory and validates it, see LoadFederationFS. Nothing is printed.
+func LoadFederationReport(dir string) (*Federation, *ValidationReport, error) {
+	return LoadFederationFS(os.DirFS(dir), nil)
+}
+
+// LoadFederationFS loads a Scrit mint federation configuration from the file
+// system fsys and validates it. Instead of stopping at the first problem it
+// returns a report listing all issues. Mints which cannot be loaded or
+// validated are not part of the returned federation and reported as warnings
+// (wrapping ErrMintLoad, if the mint configuration could not be read).
+// An error is only returned if the network configuration cannot be loaded at
+// all. If opts is nil, the default options are used.
+func LoadFederationFS(fsys fs.FS, opts *LoadOptions) (*Federation, *ValidationReport, error) {
+	if opts == nil {
+		opts = &LoadOptions{}
+	}
+	logf := func(format string, v ...interface{}) {
+		if opts.Logger != nil {
+			opts.Logger.Printf(format, v...)
+		}
+	}
+	var f Federation
+	logf("loading '%s'", DefNetConfFile)
+	n, err := loadNetworkFS(fsys, DefNetConfFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !opts.SkipUpToDate {
+		if err := upToDate(fsys); err != nil {
+			return nil, nil, err
+		}
+	}
+	logf("validate '%s'", DefNetConfFile)
+	r := n.Report()
 	f.Network = n
 	f.Mints = make(map[string]*Mint)
 
-	// we try to load all mints ever known, but ignore errors.
+	// we try to load all mints ever known, but only warn about errors.
 	// f.validate() later checks that we have enough mints in the current signing
 	// epoch available
 	for mn := range n.AllMints() {
-		filename := filepath.Join(dir, DefMintDir, mn+".json")
-		m, err := LoadMint(filename)
+		filename := path.Join(DefMintDir, mn+".json")
+		logf("loading '%s'", filename)
+		m, err := loadMintFS(fsys, filename)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "WARNING loading '%s' failed: %s\n", filename, err)
+			r.addWarning(NoEpoch, mn, fmt.Errorf("%w '%s': %v", ErrMintLoad, filename, err))
 			continue
 		}
-		if err := m.Validate(n); err != nil {
-			fmt.Fprintf(os.Stderr, "WARNING validating '%s' failed: %s\n", filename, err)
+		mr := m.Report(n)
+		if mr.HasErrors() {
+			r.merge(mr, SeverityWarning)
 			continue
 		}
 		f.Mints[mn] = m
 	}
-	if err := f.validate(); err != nil {
-		return nil, err
+
+	// load revocations, they are optional
+	f.Revocations = make(map[string][]*Revocation)
+	for mn := range n.AllMints() {
+		filename := path.Join(DefRevocationDir, mn+".json")
+		revs, err := loadRevocationsFS(fsys, filename)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				r.addError(NoEpoch, mn, fmt.Errorf("netconf: cannot load revocations '%s': %w", filename, err))
+			}
+			continue
+		}
+		logf("loaded '%s'", filename)
+		for _, rev := range revs {
+			if m, ok := f.Mints[mn]; ok {
+				err = rev.Validate(m)
+			} else if rev.MintIdentityKey.MarshalID() != mn {
+				err = fmt.Errorf("%w: %s", ErrRevocationMint, rev.MintIdentityKey.MarshalID())
+			} else {
+				err = rev.Verify()
+			}
+			if err != nil {
+				r.addError(NoEpoch, mn, err)
+				continue
+			}
+			f.Revocations[mn] = append(f.Revocations[mn], rev)
+		}
+	}
+
+	// load issuance and destruction ledgers, they are optional
+	f.Created = loadAllLedgersFS(fsys, n, LedgerCreate, r)
+	f.Destroyed = loadAllLedgersFS(fsys, n, LedgerDestroyed, r)
+
+	at := opts.At
+	if at.IsZero() {
+		at = time.Now()
 	}
-	return &f, nil
+	f.validate(r, at)
+	return &f, r, nil
 }
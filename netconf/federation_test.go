@@ -1,21 +1,75 @@
 package netconf
 
 import (
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/scritcash/scrit/util/def"
 )
 
+// testNow is a point in time during the first signing epoch of testdata.
+func testNow() time.Time {
+	return time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+}
+
 func TestLoadFederation(t *testing.T) {
-	_, err := LoadFederation("testdata")
+	_, err := loadFederation("testdata", testNow())
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
+func TestLoadFederationFS(t *testing.T) {
+	// load testdata into memory, but leave out one mint
+	fsys := make(fstest.MapFS)
+	missing := "mints/ed25519-ECNmyLJ2ESzYmzX8nLE6_zXML_DSK4XXsvZ5KU88aYE.json"
+	err := fs.WalkDir(os.DirFS("testdata"), ".",
+		func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || path == missing {
+				return err
+			}
+			data, err := ioutil.ReadFile(filepath.Join("testdata", path))
+			if err != nil {
+				return err
+			}
+			fsys[path] = &fstest.MapFile{Data: data}
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, r, err := LoadFederationFS(fsys, &LoadOptions{At: testNow()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.HasErrors() {
+		t.Errorf("unexpected errors:\n%s", r.Marshal())
+	}
+	if len(f.Mints) != 2 {
+		t.Errorf("len(f.Mints) == %d != 2", len(f.Mints))
+	}
+	var found bool
+	for _, i := range r.Issues {
+		if i.Code == "ErrMintLoad" {
+			if i.Severity != SeverityWarning {
+				t.Errorf("mint load issue has severity %s", i.Severity)
+			}
+			if i.MintID != "ed25519-ECNmyLJ2ESzYmzX8nLE6_zXML_DSK4XXsvZ5KU88aYE" {
+				t.Errorf("mint load issue for wrong mint: %s", i.MintID)
+			}
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("missing mint not reported:\n%s", r.Marshal())
+	}
+}
+
 func TestFederation(t *testing.T) {
 	tmpdir, err := ioutil.TempDir("", "scrit_federation_test")
 	if err != nil {
@@ -0,0 +1,22 @@
+package netconf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// readFile reads the JSON file filename. If a previous save of filename was
+// interrupted, the file is recovered first (see atomicfile.Recover).
+func readFile(filename string) ([]byte, error) {
+	restored, err := atomicfile.Recover(filename, json.Valid)
+	if err != nil {
+		return nil, err
+	}
+	if restored {
+		log.Printf("recovered '%s' from interrupted save", filename)
+	}
+	return ioutil.ReadFile(filename)
+}
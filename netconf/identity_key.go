@@ -33,6 +33,9 @@ func NewIdentityKey() (*IdentityKey, error) {
 func ParseIdentityKey(iks string) (*IdentityKey, error) {
 	var ik IdentityKey
 	parts := strings.SplitN(iks, "-", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("netconf: cannot parse identity key '%s'", iks)
+	}
 	ik.SigAlgo = parts[0]
 	pk, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
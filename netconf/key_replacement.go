@@ -23,6 +23,16 @@ func NewKeyReplacement(newKey, oldKey *IdentityKey, sig string) *KeyReplacement
 	}
 }
 
+// SignKeyReplacement returns a new key replacement from oldKey to newKey
+// signed by oldKey, which must contain the private key.
+func SignKeyReplacement(newKey, oldKey *IdentityKey) (*KeyReplacement, error) {
+	if len(oldKey.privKey) != ed25519.PrivateKeySize {
+		return nil, ErrPrivKeyMissing
+	}
+	sig := ed25519.Sign(oldKey.privKey, []byte(newKey.MarshalID()))
+	return NewKeyReplacement(newKey, oldKey, base64.RawURLEncoding.EncodeToString(sig)), nil
+}
+
 // Verify the signature of the given key replacement
 func (r *KeyReplacement) Verify() error {
 	sig, err := base64.RawURLEncoding.DecodeString(r.Signature)
@@ -30,7 +40,7 @@ func (r *KeyReplacement) Verify() error {
 		return err
 	}
 	if !ed25519.Verify(r.OldKey.PubKey, []byte(r.NewKey.MarshalID()), sig) {
-		return fmt.Errorf("netconf: signature '%s' does not verify", sig)
+		return fmt.Errorf("%w: '%s'", ErrKeyReplacementSignature, r.Signature)
 	}
 	return nil
 }
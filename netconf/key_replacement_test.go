@@ -0,0 +1,37 @@
+package netconf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignKeyReplacement(t *testing.T) {
+	newKey, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := SignKeyReplacement(newKey, &identityKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Verify(); err != nil {
+		t.Error(err)
+	}
+	// signature must not verify for another key
+	otherKey, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.NewKey = *otherKey
+	if err := r.Verify(); !errors.Is(err, ErrKeyReplacementSignature) {
+		t.Errorf("Verify() should fail with %v (has %v)", ErrKeyReplacementSignature, err)
+	}
+	// signing requires the private key of the old identity key
+	pubKey, err := ParseIdentityKey(marshalledIdentityKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SignKeyReplacement(newKey, pubKey); err != ErrPrivKeyMissing {
+		t.Errorf("SignKeyReplacement() should fail with %v (has %v)", ErrPrivKeyMissing, err)
+	}
+}
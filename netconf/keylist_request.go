@@ -0,0 +1,229 @@
+package netconf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// KeyListRequest is a request to sign the key lists of a mint with the
+// identity key, which allows to keep the identity key on an offline host.
+// The payload of every mint epoch is the encoding the identity key signs.
+type KeyListRequest struct {
+	MintIdentityKey IdentityKey  // identity key of mint
+	Start           int          // index of first mint epoch to sign
+	MintEpochs      []*MintEpoch // mint epochs to sign (without private keys)
+	Payloads        [][]byte     // encodings of mint epochs to sign
+}
+
+// KeyListResponse contains the identity signatures for a KeyListRequest.
+type KeyListResponse struct {
+	MintIdentityKey IdentityKey // identity key of mint
+	Start           int         // index of first signed mint epoch
+	Signatures      [][]byte    // identity signatures of payloads
+}
+
+// PrepareMint creates a new Scrit mint like NewMint, but for an identity key
+// ik without private key. The key lists are only signed with the signing
+// keys, the returned request has to be signed with the identity key
+// and the response added with Finalize.
+func PrepareMint(
+	description string,
+	ik *IdentityKey,
+	urls []string,
+	n *Network,
+) (*Mint, *KeyListRequest, error) {
+	m, err := newMint(description, ik, urls, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := m.prepare(0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, r, nil
+}
+
+// PrepareExtend extends the mint's key list for the given network like
+// Extend, but without the private identity key. If there is nothing to
+// extend, nil is returned. See PrepareMint.
+func (m *Mint) PrepareExtend(n *Network) (*KeyListRequest, error) {
+	start, err := m.extend(n)
+	if err != nil {
+		return nil, err
+	}
+	if start >= len(m.MintEpochs) {
+		return nil, nil // nothing to extend
+	}
+	return m.prepare(start)
+}
+
+// prepare signs all mint epochs from start on with the signing keys only and
+// returns the corresponding request.
+func (m *Mint) prepare(start int) (*KeyListRequest, error) {
+	s := NewMintSigner(&m.MintIdentityKey, m)
+	r := &KeyListRequest{
+		MintIdentityKey: IdentityKey{
+			SigAlgo: m.MintIdentityKey.SigAlgo,
+			PubKey:  m.MintIdentityKey.PubKey,
+		},
+		Start: start,
+	}
+	for _, e := range m.MintEpochs[start:] {
+		enc, err := e.signKeys(&m.MintIdentityKey, s)
+		if err != nil {
+			return nil, err
+		}
+		r.MintEpochs = append(r.MintEpochs, e.public())
+		r.Payloads = append(r.Payloads, enc)
+	}
+	return r, nil
+}
+
+// public returns a copy of the mint epoch without private keys.
+func (me *MintEpoch) public() *MintEpoch {
+	e := &MintEpoch{
+		SignStart:         me.SignStart,
+		SignEnd:           me.SignEnd,
+		ValidateEnd:       me.ValidateEnd,
+		KeyListSignatures: me.KeyListSignatures,
+		SignedBy:          me.SignedBy,
+	}
+	for _, k := range me.KeyList {
+		pk := *k
+		pk.PrivKey = nil
+		e.KeyList = append(e.KeyList, &pk)
+	}
+	return e
+}
+
+// Verify that the payloads of the request are the encodings of the
+// contained mint epochs and that these are signed by the signing keys.
+func (r *KeyListRequest) Verify() error {
+	if len(r.MintEpochs) != len(r.Payloads) {
+		return fmt.Errorf("netconf: key list request has %d epochs, but %d payloads",
+			len(r.MintEpochs), len(r.Payloads))
+	}
+	for i, e := range r.MintEpochs {
+		enc, err := e.encode(&r.MintIdentityKey)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(enc, r.Payloads[i]) {
+			return fmt.Errorf("netconf: payload %d doesn't match mint epoch",
+				r.Start+i)
+		}
+		if len(e.KeyListSignatures) != len(e.KeyList) {
+			return ErrKeyListSignatures
+		}
+		for j, k := range e.KeyList {
+			if !ed25519.Verify(k.PubKey, enc, e.KeyListSignatures[j]) {
+				return ErrKeySignature
+			}
+		}
+	}
+	return nil
+}
+
+// Sign the request with the private identity key ik.
+func (r *KeyListRequest) Sign(ik *IdentityKey) (*KeyListResponse, error) {
+	if ik.MarshalID() != r.MintIdentityKey.MarshalID() {
+		return nil, ErrKeyListIdentity
+	}
+	if len(ik.privKey) != ed25519.PrivateKeySize {
+		return nil, ErrPrivKeyMissing
+	}
+	if err := r.Verify(); err != nil {
+		return nil, err
+	}
+	resp := &KeyListResponse{
+		MintIdentityKey: r.MintIdentityKey,
+		Start:           r.Start,
+	}
+	for _, p := range r.Payloads {
+		resp.Signatures = append(resp.Signatures, ed25519.Sign(ik.privKey, p))
+	}
+	return resp, nil
+}
+
+// Response returns the response for the request with the identity signatures
+// sigs (one per payload) created elsewhere, for example by a threshold
+// signing ceremony. The signatures are verified with the public identity
+// key.
+func (r *KeyListRequest) Response(sigs [][]byte) (*KeyListResponse, error) {
+	if err := r.Verify(); err != nil {
+		return nil, err
+	}
+	if len(sigs) != len(r.Payloads) {
+		return nil, fmt.Errorf("netconf: %d signatures for %d payloads",
+			len(sigs), len(r.Payloads))
+	}
+	if len(r.MintIdentityKey.PubKey) != ed25519.PublicKeySize {
+		return nil, ErrKeyListIdentity
+	}
+	for i, sig := range sigs {
+		if !ed25519.Verify(r.MintIdentityKey.PubKey, r.Payloads[i], sig) {
+			return nil, fmt.Errorf("%w: payload %d", ErrKeySignature, r.Start+i)
+		}
+	}
+	return &KeyListResponse{
+		MintIdentityKey: r.MintIdentityKey,
+		Start:           r.Start,
+		Signatures:      sigs,
+	}, nil
+}
+
+// Finalize adds the identity signatures contained in the response to the
+// prepared mint epochs.
+func (m *Mint) Finalize(resp *KeyListResponse) error {
+	ik := &m.MintIdentityKey
+	if resp.MintIdentityKey.MarshalID() != ik.MarshalID() {
+		return ErrKeyListIdentity
+	}
+	if resp.Start < 0 || resp.Start+len(resp.Signatures) != len(m.MintEpochs) {
+		return fmt.Errorf("netconf: key list response for epochs %d to %d does not match",
+			resp.Start, resp.Start+len(resp.Signatures)-1)
+	}
+	for i, sig := range resp.Signatures {
+		e := m.MintEpochs[resp.Start+i]
+		if len(e.KeyListSignatures) != len(e.KeyList) {
+			return fmt.Errorf("netconf: mint epoch %d is not prepared", resp.Start+i)
+		}
+		enc, err := e.encode(ik)
+		if err != nil {
+			return err
+		}
+		if err := e.addIdentitySignature(ik, enc, sig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadKeyListRequest loads a key list request from filename.
+func LoadKeyListRequest(filename string) (*KeyListRequest, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var r KeyListRequest
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// LoadKeyListResponse loads a key list response from filename.
+func LoadKeyListResponse(filename string) (*KeyListResponse, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var r KeyListResponse
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,95 @@
+package netconf
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/scritcash/scrit/util/def"
+)
+
+func TestKeyListRequest(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParseIdentityKey(ik.MarshalID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 200000000})
+
+	// prepare new mint without private identity key
+	m, req, err := PrepareMint("mint", pub, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(net); !errors.Is(err, ErrKeyListSignatures) {
+		t.Errorf("prepared mint should fail with ErrKeyListSignatures, got: %v", err)
+	}
+	if len(req.Payloads) != 1 {
+		t.Fatalf("request should contain 1 payload, has %d", len(req.Payloads))
+	}
+	for _, k := range req.MintEpochs[0].KeyList {
+		if k.PrivKey != nil {
+			t.Error("request must not contain private keys")
+		}
+	}
+	if _, err := req.Sign(other); !errors.Is(err, ErrKeyListIdentity) {
+		t.Errorf("Sign() should fail with ErrKeyListIdentity, got: %v", err)
+	}
+	resp, err := req.Sign(ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Finalize(resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(net); err != nil {
+		t.Error(err)
+	}
+
+	// extend key list
+	net.EpochAdd(def.SigningPeriod, def.ValidationPeriod)
+	req, err = m.PrepareExtend(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Start != 1 || len(req.Payloads) != 1 {
+		t.Fatalf("request should start at epoch 1 with 1 payload: %d, %d",
+			req.Start, len(req.Payloads))
+	}
+
+	// manipulated payload
+	req.Payloads[0][0] ^= 1
+	if _, err := req.Sign(ik); err == nil {
+		t.Error("Sign() should fail for manipulated payload")
+	}
+	req.Payloads[0][0] ^= 1
+	resp, err = req.Sign(ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// corrupted identity signature
+	bad, err := req.Sign(ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad.Signatures[0][0] ^= 1
+	if err := m.Finalize(bad); !errors.Is(err, ErrIdentitySignature) {
+		t.Errorf("Finalize() should fail with ErrIdentitySignature, got: %v", err)
+	}
+	if err := m.Finalize(resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(net); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,150 @@
+package netconf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+)
+
+// KeyListStatus compares the private key list priv of a mint with its public
+// key list pub epoch by epoch, verifies all key list signatures in both, and
+// checks them against the network net at time t. All found issues are
+// returned in the report. Private keys of epochs whose validation period has
+// ended at time t are reported as warnings (ErrPrivKeyExpired), they are not
+// needed anymore and can be erased.
+func KeyListStatus(priv, pub *Mint, net *Network, t time.Time) *ValidationReport {
+	var r ValidationReport
+	id := priv.MintIdentityKey.MarshalID()
+	if pub.MintIdentityKey.MarshalID() != id {
+		r.addError(NoEpoch, id, fmt.Errorf("%w: identity key %s",
+			ErrKeyListMismatch, pub.MintIdentityKey.MarshalID()))
+		return &r
+	}
+	if len(priv.MintEpochs) != len(pub.MintEpochs) {
+		r.addError(NoEpoch, id, fmt.Errorf("%w: %d private and %d public epochs",
+			ErrKeyListMismatch, len(priv.MintEpochs), len(pub.MintEpochs)))
+	}
+	if len(priv.MintEpochs) < len(net.NetworkEpochs) {
+		r.addWarning(NoEpoch, id, fmt.Errorf("%w: %d of %d",
+			ErrMintEpochsMissing, len(priv.MintEpochs), len(net.NetworkEpochs)))
+	}
+	for i, e := range priv.MintEpochs {
+		if i < len(pub.MintEpochs) {
+			if err := e.compare(pub.MintEpochs[i]); err != nil {
+				r.addError(i, id, err)
+			}
+		}
+		e.verifySignatures(&r, i, &priv.MintIdentityKey, "private")
+		if i < len(pub.MintEpochs) {
+			pub.MintEpochs[i].verifySignatures(&r, i, &pub.MintIdentityKey, "public")
+		}
+		if i < len(net.NetworkEpochs) {
+			for _, dbc := range DBCTypeMapToSortedArray(net.DBCTypesAt(i)) {
+				if len(e.SigningKeys(dbc)) == 0 {
+					r.addError(i, id, fmt.Errorf("%w: %s %d",
+						ErrDBCTypeMissing, dbc.Currency, dbc.Amount))
+				}
+			}
+		}
+		e.checkPrivKeys(&r, i, id, t)
+	}
+	return &r
+}
+
+// compare the public parts of the mint epoch with the mint epoch pub.
+func (me *MintEpoch) compare(pub *MintEpoch) error {
+	if !me.SignStart.Equal(pub.SignStart) || !me.SignEnd.Equal(pub.SignEnd) ||
+		!me.ValidateEnd.Equal(pub.ValidateEnd) {
+		return fmt.Errorf("%w: epoch boundaries differ", ErrKeyListMismatch)
+	}
+	if (me.SignedBy == nil) != (pub.SignedBy == nil) ||
+		me.SignedBy != nil && me.SignedBy.MarshalID() != pub.SignedBy.MarshalID() {
+		return fmt.Errorf("%w: signing identity keys differ", ErrKeyListMismatch)
+	}
+	if len(me.KeyList) != len(pub.KeyList) {
+		return fmt.Errorf("%w: %d private and %d public keys",
+			ErrKeyListMismatch, len(me.KeyList), len(pub.KeyList))
+	}
+	for i, k := range me.KeyList {
+		p := pub.KeyList[i]
+		start, end := k.Window(me)
+		pstart, pend := p.Window(pub)
+		if k.DBCType() != p.DBCType() || k.SigAlgo != p.SigAlgo ||
+			!bytes.Equal(k.PubKey, p.PubKey) ||
+			!start.Equal(pstart) || !end.Equal(pend) {
+			return fmt.Errorf("%w: key %d differs", ErrKeyListMismatch, i)
+		}
+	}
+	if len(me.KeyListSignatures) != len(pub.KeyListSignatures) {
+		return fmt.Errorf("%w: number of signatures differs", ErrKeyListMismatch)
+	}
+	for i, sig := range me.KeyListSignatures {
+		if !bytes.Equal(sig, pub.KeyListSignatures[i]) {
+			return fmt.Errorf("%w: signature %d differs", ErrKeyListMismatch, i)
+		}
+	}
+	return nil
+}
+
+// verifySignatures verifies every key list signature of the mint epoch
+// separately and adds an error to r for each one which doesn't verify.
+func (me *MintEpoch) verifySignatures(
+	r *ValidationReport,
+	epoch int,
+	ik *IdentityKey,
+	list string,
+) {
+	id := ik.MarshalID()
+	enc, err := me.encode(ik)
+	if err != nil {
+		r.addError(epoch, id, err)
+		return
+	}
+	if len(me.KeyListSignatures) != len(me.KeyList)+1 {
+		r.addError(epoch, id, fmt.Errorf("%w: %s key list has %d for %d keys",
+			ErrKeyListSignatures, list, len(me.KeyListSignatures), len(me.KeyList)))
+	}
+	for i, sig := range me.KeyListSignatures {
+		switch {
+		case i < len(me.KeyList):
+			if !ed25519.Verify(me.KeyList[i].PubKey, enc, sig) {
+				r.addError(epoch, id, fmt.Errorf("%w: %s key list, key %d",
+					ErrKeySignature, list, i))
+			}
+		case i == len(me.KeyList):
+			if !ed25519.Verify(me.identity(ik).PubKey, enc, sig) {
+				r.addError(epoch, id, fmt.Errorf("%w: %s key list",
+					ErrIdentitySignature, list))
+			}
+		}
+	}
+}
+
+// checkPrivKeys checks the private keys of the mint epoch at time t. They
+// must be present and match the public keys, unless the validation period
+// has ended.
+func (me *MintEpoch) checkPrivKeys(r *ValidationReport, epoch int, id string, t time.Time) {
+	expired := !t.Before(me.ValidateEnd)
+	var erasable int
+	for i, k := range me.KeyList {
+		if len(k.PrivKey) == 0 {
+			if !expired {
+				r.addError(epoch, id, fmt.Errorf("%w: key %d", ErrPrivKeyMissing, i))
+			}
+			continue
+		}
+		if len(k.PrivKey) != ed25519.PrivateKeySize ||
+			!bytes.Equal(k.PrivKey.Public().(ed25519.PublicKey), k.PubKey) {
+			r.addError(epoch, id, fmt.Errorf("%w: private key %d doesn't match public key",
+				ErrKeyListMismatch, i))
+			continue
+		}
+		if expired {
+			erasable++
+		}
+	}
+	if erasable > 0 {
+		r.addWarning(epoch, id, fmt.Errorf("%w: %d keys", ErrPrivKeyExpired, erasable))
+	}
+}
@@ -0,0 +1,88 @@
+package netconf
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/util/def"
+)
+
+// hasIssue returns true, if the report contains an issue for err with the
+// given severity.
+func hasIssue(r *ValidationReport, s Severity, err error) bool {
+	for _, i := range r.Issues {
+		if i.Severity == s && errors.Is(i.Err(), err) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestKeyListStatus(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	priv, err := NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// public copy
+	copyMint := func() *Mint {
+		jsn, err := json.Marshal(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := unmarshalMint(jsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.PrunePrivKeys()
+		return m
+	}
+	pub := copyMint()
+	r := KeyListStatus(priv, pub, net, t1)
+	if len(r.Issues) != 0 {
+		t.Errorf("unexpected issues: %v", r.Issues)
+	}
+
+	// mismatching public key
+	pub.MintEpochs[0].KeyList[0].PubKey = ik.PubKey
+	r = KeyListStatus(priv, pub, net, t1)
+	if !hasIssue(r, SeverityError, ErrKeyListMismatch) {
+		t.Error("mismatching public key should be reported")
+	}
+	if !hasIssue(r, SeverityError, ErrKeySignature) {
+		t.Error("invalid key signature should be reported")
+	}
+
+	// expired private keys
+	pub = copyMint()
+	r = KeyListStatus(priv, pub, net, priv.MintEpochs[0].ValidateEnd)
+	if !hasIssue(r, SeverityWarning, ErrPrivKeyExpired) || r.HasErrors() {
+		t.Error("expired private keys should be reported as warning")
+	}
+
+	// missing epoch and DBC type
+	net.EpochAdd(def.SigningPeriod, def.ValidationPeriod)
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 200000000})
+	r = KeyListStatus(priv, pub, net, t1.Add(time.Hour))
+	if !hasIssue(r, SeverityWarning, ErrMintEpochsMissing) {
+		t.Error("missing epoch should be reported")
+	}
+	if hasIssue(r, SeverityError, ErrDBCTypeMissing) {
+		t.Error("DBC type of missing epoch should not be reported")
+	}
+	net.NetworkEpochs[0].DBCTypesAdded = append(net.NetworkEpochs[0].DBCTypesAdded,
+		DBCType{Currency: "EUR", Amount: 500000000})
+	r = KeyListStatus(priv, pub, net, t1.Add(time.Hour))
+	if !hasIssue(r, SeverityError, ErrDBCTypeMissing) {
+		t.Error("missing DBC type should be reported")
+	}
+}
@@ -0,0 +1,385 @@
+package netconf
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// Ledger kinds.
+const (
+	LedgerCreate    = "create"    // DBCs issued against deposits
+	LedgerDestroyed = "destroyed" // DBCs redeemed for withdrawals
+)
+
+// LedgerEntry records the creation or destruction of DBCs of one DBC type.
+type LedgerEntry struct {
+	ID      string    // unique reference (deposit or withdrawal)
+	DBCType DBCType   // type of the DBCs
+	Count   uint64    // number of DBCs
+	Time    time.Time // time of creation or destruction
+	Inputs  []string  `json:",omitempty"` // hex(HHI) of destroyed DBCs (LedgerDestroyed)
+}
+
+// Ledger lists the DBCs created (LedgerCreate) or destroyed
+// (LedgerDestroyed) by a mint in a signing epoch. The ledgers of all mints
+// for an epoch are published in the file LedgerFilename(epoch, kind).
+type Ledger struct {
+	MintIdentityKey IdentityKey   // identity key of the recording mint
+	Kind            string        // LedgerCreate or LedgerDestroyed
+	Epoch           int           // signing epoch
+	Entries         []LedgerEntry // recorded DBCs
+	Signature       string        // of all fields above by MintIdentityKey
+}
+
+// LedgerFilename returns the filename (relative to the federation directory)
+// of the ledgers of the given kind for the given epoch.
+func LedgerFilename(epoch int, kind string) string {
+	name := DefDBCCreate
+	if kind == LedgerDestroyed {
+		name = DefDBCDestroyed
+	}
+	return path.Join(DefDBCDir, strconv.Itoa(epoch), name)
+}
+
+// NewLedger returns a new empty ledger of the given kind for the mint with
+// identity key ik in the given epoch. It has to be signed before it is
+// published.
+func NewLedger(ik *IdentityKey, kind string, epoch int) *Ledger {
+	return &Ledger{
+		MintIdentityKey: IdentityKey{SigAlgo: ik.SigAlgo, PubKey: ik.PubKey},
+		Kind:            kind,
+		Epoch:           epoch,
+	}
+}
+
+// Entry returns the entry with the given ID or nil.
+func (l *Ledger) Entry(id string) *LedgerEntry {
+	for i := range l.Entries {
+		if l.Entries[i].ID == id {
+			return &l.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Add adds entry e to the ledger, which has to be signed again afterwards.
+func (l *Ledger) Add(e LedgerEntry) error {
+	if l.Entry(e.ID) != nil {
+		return fmt.Errorf("%w: duplicate ID '%s'", ErrLedgerEntry, e.ID)
+	}
+	l.Entries = append(l.Entries, e)
+	return nil
+}
+
+// encode ledger (without signature).
+func (l *Ledger) encode() ([]byte, error) {
+	encodingScheme := []interface{}{
+		[]byte(l.MintIdentityKey.SigAlgo),
+		l.MintIdentityKey.PubKey,
+		[]byte(l.Kind),
+		int64(l.Epoch),
+		int64(len(l.Entries)),
+	}
+	for _, e := range l.Entries {
+		encodingScheme = append(encodingScheme,
+			[]byte(e.ID),
+			[]byte(e.DBCType.Currency),
+			int64(e.DBCType.Amount),
+			int64(e.Count),
+			e.Time.UTC().Unix(),
+			int64(len(e.Inputs)),
+		)
+		for _, in := range e.Inputs {
+			encodingScheme = append(encodingScheme, []byte(in))
+		}
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	return binencode.Encode(buf, encodingScheme...)
+}
+
+// Sign the ledger with the private identity key ik.
+func (l *Ledger) Sign(ik *IdentityKey) error {
+	if len(ik.privKey) != ed25519.PrivateKeySize {
+		return ErrPrivKeyMissing
+	}
+	s := NewKeySigner()
+	s.AddIdentityKey(ik)
+	return l.SignWith(s)
+}
+
+// SignWith signs the ledger with signer s, which must know the private key
+// of the mint identity key. The signature is verified, because s might be a
+// remote signer.
+func (l *Ledger) SignWith(s Signer) error {
+	enc, err := l.encode()
+	if err != nil {
+		return err
+	}
+	sig, err := s.Sign(l.MintIdentityKey.PubKey, enc)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(l.MintIdentityKey.PubKey, enc, sig) {
+		return ErrLedgerSignature
+	}
+	l.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	return nil
+}
+
+// Verify the signature of the ledger.
+func (l *Ledger) Verify() error {
+	// ed25519.Verify panics on malformed keys
+	if len(l.MintIdentityKey.PubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: invalid mint identity key", ErrLedgerSignature)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(l.Signature)
+	if err != nil {
+		return err
+	}
+	enc, err := l.encode()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(l.MintIdentityKey.PubKey, enc, sig) {
+		return ErrLedgerSignature
+	}
+	return nil
+}
+
+// Validate the ledger against the network configuration n.
+func (l *Ledger) Validate(n *Network) error {
+	if len(l.MintIdentityKey.PubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: invalid mint identity key", ErrLedgerMint)
+	}
+	if l.Kind != LedgerCreate && l.Kind != LedgerDestroyed {
+		return fmt.Errorf("netconf: unknown ledger kind '%s'", l.Kind)
+	}
+	if l.Epoch < 0 || l.Epoch >= len(n.NetworkEpochs) {
+		return fmt.Errorf("%w: %d", ErrLedgerEpoch, l.Epoch)
+	}
+	e := n.NetworkEpochs[l.Epoch]
+	mints, err := n.MintsAt(e.SignStart)
+	if err != nil {
+		return err
+	}
+	if !mints[l.MintIdentityKey.MarshalID()] {
+		return fmt.Errorf("%w: %s", ErrLedgerMint, l.MintIdentityKey.MarshalID())
+	}
+	if err := l.Verify(); err != nil {
+		return err
+	}
+	// created DBCs must be of a DBC type of the epoch, destroyed DBCs can be
+	// of every DBC type defined so far
+	dbcTypes := n.DBCTypesAt(l.Epoch)
+	if l.Kind == LedgerDestroyed {
+		for i := 0; i < l.Epoch; i++ {
+			for dbcType := range n.DBCTypesAt(i) {
+				dbcTypes[dbcType] = true
+			}
+		}
+	}
+	ids := make(map[string]bool)
+	inputs := make(map[string]bool)
+	for _, entry := range l.Entries {
+		if entry.ID == "" {
+			return fmt.Errorf("%w: empty ID", ErrLedgerEntry)
+		}
+		if ids[entry.ID] {
+			return fmt.Errorf("%w: duplicate ID '%s'", ErrLedgerEntry, entry.ID)
+		}
+		ids[entry.ID] = true
+		if !dbcTypes[entry.DBCType] {
+			return fmt.Errorf("%w: %s: %v", ErrDBCTypeNotDefined, entry.ID, entry.DBCType)
+		}
+		if entry.Count == 0 {
+			return fmt.Errorf("%w: %s: count is 0", ErrLedgerEntry, entry.ID)
+		}
+		if entry.Time.Before(e.SignStart) || !entry.Time.Before(e.SignEnd) {
+			return fmt.Errorf("%w: %s: time %s not in signing epoch", ErrLedgerEntry,
+				entry.ID, entry.Time.Format(time.RFC3339))
+		}
+		// destroyed DBCs are listed individually, to detect double spends
+		// across mints
+		if l.Kind == LedgerCreate && len(entry.Inputs) > 0 {
+			return fmt.Errorf("%w: %s: created DBCs have no inputs", ErrLedgerEntry, entry.ID)
+		}
+		if l.Kind == LedgerDestroyed && uint64(len(entry.Inputs)) != entry.Count {
+			return fmt.Errorf("%w: %s: %d inputs for count %d", ErrLedgerEntry, entry.ID,
+				len(entry.Inputs), entry.Count)
+		}
+		for _, in := range entry.Inputs {
+			if inputs[in] {
+				return fmt.Errorf("%w: %s: duplicate input %s", ErrLedgerEntry, entry.ID, in)
+			}
+			inputs[in] = true
+		}
+	}
+	return nil
+}
+
+// LoadLedgers loads a list of ledgers from filename.
+func LoadLedgers(filename string) ([]*Ledger, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalLedgers(data)
+}
+
+// loadLedgersFS loads a list of ledgers from the file name in fsys.
+func loadLedgersFS(fsys fs.FS, name string) ([]*Ledger, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalLedgers(data)
+}
+
+func unmarshalLedgers(data []byte) ([]*Ledger, error) {
+	var ledgers []*Ledger
+	if err := json.Unmarshal(data, &ledgers); err != nil {
+		return nil, err
+	}
+	return ledgers, nil
+}
+
+// SaveLedgers saves the list of ledgers to filename. If filename exists
+// already it will be overwritten!
+func SaveLedgers(filename string, ledgers []*Ledger) error {
+	jsn, err := MarshalCanonical(ledgers)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
+
+// LedgersAdd replaces the ledger of the same mint in ledgers by l or appends
+// l, if ledgers doesn't contain a ledger of the mint yet.
+func LedgersAdd(ledgers []*Ledger, l *Ledger) []*Ledger {
+	id := l.MintIdentityKey.MarshalID()
+	for i, o := range ledgers {
+		if o.MintIdentityKey.MarshalID() == id {
+			ledgers[i] = l
+			return ledgers
+		}
+	}
+	return append(ledgers, l)
+}
+
+// RecordLedger records entries in the ledger of the given kind and epoch of
+// the mint with identity key ik in the federation directory dir. The ledger
+// file is locked and record is called with the ledger of the mint (a new one,
+// if the mint has none yet) and with all ledgers of the file (those of the
+// other mints, to check for conflicts). Afterwards the ledger is signed with s
+// and saved, unless record returned an error or the file has been modified
+// concurrently.
+func RecordLedger(
+	dir string,
+	ik *IdentityKey,
+	s Signer,
+	kind string,
+	epoch int,
+	record func(l *Ledger, ledgers []*Ledger) error,
+) error {
+	filename := filepath.Join(dir, LedgerFilename(epoch, kind))
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	// lock
+	lock, err := LockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+	// load
+	var ledgers []*Ledger
+	if _, err := os.Stat(filename); err == nil {
+		ledgers, err = LoadLedgers(filename)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	l := NewLedger(ik, kind, epoch)
+	for _, o := range ledgers {
+		if o.MintIdentityKey.MarshalID() == ik.MarshalID() {
+			l = o
+			break
+		}
+	}
+	if l.Kind != kind || l.Epoch != epoch {
+		return fmt.Errorf("%w: %s ledger of epoch %d in '%s'", ErrLedgerEpoch,
+			l.Kind, l.Epoch, filename)
+	}
+	// edit
+	if err := record(l, ledgers); err != nil {
+		return err
+	}
+	if err := l.SignWith(s); err != nil {
+		return err
+	}
+	// save, unless modified concurrently
+	if err := lock.Check(); err != nil {
+		return err
+	}
+	return SaveLedgers(filename, LedgersAdd(ledgers, l))
+}
+
+// loadAllLedgersFS loads the ledgers of the given kind for all epochs of
+// network n from fsys (they are optional). Only valid ledgers are returned,
+// all others are reported as errors in r.
+func loadAllLedgersFS(
+	fsys fs.FS,
+	n *Network,
+	kind string,
+	r *ValidationReport,
+) map[int][]*Ledger {
+	all := make(map[int][]*Ledger)
+	for i := range n.NetworkEpochs {
+		filename := LedgerFilename(i, kind)
+		ledgers, err := loadLedgersFS(fsys, filename)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				r.addError(i, "", fmt.Errorf("netconf: cannot load ledgers '%s': %w", filename, err))
+			}
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, l := range ledgers {
+			id := l.MintIdentityKey.MarshalID()
+			if l.Kind != kind || l.Epoch != i {
+				r.addError(i, id, fmt.Errorf("%w: %s ledger of epoch %d in '%s'",
+					ErrLedgerEpoch, l.Kind, l.Epoch, filename))
+				continue
+			}
+			if seen[id] {
+				r.addError(i, id, fmt.Errorf("%w: in '%s'", ErrLedgerDuplicate, filename))
+				continue
+			}
+			seen[id] = true
+			if err := l.Validate(n); err != nil {
+				r.addError(i, id, err)
+				continue
+			}
+			all[i] = append(all[i], l)
+		}
+	}
+	return all
+}
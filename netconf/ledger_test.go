@@ -0,0 +1,238 @@
+package netconf
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/scritcash/scrit/util/def"
+)
+
+var testEUR = DBCType{Currency: "EUR", Amount: 100000000}
+
+// ledgerTestNetwork returns a 2-of-3 network with DBC type testEUR and the
+// identity keys of its mints.
+func ledgerTestNetwork(t *testing.T) (*Network, []*IdentityKey) {
+	var iks []*IdentityKey
+	var keys []IdentityKey
+	for i := 0; i < 3; i++ {
+		ik, err := NewIdentityKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		iks = append(iks, ik)
+		keys = append(keys, *ik)
+	}
+	start := DefStartTime()
+	net := NewNetwork(2, 3, start, start.Add(def.SigningPeriod),
+		start.Add(def.SigningPeriod).Add(def.ValidationPeriod), keys)
+	net.DBCTypeAdd(testEUR)
+	return net, iks
+}
+
+// signedLedger returns a ledger of ik with the given entries.
+func signedLedger(
+	t *testing.T,
+	ik *IdentityKey,
+	kind string,
+	entries ...LedgerEntry,
+) *Ledger {
+	l := NewLedger(ik, kind, 0)
+	for _, e := range entries {
+		if err := l.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Sign(ik); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLedgerValidate(t *testing.T) {
+	net, iks := ledgerTestNetwork(t)
+	at := net.NetworkEpochs[0].SignStart.Add(time.Hour)
+	entry := LedgerEntry{ID: "dep1", DBCType: testEUR, Count: 10, Time: at}
+	l := signedLedger(t, iks[0], LedgerCreate, entry)
+	if err := l.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Add(entry); !errors.Is(err, ErrLedgerEntry) {
+		t.Errorf("Add() should fail with ErrLedgerEntry, got: %v", err)
+	}
+
+	// modified ledger
+	l.Entries[0].Count = 11
+	if err := l.Validate(net); !errors.Is(err, ErrLedgerSignature) {
+		t.Errorf("Validate() should fail with ErrLedgerSignature, got: %v", err)
+	}
+
+	// mint not in network
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l = signedLedger(t, ik, LedgerCreate, entry)
+	if err := l.Validate(net); !errors.Is(err, ErrLedgerMint) {
+		t.Errorf("Validate() should fail with ErrLedgerMint, got: %v", err)
+	}
+
+	// undefined DBC type
+	e := entry
+	e.DBCType = DBCType{Currency: "EUR", Amount: 200000000}
+	l = signedLedger(t, iks[0], LedgerCreate, e)
+	if err := l.Validate(net); !errors.Is(err, ErrDBCTypeNotDefined) {
+		t.Errorf("Validate() should fail with ErrDBCTypeNotDefined, got: %v", err)
+	}
+
+	// entry outside of signing epoch
+	e = entry
+	e.Time = net.NetworkEpochs[0].SignEnd
+	l = signedLedger(t, iks[0], LedgerCreate, e)
+	if err := l.Validate(net); !errors.Is(err, ErrLedgerEntry) {
+		t.Errorf("Validate() should fail with ErrLedgerEntry, got: %v", err)
+	}
+
+	// destroyed DBCs list their inputs
+	e = LedgerEntry{ID: "red1", DBCType: testEUR, Count: 2, Time: at,
+		Inputs: []string{"aa", "bb"}}
+	l = signedLedger(t, iks[0], LedgerDestroyed, e)
+	if err := l.Validate(net); err != nil {
+		t.Error(err)
+	}
+	l = signedLedger(t, iks[0], LedgerCreate, e)
+	if err := l.Validate(net); !errors.Is(err, ErrLedgerEntry) {
+		t.Errorf("Validate() should fail with ErrLedgerEntry, got: %v", err)
+	}
+	e.Count = 3
+	l = signedLedger(t, iks[0], LedgerDestroyed, e)
+	if err := l.Validate(net); !errors.Is(err, ErrLedgerEntry) {
+		t.Errorf("Validate() should fail with ErrLedgerEntry, got: %v", err)
+	}
+	e2 := LedgerEntry{ID: "red2", DBCType: testEUR, Count: 1, Time: at,
+		Inputs: []string{"bb"}}
+	e.Count = 2
+	l = signedLedger(t, iks[0], LedgerDestroyed, e, e2)
+	if err := l.Validate(net); !errors.Is(err, ErrLedgerEntry) {
+		t.Errorf("Validate() should fail with ErrLedgerEntry, got: %v", err)
+	}
+}
+
+func TestLoadAllLedgers(t *testing.T) {
+	net, iks := ledgerTestNetwork(t)
+	at := net.NetworkEpochs[0].SignStart.Add(time.Hour)
+	entry := LedgerEntry{ID: "dep1", DBCType: testEUR, Count: 10, Time: at}
+	l1 := signedLedger(t, iks[0], LedgerCreate, entry)
+	l2 := signedLedger(t, iks[1], LedgerCreate, entry)
+	ledgers := LedgersAdd(nil, l1)
+	ledgers = LedgersAdd(ledgers, l2)
+	ledgers = append(ledgers, l1) // duplicate
+	data, err := MarshalCanonical(ledgers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := LedgerFilename(0, LedgerCreate)
+	if filename != "dbcs/0/create.json" {
+		t.Errorf("LedgerFilename() = %s", filename)
+	}
+	fsys := fstest.MapFS{filename: &fstest.MapFile{Data: data}}
+	var r ValidationReport
+	created := loadAllLedgersFS(fsys, net, LedgerCreate, &r)
+	if len(created[0]) != 2 {
+		t.Errorf("len(created[0]) = %d != 2", len(created[0]))
+	}
+	if !errors.Is(r.Err(), ErrLedgerDuplicate) {
+		t.Errorf("duplicate ledger not reported: %v", r.Err())
+	}
+	// wrong kind
+	r = ValidationReport{}
+	fsys = fstest.MapFS{LedgerFilename(0, LedgerDestroyed): &fstest.MapFile{Data: data}}
+	destroyed := loadAllLedgersFS(fsys, net, LedgerDestroyed, &r)
+	if len(destroyed[0]) != 0 {
+		t.Errorf("len(destroyed[0]) = %d != 0", len(destroyed[0]))
+	}
+	if !errors.Is(r.Err(), ErrLedgerEpoch) {
+		t.Errorf("wrong ledger kind not reported: %v", r.Err())
+	}
+	// malformed mint identity key is reported, not a panic
+	bad := signedLedger(t, iks[2], LedgerCreate, entry)
+	bad.MintIdentityKey.PubKey = bad.MintIdentityKey.PubKey[:16]
+	if err := bad.Verify(); !errors.Is(err, ErrLedgerSignature) {
+		t.Errorf("Verify() should fail with ErrLedgerSignature, got: %v", err)
+	}
+	data, err = MarshalCanonical(LedgersAdd(ledgers[:1], bad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r = ValidationReport{}
+	fsys = fstest.MapFS{filename: &fstest.MapFile{Data: data}}
+	created = loadAllLedgersFS(fsys, net, LedgerCreate, &r)
+	if len(created[0]) != 1 {
+		t.Errorf("len(created[0]) = %d != 1", len(created[0]))
+	}
+	if !errors.Is(r.Err(), ErrLedgerMint) {
+		t.Errorf("malformed mint identity key not reported: %v", r.Err())
+	}
+}
+
+func TestSupply(t *testing.T) {
+	net, iks := ledgerTestNetwork(t)
+	at := net.NetworkEpochs[0].SignStart.Add(time.Hour)
+	dep1 := LedgerEntry{ID: "dep1", DBCType: testEUR, Count: 10, Time: at}
+	dep2 := LedgerEntry{ID: "dep2", DBCType: testEUR, Count: 5, Time: at}
+	wd1 := LedgerEntry{ID: "wd1", DBCType: testEUR, Count: 3, Time: at}
+	f := &Federation{
+		Network: net,
+		Created: map[int][]*Ledger{0: {
+			signedLedger(t, iks[0], LedgerCreate, dep1, dep2),
+			signedLedger(t, iks[1], LedgerCreate, dep1),
+			signedLedger(t, iks[2], LedgerCreate, dep1),
+		}},
+		Destroyed: map[int][]*Ledger{0: {
+			signedLedger(t, iks[0], LedgerDestroyed, wd1),
+			signedLedger(t, iks[1], LedgerDestroyed, wd1),
+		}},
+	}
+	supply, r := f.Supply()
+	if r.HasErrors() {
+		t.Fatal(r.Err())
+	}
+	if len(supply) != 1 {
+		t.Fatalf("len(supply) = %d != 1", len(supply))
+	}
+	s := supply[0]
+	if s.Created != 10 || s.Destroyed != 3 || s.Outstanding != 7 {
+		t.Errorf("wrong supply: %+v", s)
+	}
+	if v, err := s.Value(); err != nil || v != 700000000 {
+		t.Errorf("Value() = %d, %v", v, err)
+	}
+	// dep2 is only recorded by one mint
+	if len(r.Issues) != 1 || r.Issues[0].Code != "ErrLedgerQuorum" {
+		t.Errorf("unexpected issues:\n%s", r.Marshal())
+	}
+
+	// conflicting entries and negative supply
+	wd2 := LedgerEntry{ID: "wd2", DBCType: testEUR, Count: 20, Time: at}
+	wd2b := wd2
+	wd2b.Count = 21
+	f.Destroyed[0] = []*Ledger{
+		signedLedger(t, iks[0], LedgerDestroyed, wd2),
+		signedLedger(t, iks[1], LedgerDestroyed, wd2),
+		signedLedger(t, iks[2], LedgerDestroyed, wd2b),
+	}
+	_, r = f.Supply()
+	if !errors.Is(r.Err(), ErrSupplyNegative) {
+		t.Errorf("negative supply not reported: %v", r.Err())
+	}
+	var conflicts int
+	for _, i := range r.Issues {
+		if i.Code == "ErrLedgerConflict" {
+			conflicts++
+		}
+	}
+	if conflicts != 3 {
+		t.Errorf("%d conflicts reported instead of 3", conflicts)
+	}
+}
@@ -0,0 +1,77 @@
+package netconf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/scritcash/scrit/util/flock"
+)
+
+// FileLock protects the load/edit/save cycle of a configuration file against
+// concurrent invocations. It holds an advisory lock on the directory of the
+// file and remembers the hash of the file's content at lock time, which is
+// compared again before saving (optimistic concurrency check). The latter
+// also detects modifications by processes which do not lock.
+type FileLock struct {
+	filename string
+	lock     *flock.Lock
+	hash     []byte // nil, if file doesn't exist
+}
+
+// hashFile returns the SHA-256 hash of the content of filename or nil, if
+// the file doesn't exist.
+func hashFile(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	h := sha256.Sum256(data)
+	return h[:], nil
+}
+
+// LockFile locks the configuration file filename for editing. It blocks
+// until the lock is acquired. The file doesn't have to exist. The lock covers
+// the whole directory, it is re-entrant within a process (see package flock).
+// On Windows only the optimistic concurrency check protects the file.
+func LockFile(filename string) (*FileLock, error) {
+	l, err := flock.LockDir(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	// recover interrupted save before the content is hashed
+	if _, err := readFile(filename); err != nil && !os.IsNotExist(err) {
+		l.Unlock()
+		return nil, err
+	}
+	hash, err := hashFile(filename)
+	if err != nil {
+		l.Unlock()
+		return nil, err
+	}
+	return &FileLock{filename: filename, lock: l, hash: hash}, nil
+}
+
+// Check returns ErrConcurrentModification, if the locked file has been
+// modified since it was locked. Call it right before saving the file.
+func (l *FileLock) Check() error {
+	hash, err := hashFile(l.filename)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(hash, l.hash) {
+		return fmt.Errorf("%w: '%s'", ErrConcurrentModification, l.filename)
+	}
+	return nil
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	return l.lock.Unlock()
+}
@@ -0,0 +1,46 @@
+package netconf
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), DefNetConfFile)
+
+	// file doesn't exist yet
+	l, err := LockFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Check(); err != nil {
+		t.Error(err)
+	}
+	if err := ioutil.WriteFile(filename, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Check(); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("Check() should fail with ErrConcurrentModification, got: %v", err)
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// file exists
+	l, err = LockFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Unlock()
+	if err := l.Check(); err != nil {
+		t.Error(err)
+	}
+	if err := ioutil.WriteFile(filename, []byte("{ }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Check(); !errors.Is(err, ErrConcurrentModification) {
+		t.Errorf("Check() should fail with ErrConcurrentModification, got: %v", err)
+	}
+}
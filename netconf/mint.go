@@ -5,17 +5,20 @@ import (
 	"crypto/rand"
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"fmt"
+	"io/fs"
 	"os"
 	"time"
 
-	"github.com/frankbraun/codechain/util/file"
+	"github.com/frankbraun/codechain/util/bzero"
 	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/util/atomicfile"
 )
 
 // Mint defines the key list of a single mint for all epochs and where to
 // reach the mint.
 type Mint struct {
+	Version         int          `json:",omitempty"` // file format version (see MintVersion)
 	Description     string       // description of mint (name)
 	MintIdentityKey IdentityKey  // identity key of mint
 	MintEpochs      []*MintEpoch // corresponding to global epochs
@@ -29,9 +32,19 @@ type MintEpoch struct {
 	ValidateEnd       time.Time     // end of validation epoch
 	KeyList           []*SigningKey // the key list
 	KeyListSignatures [][]byte      // signatures of key list (identity signature last)
+	SignedBy          *IdentityKey  `json:",omitempty"` // former identity key of mint, if it signed the key list
 }
 
-func (m *Mint) generateKeys(ik *IdentityKey, n *Network, start int) error {
+// identity returns the identity key which signed the mint epoch, the former
+// identity key SignedBy or the current identity key ik of the mint.
+func (me *MintEpoch) identity(ik *IdentityKey) *IdentityKey {
+	if me.SignedBy != nil {
+		return me.SignedBy
+	}
+	return ik
+}
+
+func (m *Mint) generateKeys(n *Network, start int) error {
 	dbcTypes := make(map[DBCType]bool)
 	for i, e := range n.NetworkEpochs {
 		for _, add := range e.DBCTypesAdded {
@@ -58,7 +71,7 @@ func (m *Mint) generateKeys(ik *IdentityKey, n *Network, start int) error {
 			}
 		}
 	}
-	return m.sign(ik, start)
+	return nil
 }
 
 // NewMint creates a new Scrit mint.
@@ -67,8 +80,26 @@ func NewMint(
 	ik *IdentityKey,
 	urls []string,
 	n *Network,
+) (*Mint, error) {
+	m, err := newMint(description, ik, urls, n)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.sign(ik, 0); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// newMint creates a new Scrit mint with unsigned key lists.
+func newMint(
+	description string,
+	ik *IdentityKey,
+	urls []string,
+	n *Network,
 ) (*Mint, error) {
 	var m Mint
+	m.Version = MintVersion
 	m.Description = description
 	m.MintIdentityKey = *ik
 	for _, ne := range n.NetworkEpochs {
@@ -80,7 +111,7 @@ func NewMint(
 		m.MintEpochs = append(m.MintEpochs, me)
 	}
 	m.URLs = urls
-	if err := m.generateKeys(ik, n, 0); err != nil {
+	if err := m.generateKeys(n, 0); err != nil {
 		return nil, err
 	}
 	return &m, nil
@@ -88,23 +119,36 @@ func NewMint(
 
 // Extend the mint's key list for the given network.
 func (m *Mint) Extend(ik *IdentityKey, n *Network) error {
-	start := 0
+	start, err := m.extend(n)
+	if err != nil {
+		return err
+	}
+	if start >= len(m.MintEpochs) {
+		return nil // nothing to extend
+	}
+	return m.sign(ik, start)
+}
+
+// extend the mint's key list for the given network without signing it.
+// Returns the index of the first new mint epoch.
+func (m *Mint) extend(n *Network) (int, error) {
+	start := len(m.MintEpochs)
+	if start >= len(n.NetworkEpochs) {
+		return start, nil // nothing to extend
+	}
 	for i, ne := range n.NetworkEpochs {
 		if i < len(m.MintEpochs) {
 			me := m.MintEpochs[i]
 			if me.SignStart != ne.SignStart {
-				return errors.New("netconf: epoch signature starts do not match")
+				return 0, errors.New("netconf: epoch signature starts do not match")
 			}
 			if me.SignEnd != ne.SignEnd {
-				return errors.New("netconf: epoch signature ends do not match")
+				return 0, errors.New("netconf: epoch signature ends do not match")
 			}
 			if me.ValidateEnd != ne.ValidateEnd {
-				return errors.New("netconf: epoch validation ends do not match")
+				return 0, errors.New("netconf: epoch validation ends do not match")
 			}
 		} else {
-			if start == 0 {
-				start = i
-			}
 			me := &MintEpoch{
 				SignStart:   ne.SignStart,
 				SignEnd:     ne.SignEnd,
@@ -113,7 +157,33 @@ func (m *Mint) Extend(ik *IdentityKey, n *Network) error {
 			m.MintEpochs = append(m.MintEpochs, me)
 		}
 	}
-	return m.generateKeys(ik, n, start)
+	return start, m.generateKeys(n, start)
+}
+
+// MigrateIdentity migrates the mint's key list to the new identity key ik at
+// time t. All mint epochs which start after t are signed again with ik and
+// the unchanged signing keys, which therefore must contain their private
+// keys. Mint epochs which have already started at t have been published
+// under the former identity key, they keep their signatures and record the
+// former identity key in SignedBy (their private keys might have been pruned
+// already).
+func (m *Mint) MigrateIdentity(ik *IdentityKey, t time.Time) error {
+	old := &IdentityKey{
+		SigAlgo: m.MintIdentityKey.SigAlgo,
+		PubKey:  m.MintIdentityKey.PubKey,
+	}
+	start := len(m.MintEpochs)
+	for i, e := range m.MintEpochs {
+		if t.Before(e.SignStart) {
+			start = i
+			break
+		}
+		if e.SignedBy == nil {
+			e.SignedBy = old
+		}
+	}
+	m.MintIdentityKey = *ik
+	return m.sign(ik, start)
 }
 
 // PrunePrivKeys prunes all private keys from the given mint configuration.
@@ -125,35 +195,58 @@ func (m *Mint) PrunePrivKeys() {
 	}
 }
 
+// PruneExpired erases the private keys of all mint epochs whose validation
+// period has ended at time t. Public keys and signatures are kept. It returns
+// the number of erased private keys.
+func (m *Mint) PruneExpired(t time.Time) int {
+	var n int
+	for _, e := range m.MintEpochs {
+		if t.Before(e.ValidateEnd) {
+			continue
+		}
+		for _, k := range e.KeyList {
+			if len(k.PrivKey) == 0 {
+				continue
+			}
+			// overwrite key material before dropping it
+			bzero.Bytes(k.PrivKey)
+			k.PrivKey = nil
+			n++
+		}
+	}
+	return n
+}
+
 // Save mint with perm to given filename
 func (m *Mint) Save(filename string, perm os.FileMode) error {
-	jsn, err := json.MarshalIndent(m, "", "  ")
+	jsn, err := MarshalCanonical(m)
 	if err != nil {
-		panic(err) // should never happen
-	}
-	exists, err := file.Exists(filename)
-	if err != nil {
-		return err
-	}
-	if exists {
-		if err := os.Rename(filename, filename+".bac"); err != nil {
-			return err
-		}
-	}
-	if err := ioutil.WriteFile(filename, jsn, perm); err != nil {
 		return err
 	}
-	if exists {
-		return os.Remove(filename + ".bac")
-	}
-	return nil
-
+	return atomicfile.WriteFile(filename, jsn, perm)
 }
 
 // LoadMint loads a mint configuration from filename and return the
 // Mint struct.
 func LoadMint(filename string) (*Mint, error) {
-	data, err := ioutil.ReadFile(filename)
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalMint(data)
+}
+
+// loadMintFS loads a mint configuration from the file name in fsys.
+func loadMintFS(fsys fs.FS, name string) (*Mint, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalMint(data)
+}
+
+func unmarshalMint(data []byte) (*Mint, error) {
+	data, err := migrateMint(data)
 	if err != nil {
 		return nil, err
 	}
@@ -161,22 +254,31 @@ func LoadMint(filename string) (*Mint, error) {
 	if err := json.Unmarshal(data, &mint); err != nil {
 		return nil, err
 	}
-	return &mint, err
+	return &mint, nil
 }
 
-// sign all mint epochs in mint
+// sign all mint epochs in mint from start on with the private identity key
+// ik and the private keys in the key lists.
 func (m *Mint) sign(ik *IdentityKey, start int) error {
+	return m.SignWith(NewMintSigner(ik, m), start)
+}
+
+// SignWith signs all mint epochs in mint from start on with the signer s,
+// which must know the private identity key and all private signing keys of
+// these epochs.
+func (m *Mint) SignWith(s Signer, start int) error {
 	for i := start; i < len(m.MintEpochs); i++ {
 		e := m.MintEpochs[i]
-		if err := e.sign(ik); err != nil {
+		if err := e.signWith(&m.MintIdentityKey, s); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// encode mint epoch.
+// encode mint epoch for the identity key ik of the mint.
 func (me *MintEpoch) encode(ik *IdentityKey) ([]byte, error) {
+	ik = me.identity(ik)
 	encodingScheme := []interface{}{
 		[]byte(ik.SigAlgo),
 		ik.PubKey,
@@ -184,6 +286,9 @@ func (me *MintEpoch) encode(ik *IdentityKey) ([]byte, error) {
 		me.SignEnd.UTC().Unix(),
 		me.ValidateEnd.UTC().Unix(),
 	}
+	// signing windows are only encoded if at least one key defines its own,
+	// which keeps the encoding of key lists without windows unchanged
+	windows := me.hasWindows()
 	for _, k := range me.KeyList {
 		encodingScheme = append(encodingScheme,
 			[]byte(k.Currency),
@@ -191,6 +296,13 @@ func (me *MintEpoch) encode(ik *IdentityKey) ([]byte, error) {
 			[]byte(k.SigAlgo),
 			k.PubKey,
 		)
+		if windows {
+			start, end := k.Window(me)
+			encodingScheme = append(encodingScheme,
+				start.UTC().Unix(),
+				end.UTC().Unix(),
+			)
+		}
 	}
 	size, err := binencode.EncodeSize(encodingScheme...)
 	if err != nil {
@@ -200,17 +312,61 @@ func (me *MintEpoch) encode(ik *IdentityKey) ([]byte, error) {
 	return binencode.Encode(buf, encodingScheme...)
 }
 
-// sign mint epoch.
+// sign mint epoch with the private identity key ik and the private keys in
+// the key list.
 func (me *MintEpoch) sign(ik *IdentityKey) error {
-	enc, err := me.encode(ik)
+	s := NewKeySigner()
+	s.AddIdentityKey(ik)
+	for _, k := range me.KeyList {
+		s.Add(k.PrivKey)
+	}
+	return me.signWith(ik, s)
+}
+
+// signWith signs the mint epoch with signer s for identity key ik.
+// Every signature is verified, because s might be a remote signer.
+func (me *MintEpoch) signWith(ik *IdentityKey, s Signer) error {
+	me.SignedBy = nil // signed with the current identity key from now on
+	enc, err := me.signKeys(ik, s)
+	if err != nil {
+		return err
+	}
+	sig, err := s.Sign(ik.PubKey, enc)
 	if err != nil {
 		return err
 	}
+	return me.addIdentitySignature(ik, enc, sig)
+}
+
+// signKeys signs the mint epoch for identity key ik only with the signing
+// keys in the key list (with signer s) and returns the signed encoding.
+// The identity signature has to be added with addIdentitySignature.
+func (me *MintEpoch) signKeys(ik *IdentityKey, s Signer) ([]byte, error) {
+	enc, err := me.encode(ik)
+	if err != nil {
+		return nil, err
+	}
+	var sigs [][]byte
 	for _, k := range me.KeyList {
-		sig := ed25519.Sign(k.PrivKey, enc)
-		me.KeyListSignatures = append(me.KeyListSignatures, sig)
+		sig, err := s.Sign(k.PubKey, enc)
+		if err != nil {
+			return nil, err
+		}
+		if !ed25519.Verify(k.PubKey, enc, sig) {
+			return nil, ErrKeySignature
+		}
+		sigs = append(sigs, sig)
+	}
+	me.KeyListSignatures = sigs
+	return enc, nil
+}
+
+// addIdentitySignature verifies the identity signature sig on the encoding
+// enc of the mint epoch and adds it to the key list signatures.
+func (me *MintEpoch) addIdentitySignature(ik *IdentityKey, enc, sig []byte) error {
+	if !ed25519.Verify(ik.PubKey, enc, sig) {
+		return ErrIdentitySignature
 	}
-	sig := ed25519.Sign(ik.privKey, enc)
 	me.KeyListSignatures = append(me.KeyListSignatures, sig)
 	return nil
 }
@@ -221,37 +377,58 @@ func (me *MintEpoch) Verify(ik *IdentityKey) error {
 	if err != nil {
 		return err
 	}
+	if len(me.KeyListSignatures) != len(me.KeyList)+1 {
+		return ErrKeyListSignatures
+	}
 	// check "normal" key signatures
 	for i, k := range me.KeyList {
 		if !ed25519.Verify(k.PubKey, enc, me.KeyListSignatures[i]) {
-			return errors.New("netconf: key signature doesn't verify")
+			return ErrKeySignature
 		}
 	}
 	// check identity key signature
-	if !ed25519.Verify(ik.PubKey, enc, me.KeyListSignatures[len(me.KeyList)]) {
-		return errors.New("netconf: identity key signature doesn't verify")
+	if !ed25519.Verify(me.identity(ik).PubKey, enc, me.KeyListSignatures[len(me.KeyList)]) {
+		return ErrIdentitySignature
 	}
 	return nil
 }
 
 // Validate the mint configuration.
 func (m *Mint) Validate(net *Network) error {
+	return m.Report(net).Err()
+}
+
+// Report validates the mint configuration and returns a report listing all
+// found issues.
+func (m *Mint) Report(net *Network) *ValidationReport {
+	var r ValidationReport
+	m.validate(&r, net)
+	return &r
+}
+
+// validate the mint configuration and add all found issues to the report r.
+func (m *Mint) validate(r *ValidationReport, net *Network) {
+	id := m.MintIdentityKey.MarshalID()
 	// validate mint epoch transitions
 	for i := 1; i < len(m.MintEpochs); i++ {
 		// sign end i-1 == sign start i
 		if m.MintEpochs[i-1].SignEnd != m.MintEpochs[i].SignStart {
-			return ErrSignEpochWrongBoundaries
+			r.addError(i, id, ErrSignEpochWrongBoundaries)
 		}
 		// validation end i-1 <= sign end i
 		if m.MintEpochs[i-1].ValidateEnd.After(m.MintEpochs[i].SignEnd) {
-			return ErrValidationLongerThanNextSigning
+			r.addError(i, id, ErrValidationLongerThanNextSigning)
 		}
 	}
 
-	// make sure we have the right amount of signing keys
-	// TODO: this test enforces only one siging key for each DBC type (with
+	// make sure we have the right signing keys for each DBC type
+	epochs := len(m.MintEpochs)
+	if epochs > len(net.NetworkEpochs) {
+		r.addError(NoEpoch, id, ErrMintEpochsTooMany)
+		epochs = len(net.NetworkEpochs)
+	}
 	dbcTypes := make(map[DBCType]bool)
-	for i := 0; i < len(m.MintEpochs); i++ {
+	for i := 0; i < epochs; i++ {
 		e := net.NetworkEpochs[i]
 		for _, add := range e.DBCTypesAdded {
 			dbcTypes[add] = true
@@ -260,26 +437,148 @@ func (m *Mint) Validate(net *Network) error {
 			delete(dbcTypes, remove)
 		}
 		dbcs := DBCTypeMapToSortedArray(dbcTypes)
-		if len(dbcs) != len(m.MintEpochs[i].KeyList) {
-			return errors.New("netconf: lenght of key list doesn't equal number of DBC types")
-		}
-		for j, dbc := range dbcs {
-			if dbc.Currency != m.MintEpochs[i].KeyList[j].Currency {
-				return errors.New("netconf: key currency does not match DBC type")
-			}
-			if dbc.Amount != m.MintEpochs[i].KeyList[j].Amount {
-				return errors.New("netconf: key amount does not match DBC type")
-			}
+		if err := m.MintEpochs[i].validateKeyList(dbcs); err != nil {
+			r.addError(i, id, err)
 		}
 	}
 
-	for _, e := range m.MintEpochs {
+	former := net.FormerIdentities(id)
+	for i, e := range m.MintEpochs {
+		if e.SignedBy != nil && !former[e.SignedBy.MarshalID()] {
+			r.addError(i, id, fmt.Errorf("%w: %s", ErrFormerIdentity, e.SignedBy.MarshalID()))
+		}
 		if err := e.Verify(&m.MintIdentityKey); err != nil {
-			return err
+			r.addError(i, id, err)
 		}
 	}
 	if len(m.URLs) == 0 {
-		return ErrNoURL
+		r.addError(NoEpoch, id, ErrNoURL)
+	}
+}
+
+// hasWindows returns true, if at least one key in the key list defines its
+// own signing window.
+func (me *MintEpoch) hasWindows() bool {
+	for _, k := range me.KeyList {
+		if k.HasWindow() {
+			return true
+		}
+	}
+	return false
+}
+
+// validateKeyList makes sure the key list contains keys for exactly the
+// given sorted DBC types. Each DBC type can have multiple keys, sorted by
+// their signing windows, which must cover the signing epoch without gaps or
+// overlaps.
+func (me *MintEpoch) validateKeyList(dbcs []DBCType) error {
+	j := 0
+	for _, dbc := range dbcs {
+		if j >= len(me.KeyList) {
+			return ErrKeyListLength
+		}
+		if dbc.Currency != me.KeyList[j].Currency {
+			return ErrKeyCurrencyMismatch
+		}
+		if dbc.Amount != me.KeyList[j].Amount {
+			return ErrKeyAmountMismatch
+		}
+		// all keys of this DBC type
+		next := me.SignStart
+		for ; j < len(me.KeyList) && me.KeyList[j].DBCType() == dbc; j++ {
+			start, end := me.KeyList[j].Window(me)
+			if !start.Equal(next) || !start.Before(end) || end.After(me.SignEnd) {
+				return ErrKeyWindow
+			}
+			next = end
+		}
+		if !next.Equal(me.SignEnd) {
+			return ErrKeyWindow
+		}
+	}
+	if j != len(me.KeyList) {
+		return ErrKeyListLength
+	}
+	return nil
+}
+
+// SigningKeys returns all keys of the mint epoch for the given DBC type.
+func (me *MintEpoch) SigningKeys(dbc DBCType) []*SigningKey {
+	var keys []*SigningKey
+	for _, k := range me.KeyList {
+		if k.DBCType() == dbc {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// SigningKeyAt returns the key of the mint epoch which signs the given DBC
+// type at time t, or nil if no such key exists.
+func (me *MintEpoch) SigningKeyAt(dbc DBCType, t time.Time) *SigningKey {
+	for _, k := range me.SigningKeys(dbc) {
+		start, end := k.Window(me)
+		if !t.Before(start) && t.Before(end) {
+			return k
+		}
 	}
 	return nil
 }
+
+// Rolled returns true, if the signing key k of the mint epoch has been
+// replaced by another key (see Mint.RollKey) at or before time t. The signing
+// time of a DBC signature is claimed by the signer, the holder of a rolled
+// (compromised) key could backdate its signatures. Therefore signatures of a
+// rolled key only count for reissues from the roll on (see
+// Federation.AcceptSignatures).
+func (me *MintEpoch) Rolled(k *SigningKey, t time.Time) bool {
+	_, end := k.Window(me)
+	return end.Before(me.SignEnd) && !t.Before(end)
+}
+
+// RollKey replaces the signing key for the given DBC type in the given epoch
+// at time t with a newly generated key. The signing window of the current key
+// ends at t and the new key signs from t until the end of the signing epoch.
+// Afterwards the epoch is signed again with ik and all signing keys, which
+// therefore must contain their private keys.
+// From t on signatures of the current key only count for reissues (see
+// MintEpoch.Rolled), DBCs it signed before have to be reissued.
+func (m *Mint) RollKey(ik *IdentityKey, epoch int, dbc DBCType, t time.Time) (*SigningKey, error) {
+	if epoch < 0 || epoch >= len(m.MintEpochs) {
+		return nil, fmt.Errorf("netconf: mint epoch %d undefined", epoch)
+	}
+	me := m.MintEpochs[epoch]
+	old := me.SigningKeyAt(dbc, t)
+	if old == nil {
+		return nil, fmt.Errorf("netconf: no signing key for %v at %s", dbc,
+			t.Format(time.RFC3339))
+	}
+	start, end := old.Window(me)
+	if !start.Before(t) {
+		return nil, ErrKeyWindow
+	}
+	sk, err := NewSigningKey(dbc.Currency, dbc.Amount)
+	if err != nil {
+		return nil, err
+	}
+	roll := t.UTC()
+	oldStart := start.UTC()
+	newEnd := end.UTC()
+	old.SignStart = &oldStart
+	old.SignEnd = &roll
+	sk.SignStart = &roll
+	sk.SignEnd = &newEnd
+	// insert new key directly after the old one
+	var keyList []*SigningKey
+	for _, k := range me.KeyList {
+		keyList = append(keyList, k)
+		if k == old {
+			keyList = append(keyList, sk)
+		}
+	}
+	me.KeyList = keyList
+	if err := me.sign(ik); err != nil {
+		return nil, err
+	}
+	return sk, nil
+}
@@ -0,0 +1,235 @@
+package netconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/util/def"
+)
+
+func TestRollKey(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := t1
+	net := NewNetwork(1, 1, start, start.Add(def.SigningPeriod),
+		start.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	eur1 := DBCType{Currency: "EUR", Amount: 100000000}
+	eur2 := DBCType{Currency: "EUR", Amount: 200000000}
+	net.DBCTypeAdd(eur1)
+	net.DBCTypeAdd(eur2)
+	m, err := NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := m.MintEpochs[0].encode(ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// roll key twice
+	roll1 := start.Add(24 * time.Hour)
+	roll2 := start.Add(48 * time.Hour)
+	old := m.MintEpochs[0].SigningKeyAt(eur1, roll1)
+	sk1, err := m.RollKey(ik, 0, eur1, roll1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk2, err := m.RollKey(ik, 0, eur1, roll2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.MintEpochs[0].SigningKeys(eur1)) != 3 {
+		t.Errorf("DBC type should have 3 keys")
+	}
+	if len(m.MintEpochs[0].SigningKeys(eur2)) != 1 {
+		t.Errorf("DBC type should have 1 key")
+	}
+	testCases := []struct {
+		t   time.Time
+		key *SigningKey
+	}{
+		{start.Add(-time.Second), nil},
+		{start, old},
+		{roll1.Add(-time.Second), old},
+		{roll1, sk1},
+		{roll2, sk2},
+		{start.Add(def.SigningPeriod).Add(-time.Second), sk2},
+		{start.Add(def.SigningPeriod), nil},
+	}
+	for i, testCase := range testCases {
+		if k := m.MintEpochs[0].SigningKeyAt(eur1, testCase.t); k != testCase.key {
+			t.Errorf("test case %d: SigningKeyAt() returned wrong key", i)
+		}
+	}
+
+	// encoding changed, because the key list now has signing windows
+	enc2, err := m.MintEpochs[0].encode(ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enc2) <= len(enc) {
+		t.Error("encoding with signing windows should be longer")
+	}
+
+	// signing windows survive a JSON round trip and are covered by signatures
+	jsn, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := unmarshalMint(jsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m2.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+	end := roll2.Add(time.Hour)
+	m2.MintEpochs[0].KeyList[1].SignEnd = &end
+	if err := m2.MintEpochs[0].Verify(ik); err != ErrKeySignature {
+		t.Errorf("Verify() should fail with %v (has %v)", ErrKeySignature, err)
+	}
+	// gap between signing windows
+	gap := end.Add(time.Hour)
+	m2.MintEpochs[0].KeyList[2].SignStart = &gap
+	if err := m2.MintEpochs[0].validateKeyList(DBCTypeMapToSortedArray(net.DBCTypes())); err != ErrKeyWindow {
+		t.Errorf("validateKeyList() should fail with %v (has %v)", ErrKeyWindow, err)
+	}
+}
+
+func TestPruneExpired(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	net.EpochAdd(def.SigningPeriod, def.ValidationPeriod)
+	m, err := NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.MintEpochs) != 2 {
+		t.Fatalf("mint should have 2 epochs, has %d", len(m.MintEpochs))
+	}
+	privKey := m.MintEpochs[0].KeyList[0].PrivKey
+
+	// nothing expired yet
+	if n := m.PruneExpired(m.MintEpochs[0].ValidateEnd.Add(-time.Second)); n != 0 {
+		t.Errorf("PruneExpired() should prune 0 keys, pruned %d", n)
+	}
+	// first epoch expired
+	if n := m.PruneExpired(m.MintEpochs[0].ValidateEnd); n != 1 {
+		t.Errorf("PruneExpired() should prune 1 key, pruned %d", n)
+	}
+	if m.MintEpochs[0].KeyList[0].PrivKey != nil {
+		t.Error("expired private key should be erased")
+	}
+	for _, b := range privKey {
+		if b != 0 {
+			t.Error("expired private key should be overwritten")
+			break
+		}
+	}
+	if m.MintEpochs[1].KeyList[0].PrivKey == nil {
+		t.Error("private key of second epoch should be kept")
+	}
+	// public keys and signatures are kept
+	if err := m.Validate(net); err != nil {
+		t.Error(err)
+	}
+	// pruning again is a no-op
+	if n := m.PruneExpired(m.MintEpochs[0].ValidateEnd); n != 0 {
+		t.Errorf("PruneExpired() should prune 0 keys, pruned %d", n)
+	}
+}
+
+func TestMigrateIdentityAfterPrune(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	for i := 0; i < 3; i++ {
+		net.EpochAdd(def.SigningPeriod, def.ValidationPeriod)
+	}
+	m, err := NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sigs [][][]byte
+	for _, e := range m.MintEpochs {
+		sigs = append(sigs, e.KeyListSignatures)
+	}
+
+	// prune the first epoch and rotate afterwards
+	now := m.MintEpochs[0].ValidateEnd
+	if n := m.PruneExpired(now); n != 1 {
+		t.Fatalf("PruneExpired() should prune 1 key, pruned %d", n)
+	}
+	newKey, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.MigrateIdentity(newKey, now); err != nil {
+		t.Fatal(err)
+	}
+	// epochs which have started keep the signatures of the former identity
+	for i, e := range m.MintEpochs {
+		started := !now.Before(e.SignStart)
+		if started != (e.SignedBy != nil) {
+			t.Errorf("epoch %d: started %v, signed by former identity key %v",
+				i, started, e.SignedBy != nil)
+		}
+		if e.SignedBy != nil && e.SignedBy.MarshalID() != ik.MarshalID() {
+			t.Errorf("epoch %d: signed by wrong former identity key", i)
+		}
+		kept := bytes.Equal(e.KeyListSignatures[1], sigs[i][1])
+		if started != kept {
+			t.Errorf("epoch %d: started %v, signatures kept %v", i, started, kept)
+		}
+	}
+	if m.MintEpochs[3].SignedBy != nil {
+		t.Error("future epoch should be signed by new identity key")
+	}
+	e := m.MintEpochs[0]
+
+	// the former identity key must have been replaced by the mint
+	if err := m.Validate(net); !errors.Is(err, ErrFormerIdentity) {
+		t.Errorf("Validate() should fail with ErrFormerIdentity, got: %v", err)
+	}
+	r, err := SignKeyReplacement(newKey, ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	net.MintReplace(r)
+	if err := m.Validate(net); err != nil {
+		t.Fatal(err)
+	}
+
+	// a foreign identity key
+	other, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SignedBy = other
+	if err := m.Validate(net); !errors.Is(err, ErrFormerIdentity) {
+		t.Errorf("Validate() should fail with ErrFormerIdentity, got: %v", err)
+	}
+}
@@ -11,6 +11,10 @@ const DefNetConfFile = "federation.json"
 // DefMintDir defines the default sub-directory for mint configurations.
 const DefMintDir = "mints"
 
+// DefRevocationDir defines the default sub-directory for mint key
+// revocations.
+const DefRevocationDir = "revocations"
+
 // DefDBCDir defines the default sub-directory for DBC creation and
 // destruction lists.
 const DefDBCDir = "dbcs"
@@ -24,11 +28,19 @@ const DefDBCCreate = "create.json"
 // DefDBCDestroyed defines the name of the list of destroyed DBCs.
 const DefDBCDestroyed = "destroyed.json"
 
+// DefCommitmentLogFile defines the default name of exported commitment logs.
+const DefCommitmentLogFile = "commitlog.json"
+
 // DefStartTime defines the default signing start: tomorrow at midnight.
 func DefStartTime() time.Time {
-	now := time.Now().UTC()
-	year, month, day := now.Date()
-	t := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
-	t = t.Add(time.Hour * 48)
-	return t
+	return DefStartTimeAt(time.Now())
+}
+
+// DefStartTimeAt defines the default signing start relative to t: the day
+// after t at midnight.
+func DefStartTimeAt(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	start = start.Add(time.Hour * 48)
+	return start
 }
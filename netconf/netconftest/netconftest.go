@@ -0,0 +1,73 @@
+// Package netconftest implements a test federation for packages which build
+// on netconf.
+package netconftest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/netconf"
+	"github.com/scritcash/scrit/util/def"
+)
+
+// Federation is a 2-of-3 test federation with a single signing epoch which
+// starts at netconf.DefStartTime(). All private keys are known.
+type Federation struct {
+	*netconf.Federation
+	IdentityKeys []*netconf.IdentityKey // private identity keys of the mints
+	PrivateKeys  []*[64]byte            // the same private identity keys
+	Signers      []*netconf.KeySigner   // signers for all keys of the mints
+	At           time.Time              // time in the first signing epoch
+}
+
+// NewFederation returns a new test federation which defines the given DBC
+// types. It fails the test t, if the federation cannot be created.
+func NewFederation(t testing.TB, dbcTypes ...netconf.DBCType) *Federation {
+	t.Helper()
+	f := new(Federation)
+	var keys []netconf.IdentityKey
+	for i := 0; i < 3; i++ {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var privKey [64]byte
+		copy(privKey[:], priv)
+		ik := netconf.NewIdentityKeyEd25519Priv(&privKey)
+		f.IdentityKeys = append(f.IdentityKeys, ik)
+		f.PrivateKeys = append(f.PrivateKeys, &privKey)
+		keys = append(keys, *ik)
+	}
+	start := netconf.DefStartTime()
+	net := netconf.NewNetwork(2, 3, start, start.Add(def.SigningPeriod),
+		start.Add(def.SigningPeriod).Add(def.ValidationPeriod), keys)
+	for _, dbcType := range dbcTypes {
+		net.DBCTypeAdd(dbcType)
+	}
+	f.Federation = &netconf.Federation{
+		Network: net,
+		Mints:   make(map[string]*netconf.Mint),
+	}
+	for _, ik := range f.IdentityKeys {
+		m, err := netconf.NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Mints[ik.MarshalID()] = m
+		f.Signers = append(f.Signers, netconf.NewMintSigner(ik, m))
+	}
+	f.At = start.Add(time.Hour)
+	return f
+}
+
+// MintID returns the ID of the i-th mint.
+func (f *Federation) MintID(i int) string {
+	return f.IdentityKeys[i].MarshalID()
+}
+
+// Mint returns the public key list of the i-th mint.
+func (f *Federation) Mint(i int) *netconf.Mint {
+	return f.Mints[f.MintID(i)]
+}
@@ -2,18 +2,18 @@ package netconf
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"io/fs"
 	"time"
 
-	"github.com/frankbraun/codechain/util/file"
+	"github.com/scritcash/scrit/util/atomicfile"
 )
 
 // Network defines a Scrit network.
 type Network struct {
+	Version       int            `json:",omitempty"` // file format version (see NetworkVersion)
 	NetworkEpochs []NetworkEpoch // global list of signing epochs
+	Currencies    []Currency     `json:",omitempty"` // custom assets declared by the network
 }
 
 // NewNetwork creates a new network configuration and returns the Network
@@ -24,6 +24,7 @@ func NewNetwork(
 	mintIdentities []IdentityKey,
 ) *Network {
 	var network Network
+	network.Version = NetworkVersion
 	network.NetworkEpochs = []NetworkEpoch{
 		{
 			QuorumM:        m,
@@ -40,7 +41,24 @@ func NewNetwork(
 // LoadNetwork loads a network configuration from filename and return
 // the Network struct.
 func LoadNetwork(filename string) (*Network, error) {
-	data, err := ioutil.ReadFile(filename)
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalNetwork(data)
+}
+
+// loadNetworkFS loads a network configuration from the file name in fsys.
+func loadNetworkFS(fsys fs.FS, name string) (*Network, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalNetwork(data)
+}
+
+func unmarshalNetwork(data []byte) (*Network, error) {
+	data, err := migrateNetwork(data)
 	if err != nil {
 		return nil, err
 	}
@@ -48,39 +66,87 @@ func LoadNetwork(filename string) (*Network, error) {
 	if err := json.Unmarshal(data, &n); err != nil {
 		return nil, err
 	}
-	return &n, err
+	// register custom assets
+	for _, c := range n.Currencies {
+		if err := declareCurrency(c); err != nil {
+			return nil, err
+		}
+	}
+	return &n, nil
 }
 
 // Validate the network configuration.
 func (n *Network) Validate() error {
+	return n.Report().Err()
+}
+
+// Report validates the network configuration and returns a report listing
+// all found issues.
+func (n *Network) Report() *ValidationReport {
+	var r ValidationReport
+	n.validate(&r)
+	return &r
+}
+
+// validate the network configuration and add all found issues to the report
+// r.
+func (n *Network) validate(r *ValidationReport) {
 	// validate each network epoch
-	for _, e := range n.NetworkEpochs {
-		if err := e.Validate(); err != nil {
-			return err
-		}
+	for i := range n.NetworkEpochs {
+		n.NetworkEpochs[i].validate(r, i)
 	}
 	// validate network epoch transitions
 	for i := 1; i < len(n.NetworkEpochs); i++ {
 		// sign end i-1 == sign start i
 		if n.NetworkEpochs[i-1].SignEnd != n.NetworkEpochs[i].SignStart {
-			return ErrSignEpochWrongBoundaries
+			r.addError(i, "", ErrSignEpochWrongBoundaries)
 		}
 		// validation end i-1 <= sign end i
 		if n.NetworkEpochs[i-1].ValidateEnd.After(n.NetworkEpochs[i].SignEnd) {
-			return ErrValidationLongerThanNextSigning
+			r.addError(i, "", ErrValidationLongerThanNextSigning)
 		}
 	}
 
 	// validate Mints
-	if err := n.MintsValidate(); err != nil {
-		return err
-	}
+	n.mintsValidate(r)
+
+	// validate custom assets
+	n.currenciesValidate(r)
 
 	// validate DBC types
-	if err := n.DBCTypesValidate(); err != nil {
-		return err
+	n.dbcTypesValidate(r)
+}
+
+// currenciesValidate makes sure the custom assets declared by the network
+// are valid, unique, and registered.
+func (n *Network) currenciesValidate(r *ValidationReport) {
+	codes := make(map[string]bool)
+	for _, c := range n.Currencies {
+		if codes[c.Code] {
+			r.addError(NoEpoch, "", fmt.Errorf("%w: %s declared twice", ErrAssetInvalid, c.Code))
+		}
+		codes[c.Code] = true
+		if err := c.validateAsset(); err != nil {
+			r.addError(NoEpoch, "", err)
+			continue
+		}
+		if rc, err := LookupCurrency(c.Code); err != nil || rc != c {
+			r.addError(NoEpoch, "", fmt.Errorf("%w: %s not registered as declared", ErrAssetInvalid, c.Code))
+		}
 	}
+}
 
+// CurrencyAdd declares the custom asset c in the network and registers it.
+func (n *Network) CurrencyAdd(c Currency) error {
+	for _, d := range n.Currencies {
+		if d.Code == c.Code {
+			return fmt.Errorf("%w: %s declared already", ErrAssetInvalid, c.Code)
+		}
+	}
+	if err := declareCurrency(c); err != nil {
+		return err
+	}
+	n.Currencies = append(n.Currencies, c)
 	return nil
 }
 
@@ -96,32 +162,22 @@ func (n *Network) Marshal() string {
 // Save network to filename. If filename exists already it will be
 // overwritten!
 func (n *Network) Save(filename string) error {
-	jsn, err := json.MarshalIndent(n, "", "  ")
+	jsn, err := MarshalCanonical(n)
 	if err != nil {
 		return err
 	}
-	exists, err := file.Exists(filename)
-	if err != nil {
-		return err
-	}
-	if exists {
-		if err := os.Rename(filename, filename+".bac"); err != nil {
-			return err
-		}
-	}
-	if err := ioutil.WriteFile(filename, jsn, 0755); err != nil {
-		return err
-	}
-	if exists {
-		return os.Remove(filename + ".bac")
-	}
-	return nil
+	return atomicfile.WriteFile(filename, jsn, 0644)
 }
 
 // HasFuture ensures that the network has an epoch which starts in the future.
 func (n *Network) HasFuture() error {
+	return n.HasFutureAt(time.Now())
+}
+
+// HasFutureAt ensures that the network has an epoch which starts after t.
+func (n *Network) HasFutureAt(t time.Time) error {
 	e := n.NetworkEpochs[len(n.NetworkEpochs)-1]
-	if !e.SignStart.After(time.Now().UTC()) {
+	if !e.SignStart.After(t) {
 		return ErrNoFuture
 	}
 	return nil
@@ -160,9 +216,31 @@ func (n *Network) AllMints() map[string]bool {
 	return mints
 }
 
+// FormerIdentities returns a map of all identity keys which have been
+// replaced (directly or indirectly) by the mint identity key with the given
+// ID.
+func (n *Network) FormerIdentities(id string) map[string]bool {
+	former := make(map[string]bool)
+	for i := len(n.NetworkEpochs) - 1; i >= 0; i-- {
+		replaced := n.NetworkEpochs[i].MintsReplaced
+		for j := len(replaced) - 1; j >= 0; j-- {
+			newID := replaced[j].NewKey.MarshalID()
+			if newID == id || former[newID] {
+				former[replaced[j].OldKey.MarshalID()] = true
+			}
+		}
+	}
+	return former
+}
+
 // CurrentMints returns a map of all mints in the network at the current time
 func (n *Network) CurrentMints() (map[string]bool, error) {
-	c, err := n.CurrentEpoch()
+	return n.MintsAt(time.Now())
+}
+
+// MintsAt returns a map of all mints in the network at time t.
+func (n *Network) MintsAt(t time.Time) (map[string]bool, error) {
+	c, err := n.SigningEpochAt(t)
 	if err != nil {
 		return nil, err
 	}
@@ -185,18 +263,24 @@ func (n *Network) CurrentMints() (map[string]bool, error) {
 
 // MintsValidate validates the mint types.
 func (n *Network) MintsValidate() error {
+	var r ValidationReport
+	n.mintsValidate(&r)
+	return r.Err()
+}
+
+func (n *Network) mintsValidate(r *ValidationReport) {
 	mints := make(map[string]bool)
-	for _, e := range n.NetworkEpochs {
+	for i, e := range n.NetworkEpochs {
 		// make sure the MintsAdded, MintsRemoved, and MintsReplaced sets are
 		// disjunct
 		if err := e.MintsDisjunct(); err != nil {
-			return err
+			r.addError(i, "", err)
 		}
 		for _, add := range e.MintsAdded {
 			// make sure we do not add an exisiting mint
 			id := add.MarshalID()
 			if mints[id] {
-				return fmt.Errorf("netconf: mint already added: %v", id)
+				r.addError(i, id, fmt.Errorf("%w: %v", ErrMintAlreadyAdded, id))
 			}
 			mints[id] = true
 		}
@@ -205,30 +289,29 @@ func (n *Network) MintsValidate() error {
 			// make sure the mint to delete is actually there
 			_, present := mints[id]
 			if !present {
-				return fmt.Errorf("netconf: mint to remove not added: %v", id)
+				r.addError(i, id, fmt.Errorf("netconf: mint to remove not added: %v: %w", id, ErrMintNotAdded))
 			}
 			delete(mints, id)
 		}
 		for _, replace := range e.MintsReplaced {
-			if err := replace.Verify(); err != nil {
-				return err
-			}
 			oldID := replace.OldKey.MarshalID()
 			newID := replace.NewKey.MarshalID()
+			if err := replace.Verify(); err != nil {
+				r.addError(i, oldID, err)
+			}
 			// make sure the mint to replace is actually there
 			_, present := mints[oldID]
 			if !present {
-				return fmt.Errorf("netconf: mint to replace not added: %v", oldID)
+				r.addError(i, oldID, fmt.Errorf("netconf: mint to replace not added: %v: %w", oldID, ErrMintNotAdded))
 			}
 			delete(mints, oldID)
 			// make sure we do not replace to an exisiting mint
 			if mints[newID] {
-				return fmt.Errorf("netconf: mint to replace to already added: %v", newID)
+				r.addError(i, newID, fmt.Errorf("netconf: mint to replace to already added: %v: %w", newID, ErrMintAlreadyAdded))
 			}
 			mints[newID] = true
 		}
 	}
-	return nil
 }
 
 // MintAdd adds the mint identity key to the network.
@@ -255,8 +338,16 @@ func (n *Network) MintReplace(r *KeyReplacement) {
 
 // DBCTypes returns a map of all DBCTypes in the network.
 func (n *Network) DBCTypes() map[DBCType]bool {
+	return n.DBCTypesAt(len(n.NetworkEpochs) - 1)
+}
+
+// DBCTypesAt returns a map of all DBCTypes in the given epoch.
+func (n *Network) DBCTypesAt(epoch int) map[DBCType]bool {
 	dbcTypes := make(map[DBCType]bool)
-	for _, e := range n.NetworkEpochs {
+	for i, e := range n.NetworkEpochs {
+		if i > epoch {
+			break
+		}
 		for _, add := range e.DBCTypesAdded {
 			dbcTypes[add] = true
 		}
@@ -269,29 +360,38 @@ func (n *Network) DBCTypes() map[DBCType]bool {
 
 // DBCTypesValidate validates the DBC types.
 func (n *Network) DBCTypesValidate() error {
+	var r ValidationReport
+	n.dbcTypesValidate(&r)
+	return r.Err()
+}
+
+func (n *Network) dbcTypesValidate(r *ValidationReport) {
 	dbcTypes := make(map[DBCType]bool)
-	for _, e := range n.NetworkEpochs {
+	for i, e := range n.NetworkEpochs {
 		// make sure the DBCTypesAdded and DBCTypesRemoved sets are disjunct
 		if err := e.DBCTypesDisjunct(); err != nil {
-			return err
+			r.addError(i, "", err)
 		}
 		for _, add := range e.DBCTypesAdded {
 			// make sure we do not add an exisiting DBC type
 			if dbcTypes[add] {
-				return fmt.Errorf("netconf: DBC type already defined: %v", add)
+				r.addError(i, "", fmt.Errorf("%w: %v", ErrDBCTypeAlreadyDefined, add))
 			}
 			dbcTypes[add] = true
+			// make sure currency and amount make sense
+			if err := add.Validate(); err != nil {
+				r.addWarning(i, "", err)
+			}
 		}
 		for _, remove := range e.DBCTypesRemoved {
 			// make sure the type to delete is actually there
 			_, present := dbcTypes[remove]
 			if !present {
-				return fmt.Errorf("netconf: DBC type not defined: %v", remove)
+				r.addError(i, "", fmt.Errorf("%w: %v", ErrDBCTypeNotDefined, remove))
 			}
 			delete(dbcTypes, remove)
 		}
 	}
-	return nil
 }
 
 // DBCTypeAdd adds the DBC type to the network.
@@ -331,20 +431,38 @@ func (n *Network) SetQuorum(m uint64) {
 // CurrentEpoch returns the current signing epoch number or an error if no
 // such epoch exists.
 func (n *Network) CurrentEpoch() (int, error) {
-	// make sure we still have a valid signing epoch
-	i := len(n.NetworkEpochs) - 1
-	now := time.Now().UTC()
-	if now.After(n.NetworkEpochs[i].SignEnd) {
-		return 0, errors.New("netconf: no valid signing epoch found")
-	}
-	// determine current epoch
-	for ; i >= 0; i-- {
-		if now.After(n.NetworkEpochs[i].SignStart) {
-			break
+	return n.SigningEpochAt(time.Now())
+}
+
+// SigningEpochAt returns the number of the signing epoch at time t or
+// ErrNoSigningEpoch, if no such epoch exists. An epoch i is the signing epoch
+// at time t, if SignStart <= t < SignEnd. That is, at the exact boundary
+// between two epochs the later epoch is the signing epoch.
+//
+// Times before the SignStart of the first epoch and times at or after the
+// SignEnd of the last epoch have no signing epoch. SigningEpochAt then returns
+// ErrNoSigningEpoch and the returned epoch number 0 must not be used.
+func (n *Network) SigningEpochAt(t time.Time) (int, error) {
+	for i := len(n.NetworkEpochs) - 1; i >= 0; i-- {
+		e := n.NetworkEpochs[i]
+		if !t.Before(e.SignStart) && t.Before(e.SignEnd) {
+			return i, nil
 		}
 	}
-	if i < 0 {
-		i = 0
+	return 0, ErrNoSigningEpoch
+}
+
+// ValidatingEpochsAt returns the numbers of all epochs in ascending order
+// whose keys validate DBCs at time t. An epoch i validates at time t, if
+// SignStart <= t < ValidateEnd. That is, the signing epoch at time t is always
+// included and the previous epochs are included until their validation
+// period ended. If no epoch validates at time t, nil is returned.
+func (n *Network) ValidatingEpochsAt(t time.Time) []int {
+	var epochs []int
+	for i, e := range n.NetworkEpochs {
+		if !t.Before(e.SignStart) && t.Before(e.ValidateEnd) {
+			epochs = append(epochs, i)
+		}
 	}
-	return i, nil
+	return epochs
 }
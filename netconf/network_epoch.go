@@ -19,35 +19,50 @@ type NetworkEpoch struct {
 	DBCTypesRemoved []DBCType        `json:",omitempty"` // DBC types removed in this epoch
 }
 
+// SigningPeriod returns the length of the signing period of the epoch.
+func (e *NetworkEpoch) SigningPeriod() time.Duration {
+	return e.SignEnd.Sub(e.SignStart)
+}
+
+// ValidationPeriod returns the length of the validation period of the epoch.
+func (e *NetworkEpoch) ValidationPeriod() time.Duration {
+	return e.ValidateEnd.Sub(e.SignEnd)
+}
+
 // Validate the network epoch.
 func (e *NetworkEpoch) Validate() error {
+	var r ValidationReport
+	e.validate(&r, NoEpoch)
+	return r.Err()
+}
+
+// validate the network epoch with the given index and add all found issues to
+// the report r.
+func (e *NetworkEpoch) validate(r *ValidationReport, epoch int) {
 	// m > 0
 	if e.QuorumM == 0 {
-		return ErrZeroM
+		r.addError(epoch, "", ErrZeroM)
 	}
 	// n > 0
 	if e.NumberOfMintsN == 0 {
-		return ErrZeroN
+		r.addError(epoch, "", ErrZeroN)
 	}
 	// m <= n
 	if e.QuorumM > e.NumberOfMintsN {
-		return ErrMGreaterN
-	}
-	// m > n/2
-	if e.QuorumM <= e.NumberOfMintsN/2 {
-		return ErrQuorumTooSmall
+		r.addError(epoch, "", ErrMGreaterN)
+	} else if e.QuorumM != 0 && e.QuorumM <= e.NumberOfMintsN/2 {
+		// m > n/2
+		r.addError(epoch, "", ErrQuorumTooSmall)
 	}
 
 	// sign epoch start < sign epoch end
 	if !e.SignStart.Before(e.SignEnd) {
-		return ErrSignEpochStartNotBeforeSignEnd
+		r.addError(epoch, "", ErrSignEpochStartNotBeforeSignEnd)
 	}
 	// sign epoch end < validation epoch end
 	if !e.SignEnd.Before(e.ValidateEnd) {
-		return ErrSignEpochEndNotBeforeValidateEnd
+		r.addError(epoch, "", ErrSignEpochEndNotBeforeValidateEnd)
 	}
-
-	return nil
 }
 
 // MintsDisjunct make sure the MintsAdded, MintsRemoved, and MintsReplaced
@@ -1,8 +1,12 @@
 package netconf
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestLoadNetwork(t *testing.T) {
@@ -16,6 +20,36 @@ func TestLoadNetwork(t *testing.T) {
 	_ = DBCTypeMapToSortedArray(net.DBCTypes())
 }
 
+func TestSaveNetwork(t *testing.T) {
+	net, err := LoadNetwork(filepath.Join("testdata", DefNetConfFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), DefNetConfFile)
+	if err := net.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0644 {
+		t.Errorf("network configuration has wrong mode: %v", fi.Mode())
+	}
+
+	// simulate save interrupted after renaming to backup file
+	if err := os.Rename(filename, filename+".bac"); err != nil {
+		t.Fatal(err)
+	}
+	net2, err := LoadNetwork(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(net, net2) {
+		t.Error("recovered network configuration differs")
+	}
+}
+
 func TestNetworkValidate(t *testing.T) {
 	testCases := []struct {
 		net       Network
@@ -23,7 +57,7 @@ func TestNetworkValidate(t *testing.T) {
 	}{
 		{
 			Network{
-				[]NetworkEpoch{
+				NetworkEpochs: []NetworkEpoch{
 					{
 						QuorumM:        8,
 						NumberOfMintsN: 10,
@@ -44,7 +78,7 @@ func TestNetworkValidate(t *testing.T) {
 		},
 		{
 			Network{
-				[]NetworkEpoch{
+				NetworkEpochs: []NetworkEpoch{
 					{
 						QuorumM:        8,
 						NumberOfMintsN: 10,
@@ -74,3 +108,122 @@ func TestNetworkValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestEpochsAt(t *testing.T) {
+	net := Network{
+		NetworkEpochs: []NetworkEpoch{
+			{
+				QuorumM:        2,
+				NumberOfMintsN: 3,
+				SignStart:      t1,
+				SignEnd:        t2,
+				ValidateEnd:    t3,
+			},
+			{
+				QuorumM:        2,
+				NumberOfMintsN: 3,
+				SignStart:      t2,
+				SignEnd:        t3,
+				ValidateEnd:    t4,
+			},
+		},
+	}
+	testCases := []struct {
+		t          time.Time
+		signing    int
+		errorCode  error
+		validating []int
+	}{
+		{time.Time{}, 0, ErrNoSigningEpoch, nil},
+		{t1.Add(-time.Hour), 0, ErrNoSigningEpoch, nil},
+		{t1.Add(-time.Nanosecond), 0, ErrNoSigningEpoch, nil},
+		{t1, 0, nil, []int{0}},
+		{t2.Add(-time.Nanosecond), 0, nil, []int{0}},
+		{t2, 1, nil, []int{0, 1}},
+		{t3.Add(-time.Nanosecond), 1, nil, []int{0, 1}},
+		{t3, 0, ErrNoSigningEpoch, []int{1}},
+		{t4.Add(-time.Nanosecond), 0, ErrNoSigningEpoch, []int{1}},
+		{t4, 0, ErrNoSigningEpoch, nil},
+	}
+	for _, testCase := range testCases {
+		i, err := net.SigningEpochAt(testCase.t)
+		if err != testCase.errorCode {
+			t.Errorf("SigningEpochAt(%v) should have error code: %v (has %v)",
+				testCase.t, testCase.errorCode, err)
+		} else if err == nil && i != testCase.signing {
+			t.Errorf("SigningEpochAt(%v) == %d != %d", testCase.t, i, testCase.signing)
+		}
+		epochs := net.ValidatingEpochsAt(testCase.t)
+		if !reflect.DeepEqual(epochs, testCase.validating) {
+			t.Errorf("ValidatingEpochsAt(%v) == %v != %v",
+				testCase.t, epochs, testCase.validating)
+		}
+	}
+}
+
+func TestNetworkCurrencies(t *testing.T) {
+	net, err := LoadNetwork(filepath.Join("testdata", DefNetConfFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), DefNetConfFile)
+
+	// declared assets are registered on load
+	silver := Currency{Code: "NETSILVER", Decimals: 3, Symbol: "ag"}
+	net.Currencies = []Currency{silver}
+	if err := net.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LookupCurrency(silver.Code); err == nil {
+		t.Fatal("asset registered before load")
+	}
+	net2, err := LoadNetwork(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := LookupCurrency(silver.Code); err != nil || c != silver {
+		t.Errorf("declared asset not registered: %v", err)
+	}
+	dbc, err := ParseDBCType(silver.Code, "1.125")
+	if err != nil {
+		t.Fatal(err)
+	}
+	net2.DBCTypeAdd(dbc)
+	if err := net2.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// add asset
+	gold := Currency{Code: "NETGOLD", Decimals: 2}
+	if err := net2.CurrencyAdd(gold); err != nil {
+		t.Fatal(err)
+	}
+	if err := net2.CurrencyAdd(gold); !errors.Is(err, ErrAssetInvalid) {
+		t.Errorf("CurrencyAdd() should fail with ErrAssetInvalid, got: %v", err)
+	}
+	if err := net2.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// invalid declarations are rejected on load
+	for _, c := range []Currency{
+		{Code: "gold", Decimals: 2},
+		{Code: "USD", Decimals: 2, Symbol: "$"},
+		{Code: "NETSILVER", Decimals: 2},
+		{Code: "NETCOPPER", Decimals: AmountDecimals + 1},
+	} {
+		net.Currencies = []Currency{c}
+		if err := net.Save(filename); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadNetwork(filename); !errors.Is(err, ErrAssetInvalid) {
+			t.Errorf("%s: LoadNetwork() should fail with ErrAssetInvalid, got: %v", c.Code, err)
+		}
+	}
+
+	// duplicate declaration
+	net2.Currencies = append(net2.Currencies, gold)
+	if err := net2.Validate(); !errors.Is(err, ErrAssetInvalid) {
+		t.Errorf("Validate() should fail with ErrAssetInvalid, got: %v", err)
+	}
+}
@@ -0,0 +1,513 @@
+package netconf
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/frankbraun/codechain/util/bzero"
+	"github.com/scritcash/scrit/util/atomicfile"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// KeyListEncryption defines the encryption scheme of private key lists.
+const KeyListEncryption = "x25519-box"
+
+// keyListEncryptionLegacy defines the former encryption scheme of private key
+// lists, with a key derived from the private identity key. Such key lists are
+// still loaded.
+const keyListEncryptionLegacy = "blake2b-secretbox"
+
+// keyListKeyContext is used to derive the key list encryption key from the
+// private identity key (legacy).
+const keyListKeyContext = "scrit private key list encryption"
+
+// EncryptedKeyList defines the format of an encrypted private key list file.
+// The key list itself is public (only the identity signatures might be
+// pending), the private signing keys are sealed to the identity key of the
+// mint in boxes. Sealing only requires the public identity key, which allows
+// to prepare key lists on a host without the private identity key. Opening
+// the boxes requires the private identity key, which is itself protected by
+// a passphrase.
+//
+// Mints with a threshold identity key (see package frost) have no private
+// identity key to open the boxes with, their private keys are sealed to a
+// separate X25519 box key instead (see EncryptBoxKey).
+type EncryptedKeyList struct {
+	Encryption      string   // encryption scheme (KeyListEncryption)
+	MintIdentityKey string   // ID of the identity key the key list belongs to
+	BoxKey          string   `json:",omitempty"` // X25519 key the boxes are sealed to (base64), instead of identity key
+	KeyList         *Mint    `json:",omitempty"` // key list without private keys
+	Boxes           []KeyBox `json:",omitempty"` // sealed private keys
+	Nonce           string   `json:",omitempty"` // secretbox nonce (base64, legacy)
+	Box             string   `json:",omitempty"` // encrypted private key list (base64, legacy)
+}
+
+// KeyBox contains private signing keys sealed to an identity key (or a box
+// key).
+type KeyBox struct {
+	EphemeralKey string // ephemeral X25519 public key (base64)
+	Nonce        string // box nonce (base64)
+	Box          string // sealed private keys (base64)
+}
+
+// curve25519P is the field prime 2^255 - 19.
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// boxPublicKey returns the X25519 public key corresponding to the Ed25519
+// public key of ik, the birational map u = (1 + y) / (1 - y) (the public key
+// is not secret, therefore math/big is fine).
+func (ik *IdentityKey) boxPublicKey() (*[32]byte, error) {
+	if len(ik.PubKey) != ed25519.PublicKeySize {
+		return nil, ErrKeyListIdentity
+	}
+	// y is encoded little-endian with the sign of x in the top bit
+	le := make([]byte, 32)
+	copy(le, ik.PubKey)
+	le[31] &= 0x7f
+	for i, j := 0, len(le)-1; i < j; i, j = i+1, j-1 {
+		le[i], le[j] = le[j], le[i]
+	}
+	y := new(big.Int).SetBytes(le)
+	num := new(big.Int).Add(big.NewInt(1), y)
+	den := new(big.Int).Sub(big.NewInt(1), y)
+	den.Mod(den, curve25519P)
+	if den.ModInverse(den, curve25519P) == nil {
+		return nil, ErrKeyListIdentity
+	}
+	u := num.Mul(num, den)
+	u.Mod(u, curve25519P)
+	var pub [32]byte
+	b := u.Bytes()
+	for i := range b {
+		pub[i] = b[len(b)-1-i]
+	}
+	return &pub, nil
+}
+
+// boxPrivateKey returns the X25519 private key corresponding to the private
+// Ed25519 key of ik (the clamping is done by X25519).
+func (ik *IdentityKey) boxPrivateKey() (*[32]byte, error) {
+	if len(ik.privKey) != ed25519.PrivateKeySize {
+		return nil, ErrPrivKeyMissing
+	}
+	h := sha512.Sum512(ik.privKey.Seed())
+	defer bzero.Bytes(h[:])
+	var priv [32]byte
+	copy(priv[:], h[:32])
+	return &priv, nil
+}
+
+// ParseBoxKey parses the X25519 public box key s (base64).
+func ParseBoxKey(s string) (*[32]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBoxKey, err)
+	}
+	if len(b) != 32 {
+		return nil, ErrBoxKey
+	}
+	var key [32]byte
+	copy(key[:], b)
+	return &key, nil
+}
+
+// MarshalBoxKey encodes the X25519 public box key key as base64.
+func MarshalBoxKey(key *[32]byte) string {
+	return base64.RawURLEncoding.EncodeToString(key[:])
+}
+
+// sealKeys seals the private keys to the X25519 public key peer.
+func sealKeys(peer *[32]byte, keys []ed25519.PrivateKey) (*KeyBox, error) {
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(priv[:])
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+	jsn, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(jsn)
+	return &KeyBox{
+		EphemeralKey: base64.RawURLEncoding.EncodeToString(pub[:]),
+		Nonce:        base64.RawURLEncoding.EncodeToString(nonce[:]),
+		Box:          base64.RawURLEncoding.EncodeToString(box.Seal(nil, jsn, &nonce, peer, priv)),
+	}, nil
+}
+
+// open the box with the X25519 private key priv and return the contained
+// private keys.
+func (b *KeyBox) open(priv *[32]byte) ([]ed25519.PrivateKey, error) {
+	p, err := base64.RawURLEncoding.DecodeString(b.EphemeralKey)
+	if err != nil {
+		return nil, err
+	}
+	n, err := base64.RawURLEncoding.DecodeString(b.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(p) != 32 || len(n) != 24 {
+		return nil, ErrKeyListDecrypt
+	}
+	var (
+		peer  [32]byte
+		nonce [24]byte
+	)
+	copy(peer[:], p)
+	copy(nonce[:], n)
+	boxed, err := base64.RawURLEncoding.DecodeString(b.Box)
+	if err != nil {
+		return nil, err
+	}
+	jsn, ok := box.Open(nil, boxed, &nonce, &peer, priv)
+	if !ok {
+		return nil, ErrKeyListDecrypt
+	}
+	defer bzero.Bytes(jsn)
+	var keys []ed25519.PrivateKey
+	if err := json.Unmarshal(jsn, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// public returns a copy of the key list m without private keys.
+func (m *Mint) public() *Mint {
+	p := *m
+	p.MintIdentityKey = IdentityKey{
+		SigAlgo: m.MintIdentityKey.SigAlgo,
+		PubKey:  m.MintIdentityKey.PubKey,
+	}
+	p.MintEpochs = nil
+	for _, e := range m.MintEpochs {
+		p.MintEpochs = append(p.MintEpochs, e.public())
+	}
+	return &p
+}
+
+// privKeys returns all private keys contained in the key list m.
+func (m *Mint) privKeys() []ed25519.PrivateKey {
+	var keys []ed25519.PrivateKey
+	for _, e := range m.MintEpochs {
+		for _, k := range e.KeyList {
+			if len(k.PrivKey) > 0 {
+				keys = append(keys, k.PrivKey)
+			}
+		}
+	}
+	return keys
+}
+
+// keyListKey derives the key list encryption key from the private key of ik.
+func (ik *IdentityKey) keyListKey() (*[32]byte, error) {
+	if len(ik.privKey) == 0 {
+		return nil, ErrPrivKeyMissing
+	}
+	h, err := blake2b.New256(ik.privKey.Seed())
+	if err != nil {
+		return nil, err
+	}
+	h.Write([]byte(keyListKeyContext))
+	var key [32]byte
+	copy(key[:], h.Sum(nil))
+	return &key, nil
+}
+
+// Encrypt the private key list m by sealing its private keys to the identity
+// key ik, which doesn't require the private identity key.
+func (m *Mint) Encrypt(ik *IdentityKey) (*EncryptedKeyList, error) {
+	if ik.MarshalID() != m.MintIdentityKey.MarshalID() {
+		return nil, ErrKeyListIdentity
+	}
+	e := &EncryptedKeyList{
+		Encryption:      KeyListEncryption,
+		MintIdentityKey: ik.MarshalID(),
+	}
+	if err := e.Add(m); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Add replaces the key list of e with m and seals the private keys
+// contained in m additionally (the private keys already sealed in e are
+// kept). This allows to extend an encrypted key list without the private
+// identity key.
+func (e *EncryptedKeyList) Add(m *Mint) error {
+	if e.Encryption != KeyListEncryption {
+		return fmt.Errorf("netconf: cannot add to key list encrypted with %s",
+			e.Encryption)
+	}
+	ik := &m.MintIdentityKey
+	if e.MintIdentityKey != ik.MarshalID() {
+		return ErrKeyListIdentity
+	}
+	e.KeyList = m.public()
+	if keys := m.privKeys(); len(keys) > 0 {
+		var (
+			peer *[32]byte
+			err  error
+		)
+		if e.BoxKey != "" {
+			peer, err = ParseBoxKey(e.BoxKey)
+		} else {
+			peer, err = ik.boxPublicKey()
+		}
+		if err != nil {
+			return err
+		}
+		b, err := sealKeys(peer, keys)
+		if err != nil {
+			return err
+		}
+		e.Boxes = append(e.Boxes, *b)
+	}
+	return nil
+}
+
+// EncryptBoxKey encrypts the private key list m like Encrypt, but seals its
+// private keys to the X25519 public key boxKey instead of the identity key.
+// Used by mints with a threshold identity key.
+func (m *Mint) EncryptBoxKey(boxKey *[32]byte) (*EncryptedKeyList, error) {
+	e := &EncryptedKeyList{
+		Encryption:      KeyListEncryption,
+		MintIdentityKey: m.MintIdentityKey.MarshalID(),
+		BoxKey:          MarshalBoxKey(boxKey),
+	}
+	if err := e.Add(m); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Decrypt the encrypted private key list with the private identity key ik.
+func (e *EncryptedKeyList) Decrypt(ik *IdentityKey) (*Mint, error) {
+	if e.MintIdentityKey != ik.MarshalID() {
+		return nil, ErrKeyListIdentity
+	}
+	if e.BoxKey != "" {
+		return nil, fmt.Errorf("%w: sealed to box key", ErrKeyListDecrypt)
+	}
+	switch e.Encryption {
+	case KeyListEncryption:
+		priv, err := ik.boxPrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		defer bzero.Bytes(priv[:])
+		return e.open(priv)
+	case keyListEncryptionLegacy:
+		return e.decryptLegacy(ik)
+	default:
+		return nil, fmt.Errorf("netconf: unknown key list encryption: %s",
+			e.Encryption)
+	}
+}
+
+// DecryptBoxKey decrypts the encrypted private key list with the X25519
+// private key priv, which must belong to the box key of e (see
+// EncryptBoxKey).
+func (e *EncryptedKeyList) DecryptBoxKey(priv *[32]byte) (*Mint, error) {
+	if e.Encryption != KeyListEncryption || e.BoxKey == "" {
+		return nil, fmt.Errorf("%w: not sealed to box key", ErrKeyListDecrypt)
+	}
+	pub, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	if base64.RawURLEncoding.EncodeToString(pub) != e.BoxKey {
+		return nil, fmt.Errorf("%w: wrong box key", ErrKeyListDecrypt)
+	}
+	return e.open(priv)
+}
+
+// open the boxes of e with the X25519 private key priv and add the private
+// keys to a copy of the key list.
+func (e *EncryptedKeyList) open(priv *[32]byte) (*Mint, error) {
+	if e.KeyList == nil {
+		return nil, fmt.Errorf("%w: key list missing", ErrKeyListDecrypt)
+	}
+	keys := make(map[string]ed25519.PrivateKey)
+	for i := range e.Boxes {
+		privKeys, err := e.Boxes[i].open(priv)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range privKeys {
+			if len(k) != ed25519.PrivateKeySize {
+				return nil, fmt.Errorf("%w: invalid private key", ErrKeyListDecrypt)
+			}
+			keys[string(k.Public().(ed25519.PublicKey))] = k
+		}
+	}
+	jsn, err := json.Marshal(e.KeyList)
+	if err != nil {
+		return nil, err
+	}
+	m, err := unmarshalMint(jsn)
+	if err != nil {
+		return nil, err
+	}
+	if m.MintIdentityKey.MarshalID() != e.MintIdentityKey {
+		return nil, ErrKeyListIdentity
+	}
+	for _, me := range m.MintEpochs {
+		for _, k := range me.KeyList {
+			k.PrivKey = keys[string(k.PubKey)]
+		}
+	}
+	return m, nil
+}
+
+// decryptLegacy decrypts a private key list encrypted with the key derived
+// from the private identity key ik.
+func (e *EncryptedKeyList) decryptLegacy(ik *IdentityKey) (*Mint, error) {
+	key, err := ik.keyListKey()
+	if err != nil {
+		return nil, err
+	}
+	defer bzero.Bytes(key[:])
+	n, err := base64.RawURLEncoding.DecodeString(e.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) != 24 {
+		return nil, ErrKeyListDecrypt
+	}
+	var nonce [24]byte
+	copy(nonce[:], n)
+	box, err := base64.RawURLEncoding.DecodeString(e.Box)
+	if err != nil {
+		return nil, err
+	}
+	jsn, ok := secretbox.Open(nil, box, &nonce, key)
+	if !ok {
+		return nil, ErrKeyListDecrypt
+	}
+	defer bzero.Bytes(jsn)
+	m, err := unmarshalMint(jsn)
+	if err != nil {
+		return nil, err
+	}
+	if m.MintIdentityKey.MarshalID() != ik.MarshalID() {
+		return nil, ErrKeyListIdentity
+	}
+	return m, nil
+}
+
+// unmarshalEncryptedKeyList unmarshals data as encrypted key list. If data
+// is not an encrypted key list (but a plaintext one), nil is returned.
+func unmarshalEncryptedKeyList(data []byte) (*EncryptedKeyList, error) {
+	var probe struct{ Encryption string }
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if probe.Encryption == "" {
+		return nil, nil
+	}
+	var e EncryptedKeyList
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// IsEncryptedKeyList returns true, if the private key list stored in
+// filename is encrypted.
+func IsEncryptedKeyList(filename string) (bool, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return false, err
+	}
+	e, err := unmarshalEncryptedKeyList(data)
+	if err != nil {
+		return false, err
+	}
+	return e != nil, nil
+}
+
+// LoadEncryptedKeyList loads the private key list from filename without
+// decrypting it, which doesn't require the private identity key. Plaintext
+// private key lists are encrypted. Private key lists in the legacy format
+// cannot be loaded this way.
+func LoadEncryptedKeyList(filename string) (*EncryptedKeyList, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	e, err := unmarshalEncryptedKeyList(data)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		// plaintext private key list (legacy)
+		m, err := unmarshalMint(data)
+		if err != nil {
+			return nil, err
+		}
+		return m.Encrypt(&m.MintIdentityKey)
+	}
+	if e.Encryption != KeyListEncryption || e.KeyList == nil {
+		return nil, fmt.Errorf("netconf: key list encrypted with %s must be migrated with the private identity key",
+			e.Encryption)
+	}
+	return e, nil
+}
+
+// Save the encrypted private key list to filename (mode 0600).
+func (e *EncryptedKeyList) Save(filename string) error {
+	jsn, err := MarshalCanonical(e)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0600)
+}
+
+// LoadPrivKeyList loads the private key list of the mint with identity key ik
+// from filename and decrypts it. For migration purposes plaintext private key
+// lists are loaded as well, they are encrypted with the next SavePrivKeyList.
+func LoadPrivKeyList(filename string, ik *IdentityKey) (*Mint, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	e, err := unmarshalEncryptedKeyList(data)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		// plaintext private key list (legacy)
+		m, err := unmarshalMint(data)
+		if err != nil {
+			return nil, err
+		}
+		if m.MintIdentityKey.MarshalID() != ik.MarshalID() {
+			return nil, ErrKeyListIdentity
+		}
+		return m, nil
+	}
+	return e.Decrypt(ik)
+}
+
+// SavePrivKeyList encrypts the private key list m for the identity key ik
+// (see Encrypt) and saves it to filename (mode 0600).
+func (m *Mint) SavePrivKeyList(filename string, ik *IdentityKey) error {
+	e, err := m.Encrypt(ik)
+	if err != nil {
+		return err
+	}
+	return e.Save(filename)
+}
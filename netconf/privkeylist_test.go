@@ -0,0 +1,172 @@
+package netconf
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scritcash/scrit/util/def"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestPrivKeyList(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	m, err := NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	// plaintext private key list (legacy)
+	filename := filepath.Join(dir, ik.MarshalID()+".json")
+	if err := m.Save(filename, 0700); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := IsEncryptedKeyList(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted {
+		t.Error("plaintext key list should not be encrypted")
+	}
+	m2, err := LoadPrivKeyList(filename, ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// migrate to encrypted private key list
+	if err := m2.SavePrivKeyList(filename, ik); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err = IsEncryptedKeyList(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !encrypted {
+		t.Error("key list should be encrypted")
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Errorf("wrong file mode: %s", fi.Mode())
+	}
+	m3, err := LoadPrivKeyList(filename, ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m3.Validate(net); err != nil {
+		t.Error(err)
+	}
+	if len(m3.MintEpochs[0].KeyList[0].PrivKey) == 0 {
+		t.Error("decrypted key list should contain private keys")
+	}
+
+	// wrong identity key
+	if _, err := LoadPrivKeyList(filename, other); !errors.Is(err, ErrKeyListIdentity) {
+		t.Errorf("LoadPrivKeyList() should fail with ErrKeyListIdentity, got: %v", err)
+	}
+	e, err := m.Encrypt(ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.MintIdentityKey = other.MarshalID()
+	if _, err := e.Decrypt(other); !errors.Is(err, ErrKeyListDecrypt) {
+		t.Errorf("Decrypt() should fail with ErrKeyListDecrypt, got: %v", err)
+	}
+}
+
+func TestPrivKeyListSealed(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the X25519 keys derived from the identity key correspond
+	pub, err := ik.boxPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv, err := ik.boxPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	derived, err := curve25519.X25519(priv[:], curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(derived, pub[:]) {
+		t.Fatal("X25519 public key doesn't match private key")
+	}
+
+	// prepare and extend key list without private identity key
+	pubIK := &IdentityKey{SigAlgo: ik.SigAlgo, PubKey: ik.PubKey}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*pubIK})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	m, _, err := PrepareMint("mint", pubIK, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), ik.MarshalID()+".json")
+	if err := m.SavePrivKeyList(filename, pubIK); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPrivKeyList(filename, pubIK); !errors.Is(err, ErrPrivKeyMissing) {
+		t.Errorf("LoadPrivKeyList() should fail with ErrPrivKeyMissing, got: %v", err)
+	}
+	e, err := LoadEncryptedKeyList(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(e.KeyList.MintEpochs[0].KeyList[0].PrivKey) != 0 {
+		t.Error("encrypted key list contains private key in plaintext")
+	}
+	net.EpochAdd(def.SigningPeriod, def.ValidationPeriod)
+	m = e.KeyList
+	req, err := m.PrepareExtend(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req == nil || req.Start != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if err := e.Add(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	// decrypt with private identity key
+	m, err = LoadPrivKeyList(filename, ik)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.MintEpochs) != 2 {
+		t.Fatalf("key list has %d epochs instead of 2", len(m.MintEpochs))
+	}
+	for i, me := range m.MintEpochs {
+		for _, k := range me.KeyList {
+			if len(k.PrivKey) == 0 ||
+				!bytes.Equal(k.PrivKey.Public().(ed25519.PublicKey), k.PubKey) {
+				t.Errorf("epoch %d: private key missing or wrong", i)
+			}
+		}
+	}
+}
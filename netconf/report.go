@@ -0,0 +1,224 @@
+package netconf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Severity defines the severity of a validation issue.
+type Severity int
+
+// Severities of validation issues.
+const (
+	SeverityWarning Severity = iota // configuration is usable, but degraded
+	SeverityError                   // configuration is invalid
+)
+
+// String returns the severity as string.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// MarshalJSON marshals the severity as JSON string.
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// NoEpoch is the epoch index of issues which do not refer to a specific
+// epoch.
+const NoEpoch = -1
+
+// Issue defines a single problem found during validation.
+type Issue struct {
+	Severity Severity // severity of issue
+	Epoch    int      // index of affected epoch (NoEpoch, if not epoch specific)
+	MintID   string   `json:",omitempty"` // ID of affected mint, if any
+	Code     string   // error code (name of the corresponding Err* variable)
+	Message  string   // human readable error message
+	err      error
+}
+
+// Err returns the error underlying the issue.
+func (i *Issue) Err() error {
+	return i.err
+}
+
+// String returns the issue as a single line of text.
+func (i *Issue) String() string {
+	var b strings.Builder
+	b.WriteString(i.Severity.String())
+	if i.Epoch != NoEpoch {
+		fmt.Fprintf(&b, " epoch %d", i.Epoch)
+	}
+	if i.MintID != "" {
+		fmt.Fprintf(&b, " mint %s", i.MintID)
+	}
+	fmt.Fprintf(&b, ": %s (%s)", i.Message, i.Code)
+	return b.String()
+}
+
+// ValidationReport lists all issues found while validating a federation
+// configuration.
+type ValidationReport struct {
+	Issues []Issue // all issues in the order they were found
+}
+
+// errorCodes maps the Err* variables of this package to their names.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrZeroM, "ErrZeroM"},
+	{ErrZeroN, "ErrZeroN"},
+	{ErrMGreaterN, "ErrMGreaterN"},
+	{ErrQuorumTooSmall, "ErrQuorumTooSmall"},
+	{ErrSignEpochStartNotBeforeSignEnd, "ErrSignEpochStartNotBeforeSignEnd"},
+	{ErrSignEpochEndNotBeforeValidateEnd, "ErrSignEpochEndNotBeforeValidateEnd"},
+	{ErrSignEpochWrongBoundaries, "ErrSignEpochWrongBoundaries"},
+	{ErrValidationLongerThanNextSigning, "ErrValidationLongerThanNextSigning"},
+	{ErrNoFuture, "ErrNoFuture"},
+	{ErrMintsOverlap, "ErrMintsOverlap"},
+	{ErrDBCTypesOverlap, "ErrDBCTypesOverlap"},
+	{ErrNoURL, "ErrNoURL"},
+	{ErrMintAlreadyAdded, "ErrMintAlreadyAdded"},
+	{ErrMintNotAdded, "ErrMintNotAdded"},
+	{ErrDBCTypeAlreadyDefined, "ErrDBCTypeAlreadyDefined"},
+	{ErrDBCTypeNotDefined, "ErrDBCTypeNotDefined"},
+	{ErrKeyReplacementSignature, "ErrKeyReplacementSignature"},
+	{ErrMintEpochsTooMany, "ErrMintEpochsTooMany"},
+	{ErrKeyListLength, "ErrKeyListLength"},
+	{ErrKeyCurrencyMismatch, "ErrKeyCurrencyMismatch"},
+	{ErrKeyAmountMismatch, "ErrKeyAmountMismatch"},
+	{ErrKeyListSignatures, "ErrKeyListSignatures"},
+	{ErrKeySignature, "ErrKeySignature"},
+	{ErrIdentitySignature, "ErrIdentitySignature"},
+	{ErrSignStartMismatch, "ErrSignStartMismatch"},
+	{ErrSignEndMismatch, "ErrSignEndMismatch"},
+	{ErrValidateEndMismatch, "ErrValidateEndMismatch"},
+	{ErrNoSigningEpoch, "ErrNoSigningEpoch"},
+	{ErrNoQuorum, "ErrNoQuorum"},
+	{ErrMintLoad, "ErrMintLoad"},
+	{ErrFormerIdentity, "ErrFormerIdentity"},
+	{ErrPrivKeyMissing, "ErrPrivKeyMissing"},
+	{ErrRevocationSignature, "ErrRevocationSignature"},
+	{ErrRevocationMint, "ErrRevocationMint"},
+	{ErrRevocationEpoch, "ErrRevocationEpoch"},
+	{ErrRevocationRecoveryEnd, "ErrRevocationRecoveryEnd"},
+	{ErrRevoked, "ErrRevoked"},
+	{ErrKeyWindow, "ErrKeyWindow"},
+	{ErrKeyListDecrypt, "ErrKeyListDecrypt"},
+	{ErrKeyListIdentity, "ErrKeyListIdentity"},
+	{ErrBoxKey, "ErrBoxKey"},
+	{ErrKeyListMismatch, "ErrKeyListMismatch"},
+	{ErrDBCTypeMissing, "ErrDBCTypeMissing"},
+	{ErrMintEpochsMissing, "ErrMintEpochsMissing"},
+	{ErrPrivKeyExpired, "ErrPrivKeyExpired"},
+	{ErrConcurrentModification, "ErrConcurrentModification"},
+	{ErrVersionUnsupported, "ErrVersionUnsupported"},
+	{ErrCurrencyUnknown, "ErrCurrencyUnknown"},
+	{ErrAssetInvalid, "ErrAssetInvalid"},
+	{ErrAmountPrecision, "ErrAmountPrecision"},
+	{ErrDBCSeriesInvalid, "ErrDBCSeriesInvalid"},
+	{ErrLedgerSignature, "ErrLedgerSignature"},
+	{ErrLedgerMint, "ErrLedgerMint"},
+	{ErrLedgerEpoch, "ErrLedgerEpoch"},
+	{ErrLedgerEntry, "ErrLedgerEntry"},
+	{ErrLedgerDuplicate, "ErrLedgerDuplicate"},
+	{ErrLedgerQuorum, "ErrLedgerQuorum"},
+	{ErrLedgerConflict, "ErrLedgerConflict"},
+	{ErrSupplyNegative, "ErrSupplyNegative"},
+	{ErrCommitmentLogSignature, "ErrCommitmentLogSignature"},
+	{ErrCommitmentLogEntry, "ErrCommitmentLogEntry"},
+	{ErrCommitmentLogDuplicate, "ErrCommitmentLogDuplicate"},
+	{ErrAuditImbalance, "ErrAuditImbalance"},
+	{ErrAuditDivergence, "ErrAuditDivergence"},
+	{ErrAuditConflict, "ErrAuditConflict"},
+}
+
+// ErrorCode returns the error code for err, that is, the name of the Err*
+// variable of this package that err wraps. If err doesn't wrap any of them,
+// "ErrUnknown" is returned.
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return "ErrUnknown"
+}
+
+// add an issue with the given severity to the report.
+func (r *ValidationReport) add(s Severity, epoch int, mintID string, err error) {
+	r.Issues = append(r.Issues, Issue{
+		Severity: s,
+		Epoch:    epoch,
+		MintID:   mintID,
+		Code:     ErrorCode(err),
+		Message:  err.Error(),
+		err:      err,
+	})
+}
+
+// addError adds an error to the report.
+func (r *ValidationReport) addError(epoch int, mintID string, err error) {
+	r.add(SeverityError, epoch, mintID, err)
+}
+
+// addWarning adds a warning to the report.
+func (r *ValidationReport) addWarning(epoch int, mintID string, err error) {
+	r.add(SeverityWarning, epoch, mintID, err)
+}
+
+// merge all issues from o into r and set their severity to s.
+func (r *ValidationReport) merge(o *ValidationReport, s Severity) {
+	for _, i := range o.Issues {
+		i.Severity = s
+		r.Issues = append(r.Issues, i)
+	}
+}
+
+// HasErrors returns true, if the report contains at least one issue with
+// severity SeverityError.
+func (r *ValidationReport) HasErrors() bool {
+	return r.Err() != nil
+}
+
+// Err returns the error of the first issue with severity SeverityError or
+// nil, if the report contains no errors.
+func (r *ValidationReport) Err() error {
+	for _, i := range r.Issues {
+		if i.Severity == SeverityError {
+			return i.err
+		}
+	}
+	return nil
+}
+
+// WriteText writes the report as text to w, one issue per line.
+func (r *ValidationReport) WriteText(w io.Writer) error {
+	for _, i := range r.Issues {
+		if _, err := fmt.Fprintln(w, i.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Marshal report as JSON string.
+func (r *ValidationReport) Marshal() string {
+	jsn, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		panic(err) // should never happen
+	}
+	return string(jsn)
+}
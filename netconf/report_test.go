@@ -0,0 +1,80 @@
+package netconf
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestNetworkReport(t *testing.T) {
+	net := Network{
+		NetworkEpochs: []NetworkEpoch{
+			{
+				QuorumM:        0,
+				NumberOfMintsN: 1,
+				SignStart:      t1,
+				SignEnd:        t2,
+				ValidateEnd:    t4,
+			},
+			{
+				QuorumM:        8,
+				NumberOfMintsN: 10,
+				SignStart:      t2,
+				SignEnd:        t3,
+				ValidateEnd:    t3,
+				DBCTypesRemoved: []DBCType{
+					{Currency: "EUR", Amount: 100000000},
+				},
+			},
+		},
+	}
+	r := net.Report()
+	codes := []string{
+		"ErrZeroM",
+		"ErrSignEpochEndNotBeforeValidateEnd",
+		"ErrValidationLongerThanNextSigning",
+		"ErrDBCTypeNotDefined",
+	}
+	epochs := []int{0, 1, 1, 1}
+	if len(r.Issues) != len(codes) {
+		t.Fatalf("Report() has %d issues (should have %d):\n%s",
+			len(r.Issues), len(codes), r.Marshal())
+	}
+	for i, issue := range r.Issues {
+		if issue.Code != codes[i] {
+			t.Errorf("issue %d has code %s (should be %s)", i, issue.Code, codes[i])
+		}
+		if issue.Epoch != epochs[i] {
+			t.Errorf("issue %d has epoch %d (should be %d)", i, issue.Epoch, epochs[i])
+		}
+		if issue.Severity != SeverityError {
+			t.Errorf("issue %d has severity %s", i, issue.Severity)
+		}
+	}
+	if err := net.Validate(); err != ErrZeroM {
+		t.Errorf("Validate() should return first error %v (has %v)", ErrZeroM, err)
+	}
+	var jsn struct {
+		Issues []struct {
+			Severity string
+			Epoch    int
+			Code     string
+		}
+	}
+	if err := json.Unmarshal([]byte(r.Marshal()), &jsn); err != nil {
+		t.Fatal(err)
+	}
+	if jsn.Issues[0].Severity != "error" || jsn.Issues[0].Code != "ErrZeroM" {
+		t.Errorf("unexpected JSON issue: %#v", jsn.Issues[0])
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	err := fmt.Errorf("%w: %v", ErrMintAlreadyAdded, "ed25519-foo")
+	if code := ErrorCode(err); code != "ErrMintAlreadyAdded" {
+		t.Errorf("ErrorCode() == %s != ErrMintAlreadyAdded", code)
+	}
+	if code := ErrorCode(fmt.Errorf("foo")); code != "ErrUnknown" {
+		t.Errorf("ErrorCode() == %s != ErrUnknown", code)
+	}
+}
@@ -0,0 +1,170 @@
+package netconf
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/scritcash/scrit/binencode"
+	"github.com/scritcash/scrit/util/atomicfile"
+)
+
+// Revocation defines an emergency revocation of the signing keys of a mint,
+// because they have been compromised. In contrast to a mint removal, which
+// only takes effect in a future epoch, a revocation takes effect immediately.
+//
+// From CompromiseTime on signatures by the revoked keys do not count towards
+// the quorum anymore. Until RecoveryEnd DBCs which only reach the quorum with
+// the help of revoked signatures can still be reissued (but not transferred),
+// see Federation.AcceptSignatures.
+type Revocation struct {
+	MintIdentityKey IdentityKey // identity key of the revoking mint
+	Epochs          []int       // revoked mint epochs (all, if empty)
+	CompromiseTime  time.Time   // keys are compromised since this time
+	RecoveryEnd     time.Time   // end of recovery period
+	Notice          string      // compromise notice (human readable)
+	Signature       string      // of all fields above by MintIdentityKey
+}
+
+// NewRevocation creates a new revocation of the given mint epochs (all, if
+// empty) signed by ik, which must contain the private key.
+func NewRevocation(
+	ik *IdentityKey,
+	epochs []int,
+	compromiseTime, recoveryEnd time.Time,
+	notice string,
+) (*Revocation, error) {
+	if len(ik.privKey) != ed25519.PrivateKeySize {
+		return nil, ErrPrivKeyMissing
+	}
+	r := &Revocation{
+		MintIdentityKey: *ik,
+		Epochs:          epochs,
+		CompromiseTime:  compromiseTime,
+		RecoveryEnd:     recoveryEnd,
+		Notice:          notice,
+	}
+	enc, err := r.encode()
+	if err != nil {
+		return nil, err
+	}
+	sig := ed25519.Sign(ik.privKey, enc)
+	r.Signature = base64.RawURLEncoding.EncodeToString(sig)
+	return r, nil
+}
+
+// encode revocation (without signature).
+func (r *Revocation) encode() ([]byte, error) {
+	encodingScheme := []interface{}{
+		[]byte(r.MintIdentityKey.SigAlgo),
+		r.MintIdentityKey.PubKey,
+		r.CompromiseTime.UTC().Unix(),
+		r.RecoveryEnd.UTC().Unix(),
+		[]byte(r.Notice),
+		int64(len(r.Epochs)),
+	}
+	for _, e := range r.Epochs {
+		encodingScheme = append(encodingScheme, int64(e))
+	}
+	size, err := binencode.EncodeSize(encodingScheme...)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, size)
+	return binencode.Encode(buf, encodingScheme...)
+}
+
+// Verify the signature of the revocation.
+func (r *Revocation) Verify() error {
+	// ed25519.Verify panics on malformed keys
+	if len(r.MintIdentityKey.PubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: invalid mint identity key", ErrRevocationSignature)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(r.Signature)
+	if err != nil {
+		return err
+	}
+	enc, err := r.encode()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(r.MintIdentityKey.PubKey, enc, sig) {
+		return ErrRevocationSignature
+	}
+	return nil
+}
+
+// Validate the revocation against the mint configuration m.
+func (r *Revocation) Validate(m *Mint) error {
+	if r.MintIdentityKey.MarshalID() != m.MintIdentityKey.MarshalID() {
+		return fmt.Errorf("%w: %s", ErrRevocationMint, r.MintIdentityKey.MarshalID())
+	}
+	if err := r.Verify(); err != nil {
+		return err
+	}
+	for _, e := range r.Epochs {
+		if e < 0 || e >= len(m.MintEpochs) {
+			return fmt.Errorf("%w: %d", ErrRevocationEpoch, e)
+		}
+	}
+	if r.RecoveryEnd.Before(r.CompromiseTime) {
+		return ErrRevocationRecoveryEnd
+	}
+	return nil
+}
+
+// Revokes returns true, if the revocation revokes the signing keys of the
+// given mint epoch at time t.
+func (r *Revocation) Revokes(epoch int, t time.Time) bool {
+	if t.Before(r.CompromiseTime) {
+		return false
+	}
+	if len(r.Epochs) == 0 {
+		return true
+	}
+	for _, e := range r.Epochs {
+		if e == epoch {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRevocations loads a list of revocations from filename.
+func LoadRevocations(filename string) ([]*Revocation, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalRevocations(data)
+}
+
+// loadRevocationsFS loads a list of revocations from the file name in fsys.
+func loadRevocationsFS(fsys fs.FS, name string) ([]*Revocation, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalRevocations(data)
+}
+
+func unmarshalRevocations(data []byte) ([]*Revocation, error) {
+	var revs []*Revocation
+	if err := json.Unmarshal(data, &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// SaveRevocations saves the list of revocations revs to filename. If filename
+// exists already it will be overwritten!
+func SaveRevocations(filename string, revs []*Revocation) error {
+	jsn, err := MarshalCanonical(revs)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, jsn, 0644)
+}
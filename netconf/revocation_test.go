@@ -0,0 +1,108 @@
+package netconf
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/scritcash/scrit/util/def"
+)
+
+func TestRevocation(t *testing.T) {
+	var iks []IdentityKey
+	for i := 0; i < 3; i++ {
+		ik, err := NewIdentityKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		iks = append(iks, *ik)
+	}
+	start := t1
+	net := NewNetwork(2, 3, start, start.Add(def.SigningPeriod),
+		start.Add(def.SigningPeriod).Add(def.ValidationPeriod), iks)
+	f := &Federation{
+		Network:     net,
+		Mints:       make(map[string]*Mint),
+		Revocations: make(map[string][]*Revocation),
+	}
+	var ids []string
+	for i := range iks {
+		m, err := NewMint("mint", &iks[i], []string{"https://mint.example.com"}, net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, iks[i].MarshalID())
+		f.Mints[ids[i]] = m
+	}
+
+	compromised := start.Add(24 * time.Hour)
+	recoveryEnd := compromised.Add(24 * time.Hour)
+	r, err := NewRevocation(&iks[0], []int{0}, compromised, recoveryEnd,
+		"identity key leaked")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Validate(f.Mints[ids[0]]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Validate(f.Mints[ids[1]]); !errors.Is(err, ErrRevocationMint) {
+		t.Errorf("Validate() should fail with %v (has %v)", ErrRevocationMint, err)
+	}
+	f.Revocations[ids[0]] = []*Revocation{r}
+
+	testCases := []struct {
+		t       time.Time
+		mintIDs []string
+		accept  Acceptance
+	}{
+		{compromised.Add(-time.Second), []string{ids[0], ids[1]}, Accept},
+		{compromised, []string{ids[0], ids[1]}, AcceptRecovery},
+		{compromised, []string{ids[1], ids[2]}, Accept},
+		{compromised, []string{ids[0], ids[0]}, Reject},
+		{recoveryEnd, []string{ids[0], ids[1]}, Reject},
+		{recoveryEnd, []string{ids[0], ids[1], ids[2]}, Accept},
+	}
+	signed := start.Add(time.Hour)
+	sigs := func(at time.Time, mintIDs ...string) []MintSignature {
+		var s []MintSignature
+		for _, id := range mintIDs {
+			s = append(s, MintSignature{MintID: id, Time: at})
+		}
+		return s
+	}
+	for i, testCase := range testCases {
+		accept := f.AcceptSignatures(0, sigs(signed, testCase.mintIDs...), testCase.t)
+		if accept != testCase.accept {
+			t.Errorf("test case %d: AcceptSignatures() == %d != %d", i, accept,
+				testCase.accept)
+		}
+	}
+
+	// signatures made outside of the signing epoch (or after t) do not count
+	end := net.NetworkEpochs[0].SignEnd
+	outside := []struct {
+		name string
+		sigs []MintSignature
+	}{
+		{"before epoch", sigs(start.Add(-time.Second), ids[1], ids[2])},
+		{"after epoch", sigs(end, ids[1], ids[2])},
+		{"in the future", sigs(signed.Add(time.Minute), ids[1], ids[2])},
+		{"one outside", append(sigs(signed, ids[1]), sigs(end, ids[2])...)},
+	}
+	for _, tt := range outside {
+		if accept := f.AcceptSignatures(0, tt.sigs, signed); accept != Reject {
+			t.Errorf("%s: AcceptSignatures() == %d != %d", tt.name, accept, Reject)
+		}
+	}
+	// a mint with an invalid and a valid signature counts once
+	both := append(sigs(end, ids[1]), sigs(signed, ids[1], ids[2])...)
+	if accept := f.AcceptSignatures(0, both, signed); accept != Accept {
+		t.Errorf("AcceptSignatures() == %d != %d", accept, Accept)
+	}
+
+	// tampering with the revocation invalidates the signature
+	r.Epochs = []int{}
+	if err := r.Verify(); err != ErrRevocationSignature {
+		t.Errorf("Verify() should fail with %v (has %v)", ErrRevocationSignature, err)
+	}
+}
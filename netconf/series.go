@@ -0,0 +1,121 @@
+package netconf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseScheme parses a denomination scheme like "1-2-5" or "1-2-2.5-5" and
+// returns its steps as amounts (with AmountDecimals decimal places). The
+// steps must be strictly increasing and lie in the interval [1, 10).
+func parseScheme(scheme string) ([]uint64, error) {
+	// steps are parsed with the maximum precision
+	c := Currency{Code: "scheme", Decimals: AmountDecimals}
+	var steps []uint64
+	for _, s := range strings.Split(scheme, "-") {
+		step, err := c.ParseAmount(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: scheme '%s': %v", ErrDBCSeriesInvalid, scheme, err)
+		}
+		if step < 1e8 || step >= 10e8 {
+			return nil, fmt.Errorf("%w: scheme '%s': step %s not in [1, 10)",
+				ErrDBCSeriesInvalid, scheme, s)
+		}
+		if len(steps) > 0 && step <= steps[len(steps)-1] {
+			return nil, fmt.Errorf("%w: scheme '%s': steps not increasing",
+				ErrDBCSeriesInvalid, scheme)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// DBCSeries returns the DBC types of the denomination series of the given
+// currency from min to max (inclusive, decimal amount strings). The series
+// repeats the steps of scheme (e.g., "1-2-5") in every decade: 0.01, 0.02,
+// 0.05, 0.10, 0.20, ... All DBC types of the series must be valid (see
+// DBCType.Validate).
+func DBCSeries(currency, scheme, min, max string) ([]DBCType, error) {
+	steps, err := parseScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
+	lo, err := ParseDBCType(currency, min)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := ParseDBCType(currency, max)
+	if err != nil {
+		return nil, err
+	}
+	if lo.Amount > hi.Amount {
+		return nil, fmt.Errorf("%w: minimum %s greater than maximum %s",
+			ErrDBCSeriesInvalid, min, max)
+	}
+	var series []DBCType
+	// decade is the amount of 10^k, starting with the smallest amount unit
+	for decade := uint64(1); ; decade *= 10 {
+		for _, step := range steps {
+			var amount uint64
+			if decade < 1e8 {
+				// step*decade < 10e8*1e8 cannot overflow
+				if step*decade%1e8 != 0 {
+					continue // below amount resolution
+				}
+				amount = step * decade / 1e8
+			} else {
+				if step > hi.Amount/(decade/1e8) {
+					break // beyond maximum (or overflow)
+				}
+				amount = step * (decade / 1e8)
+			}
+			if amount < lo.Amount || amount > hi.Amount {
+				continue
+			}
+			dbcType := DBCType{Currency: lo.Currency, Amount: amount}
+			if err := dbcType.Validate(); err != nil {
+				return nil, err
+			}
+			series = append(series, dbcType)
+		}
+		if decade/1e8 > hi.Amount/10 || decade > ^uint64(0)/10 {
+			break // next decade is beyond maximum
+		}
+	}
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: no %s denominations between %s and %s",
+			ErrDBCSeriesInvalid, scheme, min, max)
+	}
+	return series, nil
+}
+
+// DBCSeriesAdd adds all DBC types of series to the future epoch of the
+// network. The resulting DBC types are checked with DBCTypesValidate, which
+// prevents duplicates.
+func (n *Network) DBCSeriesAdd(series []DBCType) error {
+	// make sure network has a future epoch
+	if err := n.HasFuture(); err != nil {
+		return err
+	}
+	for _, dbcType := range series {
+		if err := dbcType.Validate(); err != nil {
+			return err
+		}
+		n.DBCTypeAdd(dbcType)
+	}
+	return n.DBCTypesValidate()
+}
+
+// DBCSeriesRemove removes all DBC types of series in the future epoch of the
+// network. The resulting DBC types are checked with DBCTypesValidate, which
+// makes sure that all DBC types of the series have been defined.
+func (n *Network) DBCSeriesRemove(series []DBCType) error {
+	// make sure network has a future epoch
+	if err := n.HasFuture(); err != nil {
+		return err
+	}
+	for _, dbcType := range series {
+		n.DBCTypeRemove(dbcType)
+	}
+	return n.DBCTypesValidate()
+}
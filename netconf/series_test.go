@@ -0,0 +1,129 @@
+package netconf
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func seriesAmounts(series []DBCType) []string {
+	var amounts []string
+	for _, dbcType := range series {
+		amounts = append(amounts, dbcType.String())
+	}
+	return amounts
+}
+
+func TestDBCSeries(t *testing.T) {
+	testCases := []struct {
+		currency string
+		scheme   string
+		min      string
+		max      string
+		amounts  []string
+	}{
+		{"EUR", "1-2-5", "1", "5", []string{"1.00 EUR", "2.00 EUR", "5.00 EUR"}},
+		{"EUR", "1-2-5", "0.01", "0.5", []string{
+			"0.01 EUR", "0.02 EUR", "0.05 EUR",
+			"0.10 EUR", "0.20 EUR", "0.50 EUR",
+		}},
+		{"EUR", "1-2-5", "3", "100", []string{
+			"5.00 EUR", "10.00 EUR", "20.00 EUR", "50.00 EUR", "100.00 EUR",
+		}},
+		{"USD", "1-2.5-5", "10", "100", []string{
+			"10.00 USD", "25.00 USD", "50.00 USD", "100.00 USD",
+		}},
+		{"JPY", "1-5", "1", "10000", []string{
+			"1 JPY", "5 JPY", "10 JPY", "50 JPY", "100 JPY", "500 JPY",
+			"1000 JPY", "5000 JPY", "10000 JPY",
+		}},
+	}
+	for _, testCase := range testCases {
+		series, err := DBCSeries(testCase.currency, testCase.scheme,
+			testCase.min, testCase.max)
+		if err != nil {
+			t.Errorf("DBCSeries(%s, %s, %s, %s) failed: %v", testCase.currency,
+				testCase.scheme, testCase.min, testCase.max, err)
+			continue
+		}
+		amounts := seriesAmounts(series)
+		if !reflect.DeepEqual(amounts, testCase.amounts) {
+			t.Errorf("DBCSeries(%s, %s, %s, %s) = %v, want %v", testCase.currency,
+				testCase.scheme, testCase.min, testCase.max, amounts, testCase.amounts)
+		}
+	}
+	// full EUR series
+	series, err := DBCSeries("EUR", "1-2-5", "0.01", "500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series) != 15 {
+		t.Errorf("EUR series has %d denominations, want 15", len(series))
+	}
+	// no overflow
+	if _, err := DBCSeries("XAU", "1-2-5", "1", "184467440737"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDBCSeriesInvalid(t *testing.T) {
+	testCases := []struct {
+		currency string
+		scheme   string
+		min      string
+		max      string
+		err      error
+	}{
+		{"EUR", "", "1", "5", ErrDBCSeriesInvalid},
+		{"EUR", "1-2-", "1", "5", ErrDBCSeriesInvalid},
+		{"EUR", "1-5-2", "1", "5", ErrDBCSeriesInvalid},
+		{"EUR", "0.5-1", "1", "5", ErrDBCSeriesInvalid},
+		{"EUR", "1-10", "1", "5", ErrDBCSeriesInvalid},
+		{"EUR", "1-2-5", "5", "1", ErrDBCSeriesInvalid},
+		{"EUR", "1-2-5", "3", "4", ErrDBCSeriesInvalid},
+		{"EUR", "1-2.5-5", "0.01", "1", ErrAmountPrecision},
+		{"XYZ", "1-2-5", "1", "5", ErrCurrencyUnknown},
+	}
+	for _, testCase := range testCases {
+		_, err := DBCSeries(testCase.currency, testCase.scheme,
+			testCase.min, testCase.max)
+		if !errors.Is(err, testCase.err) {
+			t.Errorf("DBCSeries(%s, %s, %s, %s) should fail with %v, got: %v",
+				testCase.currency, testCase.scheme, testCase.min, testCase.max,
+				testCase.err, err)
+		}
+	}
+}
+
+func TestDBCSeriesAddRemove(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour)
+	net := NewNetwork(1, 1, start, start.Add(24*time.Hour),
+		start.Add(48*time.Hour), nil)
+	series, err := DBCSeries("EUR", "1-2-5", "1", "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := net.DBCSeriesAdd(series); err != nil {
+		t.Fatal(err)
+	}
+	if len(net.DBCTypes()) != 3 {
+		t.Errorf("network has %d DBC types, want 3", len(net.DBCTypes()))
+	}
+	// duplicates are refused
+	overlap, err := DBCSeries("EUR", "1-2-5", "5", "50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = net.DBCSeriesAdd(overlap)
+	if !errors.Is(err, ErrDBCTypeAlreadyDefined) {
+		t.Errorf("DBCSeriesAdd() should fail with ErrDBCTypeAlreadyDefined, got: %v", err)
+	}
+	// removing undefined DBC types is refused
+	net = NewNetwork(1, 1, start, start.Add(24*time.Hour),
+		start.Add(48*time.Hour), nil)
+	err = net.DBCSeriesRemove(series)
+	if !errors.Is(err, ErrDBCTypeNotDefined) {
+		t.Errorf("DBCSeriesRemove() should fail with ErrDBCTypeNotDefined, got: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package netconf
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"sync"
+
+	"github.com/frankbraun/codechain/util/bzero"
+)
+
+// Signer signs messages with the private key corresponding to a public key.
+// It allows to keep the private keys of a mint outside of the process using
+// them, for example, in a separate signer process.
+type Signer interface {
+	// Sign signs msg with the private key corresponding to pubKey.
+	// If the signer doesn't know the private key, ErrPrivKeyMissing is
+	// returned.
+	Sign(pubKey, msg []byte) ([]byte, error)
+}
+
+// KeySigner is a Signer which holds Ed25519 private keys in memory.
+// It is safe for concurrent use.
+type KeySigner struct {
+	mu   sync.RWMutex
+	keys map[string]ed25519.PrivateKey
+}
+
+// NewKeySigner returns a new KeySigner without any keys.
+func NewKeySigner() *KeySigner {
+	return &KeySigner{keys: make(map[string]ed25519.PrivateKey)}
+}
+
+// NewMintSigner returns a new KeySigner for the private identity key ik and
+// all private signing keys contained in the key list of mint m.
+func NewMintSigner(ik *IdentityKey, m *Mint) *KeySigner {
+	s := NewKeySigner()
+	s.AddIdentityKey(ik)
+	for _, e := range m.MintEpochs {
+		for _, k := range e.KeyList {
+			s.Add(k.PrivKey)
+		}
+	}
+	return s
+}
+
+// Add the Ed25519 private key privKey to the signer. Invalid keys are
+// ignored.
+func (s *KeySigner) Add(privKey ed25519.PrivateKey) {
+	if len(privKey) != ed25519.PrivateKeySize {
+		return
+	}
+	pubKey := privKey.Public().(ed25519.PublicKey)
+	s.mu.Lock()
+	s.keys[base64.RawURLEncoding.EncodeToString(pubKey)] = privKey
+	s.mu.Unlock()
+}
+
+// Remove erases the private key corresponding to pubKey from the signer.
+// The key material is overwritten, that is, it must not be used by the caller
+// anymore.
+func (s *KeySigner) Remove(pubKey []byte) {
+	id := base64.RawURLEncoding.EncodeToString(pubKey)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if privKey, ok := s.keys[id]; ok {
+		bzero.Bytes(privKey)
+		delete(s.keys, id)
+	}
+}
+
+// AddIdentityKey adds the private key of the identity key ik to the signer,
+// if it has one.
+func (s *KeySigner) AddIdentityKey(ik *IdentityKey) {
+	s.Add(ik.privKey)
+}
+
+// Len returns the number of private keys held by the signer.
+func (s *KeySigner) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.keys)
+}
+
+// Sign msg with the private key corresponding to pubKey.
+func (s *KeySigner) Sign(pubKey, msg []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	privKey, ok := s.keys[base64.RawURLEncoding.EncodeToString(pubKey)]
+	if !ok {
+		return nil, ErrPrivKeyMissing
+	}
+	return ed25519.Sign(privKey, msg), nil
+}
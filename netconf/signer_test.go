@@ -0,0 +1,53 @@
+package netconf
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/scritcash/scrit/util/def"
+)
+
+func TestSignWith(t *testing.T) {
+	ik, err := NewIdentityKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	net := NewNetwork(1, 1, t1, t1.Add(def.SigningPeriod),
+		t1.Add(def.SigningPeriod).Add(def.ValidationPeriod),
+		[]IdentityKey{*ik})
+	net.DBCTypeAdd(DBCType{Currency: "EUR", Amount: 100000000})
+	m, err := NewMint("mint", ik, []string{"https://mint.example.com"}, net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewMintSigner(ik, m)
+	if s.Len() != 2 {
+		t.Errorf("signer should hold 2 keys, has %d", s.Len())
+	}
+
+	// sign public key list with signer
+	jsn, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := unmarshalMint(jsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub.PrunePrivKeys()
+	pub.MintEpochs[0].KeyListSignatures = nil
+	if err := pub.SignWith(s, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := pub.Validate(net); err != nil {
+		t.Error(err)
+	}
+
+	// signer without private signing keys
+	s = NewKeySigner()
+	s.AddIdentityKey(ik)
+	if err := pub.SignWith(s, 0); !errors.Is(err, ErrPrivKeyMissing) {
+		t.Errorf("SignWith() should fail with ErrPrivKeyMissing, got: %v", err)
+	}
+}
@@ -3,15 +3,18 @@ package netconf
 import (
 	"crypto/ed25519"
 	"crypto/rand"
+	"time"
 )
 
 // SigningKey defines an entry in the key list.
 type SigningKey struct {
-	Currency string             // the currency this key signs, usually ISO 4217 codes
-	Amount   uint64             // the amount this key signs, 8 digits after the dot
-	SigAlgo  string             // signature algorithm
-	PubKey   []byte             // public key
-	PrivKey  ed25519.PrivateKey `json:",omitempty"` // private key
+	Currency  string             // the currency this key signs, usually ISO 4217 codes
+	Amount    uint64             // the amount this key signs, 8 digits after the dot
+	SigAlgo   string             // signature algorithm
+	PubKey    []byte             // public key
+	PrivKey   ed25519.PrivateKey `json:",omitempty"` // private key
+	SignStart *time.Time         `json:",omitempty"` // start of signing window (default: epoch start)
+	SignEnd   *time.Time         `json:",omitempty"` // end of signing window (default: epoch end)
 }
 
 // NewSigningKey generates a new signing key.
@@ -31,3 +34,27 @@ func NewSigningKey(
 	sk.PrivKey = privKey
 	return &sk, nil
 }
+
+// DBCType returns the DBC type signed by the key.
+func (sk *SigningKey) DBCType() DBCType {
+	return DBCType{Currency: sk.Currency, Amount: sk.Amount}
+}
+
+// HasWindow returns true, if the key defines its own signing window.
+func (sk *SigningKey) HasWindow() bool {
+	return sk.SignStart != nil || sk.SignEnd != nil
+}
+
+// Window returns the signing window [start, end) of the key within the mint
+// epoch e.
+func (sk *SigningKey) Window(e *MintEpoch) (start, end time.Time) {
+	start = e.SignStart
+	if sk.SignStart != nil {
+		start = *sk.SignStart
+	}
+	end = e.SignEnd
+	if sk.SignEnd != nil {
+		end = *sk.SignEnd
+	}
+	return start, end
+}
@@ -0,0 +1,165 @@
+package netconf
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// Supply is the supply of DBCs of a DBC type in an epoch. Only ledger entries
+// recorded by a quorum of mints are counted.
+type Supply struct {
+	Epoch       int     // signing epoch
+	DBCType     DBCType // DBC type
+	Created     uint64  // number of DBCs created in epoch
+	Destroyed   uint64  // number of DBCs destroyed in epoch
+	Outstanding uint64  // number of DBCs outstanding at the end of epoch
+}
+
+// Value returns the value of the outstanding DBCs (with AmountDecimals
+// decimal places).
+func (s *Supply) Value() (uint64, error) {
+	hi, lo := bits.Mul64(s.Outstanding, s.DBCType.Amount)
+	if hi != 0 {
+		return 0, fmt.Errorf("netconf: value of %d x %s overflows", s.Outstanding,
+			s.DBCType)
+	}
+	return lo, nil
+}
+
+// ledgerRecord identifies the content of a ledger entry, the time is
+// recorded by every mint on its own and therefore not compared.
+type ledgerRecord struct {
+	ID      string
+	DBCType DBCType
+	Count   uint64
+	Inputs  string // joined inputs
+}
+
+// entry returns the ledger entry recorded by rec (without time).
+func (rec ledgerRecord) entry() LedgerEntry {
+	e := LedgerEntry{ID: rec.ID, DBCType: rec.DBCType, Count: rec.Count}
+	if rec.Inputs != "" {
+		e.Inputs = strings.Split(rec.Inputs, ",")
+	}
+	return e
+}
+
+// confirmedEntries returns the entries of the ledgers of an epoch which have
+// been recorded identically by at least quorum mints. Entries with fewer
+// recordings and conflicting entries are reported in r.
+func confirmedEntries(
+	ledgers []*Ledger,
+	epoch int,
+	quorum uint64,
+	r *ValidationReport,
+) []ledgerRecord {
+	var ids []string
+	variants := make(map[string][]ledgerRecord)
+	mints := make(map[ledgerRecord][]string)
+	for _, l := range ledgers {
+		for _, e := range l.Entries {
+			rec := ledgerRecord{e.ID, e.DBCType, e.Count, strings.Join(e.Inputs, ",")}
+			if _, ok := variants[e.ID]; !ok {
+				ids = append(ids, e.ID)
+			}
+			if _, ok := mints[rec]; !ok {
+				variants[e.ID] = append(variants[e.ID], rec)
+			}
+			mints[rec] = append(mints[rec], l.MintIdentityKey.MarshalID())
+		}
+	}
+	var confirmed []ledgerRecord
+	for _, id := range ids {
+		if len(variants[id]) > 1 {
+			for _, rec := range variants[id] {
+				for _, mintID := range mints[rec] {
+					r.addWarning(epoch, mintID, fmt.Errorf("%w: %s: %d x %s",
+						ErrLedgerConflict, id, rec.Count, rec.DBCType))
+				}
+			}
+		}
+		var found bool
+		for _, rec := range variants[id] {
+			if uint64(len(mints[rec])) >= quorum {
+				confirmed = append(confirmed, rec)
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.addWarning(epoch, "", fmt.Errorf("%w: %s", ErrLedgerQuorum, id))
+		}
+	}
+	return confirmed
+}
+
+// Supply returns the supply of all DBC types for all epochs of the federation
+// with at least one DBC outstanding, created, or destroyed. The supply is
+// computed from the issuance (Created) and destruction (Destroyed) ledgers.
+// Inconsistencies are listed in the returned report.
+func (f *Federation) Supply() ([]Supply, *ValidationReport) {
+	var (
+		supply      []Supply
+		r           ValidationReport
+		outstanding = make(map[DBCType]uint64)
+	)
+	for i, e := range f.Network.NetworkEpochs {
+		created := make(map[DBCType]uint64)
+		destroyed := make(map[DBCType]uint64)
+		for _, rec := range confirmedEntries(f.Created[i], i, e.QuorumM, &r) {
+			created[rec.DBCType] += rec.Count
+		}
+		for _, rec := range confirmedEntries(f.Destroyed[i], i, e.QuorumM, &r) {
+			destroyed[rec.DBCType] += rec.Count
+		}
+		dbcTypes := make(map[DBCType]bool)
+		for _, m := range []map[DBCType]uint64{outstanding, created, destroyed} {
+			for dbcType := range m {
+				dbcTypes[dbcType] = true
+			}
+		}
+		for _, dbcType := range DBCTypeMapToSortedArray(dbcTypes) {
+			s := Supply{
+				Epoch:     i,
+				DBCType:   dbcType,
+				Created:   created[dbcType],
+				Destroyed: destroyed[dbcType],
+			}
+			available := outstanding[dbcType] + s.Created
+			if s.Destroyed > available {
+				r.addError(i, "", fmt.Errorf("%w: %d x %s destroyed, %d available",
+					ErrSupplyNegative, s.Destroyed, dbcType, available))
+			} else {
+				s.Outstanding = available - s.Destroyed
+			}
+			outstanding[dbcType] = s.Outstanding
+			if s.Outstanding == 0 && s.Created == 0 && s.Destroyed == 0 {
+				delete(outstanding, dbcType)
+				continue
+			}
+			supply = append(supply, s)
+		}
+	}
+	return supply, &r
+}
+
+// ConfirmedEntries returns all entries of the ledgers of the given kind
+// (LedgerCreate or LedgerDestroyed) which have been recorded identically by
+// at least a quorum of mints, indexed by their IDs. The time of the returned
+// entries is not set, it differs between mints. Inconsistencies are listed
+// in the returned report.
+func (f *Federation) ConfirmedEntries(kind string) (map[string]LedgerEntry, *ValidationReport) {
+	var r ValidationReport
+	ledgers := f.Created
+	if kind == LedgerDestroyed {
+		ledgers = f.Destroyed
+	}
+	entries := make(map[string]LedgerEntry)
+	for i, e := range f.Network.NetworkEpochs {
+		for _, rec := range confirmedEntries(ledgers[i], i, e.QuorumM, &r) {
+			entries[rec.ID] = rec.entry()
+		}
+	}
+	return entries, &r
+}
@@ -0,0 +1,112 @@
+package netconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// NetworkVersion is the current version of the network configuration file
+// format (DefNetConfFile). Files without a version have version 0.
+const NetworkVersion = 1
+
+// MintVersion is the current version of the mint key list file format
+// (public and private key lists). Files without a version have version 0.
+const MintVersion = 1
+
+// A migration upgrades the decoded JSON document doc of a file from one
+// version to the next. It doesn't have to set the version itself.
+type migration func(doc map[string]interface{}) error
+
+// networkMigrations and mintMigrations are the migration registries, the
+// migration at index i upgrades a file from version i to version i+1.
+var (
+	networkMigrations = []migration{
+		migrateNoop, // 0 -> 1: introduction of the version field
+	}
+	mintMigrations = []migration{
+		migrateNoop, // 0 -> 1: introduction of the version field
+	}
+)
+
+// migrateNoop is the migration for version upgrades without layout changes.
+func migrateNoop(doc map[string]interface{}) error {
+	return nil
+}
+
+// decodeDocument decodes the JSON object data generically.
+func decodeDocument(data []byte) (map[string]interface{}, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// documentVersion returns the version of the decoded JSON document doc.
+func documentVersion(doc map[string]interface{}) (int, error) {
+	v, ok := doc["Version"]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("netconf: version is not a number: %v", v)
+	}
+	i, err := n.Int64()
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("netconf: invalid version: %s", n)
+	}
+	return int(i), nil
+}
+
+// FileVersion returns the format version of the network configuration or
+// mint key list file with content data.
+func FileVersion(data []byte) (int, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return 0, err
+	}
+	return documentVersion(doc)
+}
+
+// migrate upgrades the JSON encoded file data to version current with the
+// given migrations. If the file has the current version already, data is
+// returned unchanged.
+func migrate(data []byte, current int, migrations []migration) ([]byte, error) {
+	doc, err := decodeDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	v, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if v == current {
+		return data, nil
+	}
+	if v > current {
+		return nil, fmt.Errorf("%w: version %d (supported: %d)",
+			ErrVersionUnsupported, v, current)
+	}
+	for ; v < current; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, fmt.Errorf("netconf: migration from version %d failed: %w",
+				v, err)
+		}
+		doc["Version"] = v + 1
+	}
+	return json.Marshal(doc)
+}
+
+// migrateNetwork upgrades the network configuration data to NetworkVersion.
+func migrateNetwork(data []byte) ([]byte, error) {
+	return migrate(data, NetworkVersion, networkMigrations)
+}
+
+// migrateMint upgrades the mint key list data to MintVersion.
+func migrateMint(data []byte) ([]byte, error) {
+	return migrate(data, MintVersion, mintMigrations)
+}
@@ -0,0 +1,68 @@
+package netconf
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationRegistry(t *testing.T) {
+	if len(networkMigrations) != NetworkVersion {
+		t.Errorf("need %d network migrations, have %d", NetworkVersion,
+			len(networkMigrations))
+	}
+	if len(mintMigrations) != MintVersion {
+		t.Errorf("need %d mint migrations, have %d", MintVersion,
+			len(mintMigrations))
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	// testdata has version 0
+	data, err := ioutil.ReadFile(filepath.Join("testdata", DefNetConfFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := FileVersion(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 0 {
+		t.Fatalf("testdata should have version 0, has %d", v)
+	}
+	net, err := unmarshalNetwork(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if net.Version != NetworkVersion {
+		t.Errorf("network should be migrated to version %d, has %d",
+			NetworkVersion, net.Version)
+	}
+	if err := net.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// current version is unchanged
+	jsn, err := MarshalCanonical(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := migrateNetwork(jsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(migrated) != string(jsn) {
+		t.Error("current version should not be migrated")
+	}
+
+	// newer versions are rejected
+	_, err = unmarshalNetwork([]byte(`{"Version": 1000, "NetworkEpochs": []}`))
+	if !errors.Is(err, ErrVersionUnsupported) {
+		t.Errorf("unmarshalNetwork() should fail with ErrVersionUnsupported, got: %v", err)
+	}
+	_, err = unmarshalMint([]byte(`{"Version": 1000}`))
+	if !errors.Is(err, ErrVersionUnsupported) {
+		t.Errorf("unmarshalMint() should fail with ErrVersionUnsupported, got: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+// Package atomicfile implements crash-safe writing of files.
+//
+// Files are written to a temporary file in the same directory, synced to
+// disk, and renamed to their final name afterwards. The directory is synced
+// as well to make the rename durable. That way a reader either sees the old
+// or the new content of a file, but never a partially written one.
+package atomicfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BackupExt is the extension of the backup files created by the
+// rename-write-remove scheme which was used before this package existed.
+const BackupExt = ".bac"
+
+// tempInfix is inserted between the filename and the random suffix of
+// temporary files.
+const tempInfix = ".tmp"
+
+// WriteFile writes data atomically to filename with permissions perm. If
+// filename exists already it will be replaced!
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := ioutil.TempFile(dir, base+tempInfix)
+	if err != nil {
+		return err
+	}
+	// remove temporary file on error, harmless after successful rename
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir syncs the directory dir to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// IsTemp returns true, if name is a temporary file left behind by an
+// interrupted WriteFile or a backup file left behind by an interrupted write
+// of the old rename-write-remove scheme.
+func IsTemp(name string) bool {
+	return strings.HasSuffix(name, BackupExt) ||
+		strings.Contains(filepath.Base(name), tempInfix)
+}
+
+// Recover recovers filename from a write of the old rename-write-remove
+// scheme which has been interrupted. If a backup file filename+BackupExt
+// exists, it is restored if filename is missing or its content is not valid
+// (as reported by valid). Otherwise, the write completed and the backup file
+// is removed. Recover returns true, if the backup file was restored.
+func Recover(filename string, valid func(data []byte) bool) (bool, error) {
+	backup := filename + BackupExt
+	if _, err := os.Stat(backup); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil // nothing to recover
+		}
+		return false, err
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if err == nil && valid(data) {
+		// write completed, only the removal of the backup is missing
+		return false, os.Remove(backup)
+	}
+	// write was interrupted, restore backup
+	if err := os.Rename(backup, filename); err != nil {
+		return false, err
+	}
+	return true, syncDir(filepath.Dir(filename))
+}
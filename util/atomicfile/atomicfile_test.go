@@ -0,0 +1,113 @@
+package atomicfile
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "file.json")
+	if err := WriteFile(filename, []byte("old"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(filename, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("file has wrong content: %s", data)
+	}
+	fi, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0644 {
+		t.Errorf("file has wrong mode: %v", fi.Mode())
+	}
+	// no temporary files left
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory should contain 1 file, has %d", len(entries))
+	}
+}
+
+func TestIsTemp(t *testing.T) {
+	testCases := []struct {
+		name string
+		temp bool
+	}{
+		{"federation.json", false},
+		{"federation.json.bac", true},
+		{"federation.json.tmp123456", true},
+		{"mints/mint.json", false},
+	}
+	for _, testCase := range testCases {
+		if IsTemp(testCase.name) != testCase.temp {
+			t.Errorf("IsTemp(%q) should return %v", testCase.name, testCase.temp)
+		}
+	}
+}
+
+func TestRecover(t *testing.T) {
+	testCases := []struct {
+		file     string // content of file ("" for missing file)
+		restored bool   // backup should be restored
+	}{
+		{"", true},           // interrupted after rename to backup
+		{`{"trunc`, true},    // interrupted during write
+		{`{"new":1}`, false}, // interrupted before removal of backup
+	}
+	for i, testCase := range testCases {
+		dir := t.TempDir()
+		filename := filepath.Join(dir, "file.json")
+		backup := `{"old":1}`
+		if err := ioutil.WriteFile(filename+BackupExt, []byte(backup), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if testCase.file != "" {
+			if err := ioutil.WriteFile(filename, []byte(testCase.file), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		restored, err := Recover(filename, json.Valid)
+		if err != nil {
+			t.Fatalf("test case %d: %v", i, err)
+		}
+		if restored != testCase.restored {
+			t.Errorf("test case %d: Recover() returned %v", i, restored)
+		}
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := testCase.file
+		if testCase.restored {
+			want = backup
+		}
+		if string(data) != want {
+			t.Errorf("test case %d: file has wrong content: %s", i, data)
+		}
+		if _, err := os.Stat(filename + BackupExt); !os.IsNotExist(err) {
+			t.Errorf("test case %d: backup file should be gone", i)
+		}
+	}
+
+	// nothing to recover
+	restored, err := Recover(filepath.Join(t.TempDir(), "file.json"), json.Valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored {
+		t.Error("Recover() should not restore anything")
+	}
+}
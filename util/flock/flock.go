@@ -0,0 +1,90 @@
+// Package flock implements advisory locking of directories.
+//
+// Directories are locked instead of files, because files written with
+// package atomicfile are replaced on every write, and a lock on the replaced
+// file would be lost. Locking the directory also avoids separate lock files.
+//
+// Locks exclude other processes only. Within a process locks are re-entrant:
+// locking a directory which the process has locked already succeeds at once,
+// the directory stays locked until every Lock for it has been released. This
+// allows a process to lock several files in the same directory. Goroutines of
+// the same process must therefore be synchronized by other means.
+//
+// Locking is only implemented on Unix systems (with flock(2)). On Windows
+// LockDir doesn't lock anything and concurrent invocations are not excluded.
+package flock
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+var (
+	mu   sync.Mutex                  // protects held, serializes locking
+	held = make(map[string]*dirLock) // directories locked by this process
+)
+
+// dirLock is the lock a process holds on a directory.
+type dirLock struct {
+	f *os.File
+	n int // number of Locks sharing f
+}
+
+// Lock is an advisory lock on a directory.
+type Lock struct {
+	dir string
+}
+
+// LockDir acquires an exclusive advisory lock on the directory dir. It blocks
+// until the lock is acquired. The lock is released with Unlock or when the
+// process exits.
+func LockDir(dir string) (*Lock, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	dir, err = filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if d, ok := held[dir]; ok {
+		d.n++
+		return &Lock{dir: dir}, nil
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	held[dir] = &dirLock{f: f, n: 1}
+	return &Lock{dir: dir}, nil
+}
+
+// Unlock releases the lock. The directory is unlocked when the last lock on
+// it held by the process is released.
+func (l *Lock) Unlock() error {
+	mu.Lock()
+	defer mu.Unlock()
+	dir := l.dir
+	d := held[dir]
+	if d == nil {
+		return os.ErrClosed // released already
+	}
+	l.dir = ""
+	d.n--
+	if d.n > 0 {
+		return nil
+	}
+	delete(held, dir)
+	if err := unlock(d.f); err != nil {
+		d.f.Close()
+		return err
+	}
+	return d.f.Close()
+}
@@ -0,0 +1,84 @@
+package flock
+
+import (
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// lockedElsewhere returns true, if dir cannot be locked with another file
+// handle, like another process would do.
+func lockedElsewhere(t *testing.T, dir string) bool {
+	f, err := os.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locked := make(chan struct{})
+	go func() {
+		if err := lock(f); err != nil {
+			t.Error(err)
+		}
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		unlock(f)
+		f.Close()
+		return false
+	case <-time.After(100 * time.Millisecond):
+		// wait for the lock before closing the handle
+		go func() {
+			<-locked
+			unlock(f)
+			f.Close()
+		}()
+		return true
+	}
+}
+
+func TestLockDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("locking is a no-op on Windows")
+	}
+	dir := t.TempDir()
+	l, err := LockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lockedElsewhere(t, dir) {
+		t.Fatal("directory not locked")
+	}
+	// locks are re-entrant within the process
+	l2, err := LockDir(dir + string(os.PathSeparator) + ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if !lockedElsewhere(t, dir) {
+		t.Fatal("directory unlocked while still locked by the process")
+	}
+	if err := l2.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if lockedElsewhere(t, dir) {
+		t.Fatal("directory still locked after last unlock")
+	}
+	if err := l2.Unlock(); err == nil {
+		t.Error("second Unlock() should fail")
+	}
+
+	// the directory can be locked again after it has been released
+	l, err = LockDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lockedElsewhere(t, dir) {
+		t.Fatal("directory not locked again after release")
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+}
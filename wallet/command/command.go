@@ -0,0 +1,2 @@
+// Package command implements the scrit-wallet commands.
+package command
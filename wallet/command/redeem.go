@@ -0,0 +1,110 @@
+package command
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/frankbraun/codechain/secpkg"
+	"github.com/frankbraun/codechain/util/log"
+	"github.com/scritcash/scrit/dbc"
+	"github.com/scritcash/scrit/mint/redeem"
+	"github.com/scritcash/scrit/netconf"
+)
+
+func createRequest(
+	dir, id, payout, outFile string,
+	dbcFiles []string,
+	t time.Time,
+) error {
+	fed, r, err := netconf.LoadFederationFS(os.DirFS(dir), &netconf.LoadOptions{Logger: log.Std, At: t})
+	if err != nil {
+		return err
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if id == "" {
+		var b [16]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return err
+		}
+		id = hex.EncodeToString(b[:])
+	}
+	var inputs []*dbc.DBC
+	for _, filename := range dbcFiles {
+		d, err := dbc.Load(filename)
+		if err != nil {
+			return err
+		}
+		a, err := d.Verify(fed, t)
+		if err != nil {
+			return fmt.Errorf("'%s': %w", filename, err)
+		}
+		if a != netconf.Accept {
+			return fmt.Errorf("'%s': DBC can only be reissued", filename)
+		}
+		inputs = append(inputs, d)
+	}
+	req, err := redeem.NewRequest(id, payout, inputs)
+	if err != nil {
+		return err
+	}
+	if err := req.Save(outFile); err != nil {
+		return err
+	}
+	value, err := req.Value()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("redemption request %s for %s to '%s' written to '%s'\n",
+		req.ID, netconf.DBCType{Currency: req.Currency(), Amount: value}, payout, outFile)
+	return nil
+}
+
+// Redeem implements the scrit-wallet 'redeem' command.
+func Redeem(argv0 string, args ...string) error {
+	fs := flag.NewFlagSet(argv0, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [-d federation_dir] [-id ID] -payout destination [-o %s] [-at time] dbc.json...\n",
+			argv0, redeem.DefRequestFile)
+		fmt.Fprintf(os.Stderr, "Create redemption request which redeems the given DBCs.\n")
+		fmt.Fprintf(os.Stderr, "The request must be redeemed by the mints with 'scrit-mint redeem'.\n")
+		fs.PrintDefaults()
+	}
+	dir := fs.String("d", ".", "Set federation directory")
+	id := fs.String("id", "", "Request reference (random, if not set)")
+	payout := fs.String("payout", "", "Payout destination")
+	outFile := fs.String("o", redeem.DefRequestFile, "Write redemption request to file")
+	at := fs.String("at", "", "Verify DBCs as of given time (RFC3339) instead of now")
+	verbose := fs.Bool("v", false, "Be verbose")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *verbose {
+		log.Std = log.NewStd(os.Stdout)
+	}
+	if *payout == "" {
+		fmt.Fprintf(os.Stderr, "%s: option -payout is mandatory\n", argv0)
+		return flag.ErrHelp
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	if err := secpkg.UpToDate("scrit"); err != nil {
+		return err
+	}
+	t := time.Now()
+	if *at != "" {
+		var err error
+		t, err = time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return err
+		}
+	}
+	return createRequest(*dir, *id, *payout, *outFile, fs.Args(), t)
+}